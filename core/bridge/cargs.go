@@ -0,0 +1,34 @@
+package bridge
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+import "unsafe"
+
+// CArgs holds the C memory built for one export call made from Invoke.
+// Exports copy their arguments on entry, so Free runs when they return.
+// cgo types are per package, so callers convert the pointers, as in
+// (*C.char)(cs.Str(s)).
+type CArgs []unsafe.Pointer
+
+// Str returns a NUL-terminated copy of s.
+func (cs *CArgs) Str(s string) unsafe.Pointer {
+	p := unsafe.Pointer(C.CString(s))
+	*cs = append(*cs, p)
+	return p
+}
+
+// Bytes returns a copy of b, which may hold NUL bytes.
+func (cs *CArgs) Bytes(b []byte) unsafe.Pointer {
+	p := C.CBytes(b)
+	*cs = append(*cs, p)
+	return p
+}
+
+// Free releases everything Str and Bytes returned.
+func (cs CArgs) Free() {
+	for _, p := range cs {
+		C.free(p)
+	}
+}
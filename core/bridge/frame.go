@@ -0,0 +1,92 @@
+package bridge
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// FrameVersion is the first byte of every framed binary message.
+const FrameVersion byte = 1
+
+// PayloadKind tells the Dart side how to interpret a frame payload.
+type PayloadKind uint8
+
+const (
+	PayloadRaw PayloadKind = iota
+	PayloadJSON
+	PayloadPNG
+	PayloadJPEG
+	PayloadFileChunk
+	PayloadSocketData
+)
+
+// FrameHeader prefixes binary messages so Dart can route them without
+// decoding the payload. Wire layout (big endian):
+//
+//	[0]       version (FrameVersion)
+//	[1]       payload kind
+//	[2:10]    request id (int64)
+//	[10:12]   op length n (uint16)
+//	[12:12+n] op name (utf-8)
+//	[12+n:]   payload
+type FrameHeader struct {
+	Op        string
+	RequestID int64
+	Kind      PayloadKind
+}
+
+const frameFixedSize = 12
+
+var ErrShortFrame = errors.New("bridge: short frame")
+
+// Size returns the encoded header length in bytes.
+func (h FrameHeader) Size() int {
+	return frameFixedSize + len(h.op())
+}
+
+// MarshalTo writes the header into b, which must hold at least h.Size()
+// bytes, and returns the number of bytes written.
+func (h FrameHeader) MarshalTo(b []byte) int {
+	op := h.op()
+	b[0] = FrameVersion
+	b[1] = byte(h.Kind)
+	binary.BigEndian.PutUint64(b[2:10], uint64(h.RequestID))
+	binary.BigEndian.PutUint16(b[10:12], uint16(len(op)))
+	return frameFixedSize + copy(b[frameFixedSize:], op)
+}
+
+func (h FrameHeader) op() string {
+	if len(h.Op) > 0xFFFF {
+		return h.Op[:0xFFFF]
+	}
+	return h.Op
+}
+
+// EncodeFrame returns header and payload as a single buffer.
+func EncodeFrame(h FrameHeader, payload []byte) []byte {
+	b := make([]byte, h.Size()+len(payload))
+	n := h.MarshalTo(b)
+	copy(b[n:], payload)
+	return b
+}
+
+// DecodeFrame splits a framed message into its header and payload. The
+// payload aliases b.
+func DecodeFrame(b []byte) (FrameHeader, []byte, error) {
+	if len(b) < frameFixedSize {
+		return FrameHeader{}, nil, ErrShortFrame
+	}
+	if b[0] != FrameVersion {
+		return FrameHeader{}, nil, errors.New("bridge: unknown frame version")
+	}
+	n := int(binary.BigEndian.Uint16(b[10:12]))
+	if len(b) < frameFixedSize+n {
+		return FrameHeader{}, nil, ErrShortFrame
+	}
+	h := FrameHeader{
+		Op:        string(b[frameFixedSize : frameFixedSize+n]),
+		RequestID: int64(binary.BigEndian.Uint64(b[2:10])),
+		Kind:      PayloadKind(b[1]),
+	}
+	return h, b[frameFixedSize+n:], nil
+}
@@ -0,0 +1,169 @@
+package bridge
+
+/*
+#include "stdint.h"
+#include "stdlib.h"
+#include "dart_api/dart_api_dl.h"
+#include "dart_api/dart_api_dl.c"
+#include "dart_api/dart_native_api.h"
+// Go does not allow calling C function pointers directly.
+// we mock a function to call Dart_PostCObject_DL
+bool GoDart_PostCObject(Dart_Port_DL port, Dart_CObject* obj) {
+  return Dart_PostCObject_DL(port, obj);
+}
+
+// finalizer for external typed data: the peer is the malloc'ed buffer itself
+static void GoDart_FreePeer(void* isolate_callback_data, void* peer) {
+  free(peer);
+}
+
+// the VM copies kTypedData while posting, so data can be freed right after
+static bool GoDart_PostTypedData(Dart_Port_DL port, uint8_t* data, intptr_t len) {
+  Dart_CObject obj;
+  obj.type = Dart_CObject_kTypedData;
+  obj.value.as_typed_data.type = Dart_TypedData_kUint8;
+  obj.value.as_typed_data.length = len;
+  obj.value.as_typed_data.values = data;
+  return Dart_PostCObject_DL(port, &obj);
+}
+
+// kExternalTypedData hands data to the receiving isolate without a copy.
+// On success the VM owns it and calls GoDart_FreePeer once the Dart
+// Uint8List is collected; on failure the caller still owns it.
+static bool GoDart_PostExternalTypedData(Dart_Port_DL port, uint8_t* data, intptr_t len) {
+  Dart_CObject obj;
+  obj.type = Dart_CObject_kExternalTypedData;
+  obj.value.as_external_typed_data.type = Dart_TypedData_kUint8;
+  obj.value.as_external_typed_data.length = len;
+  obj.value.as_external_typed_data.data = data;
+  obj.value.as_external_typed_data.peer = data;
+  obj.value.as_external_typed_data.callback = GoDart_FreePeer;
+  return Dart_PostCObject_DL(port, &obj);
+}
+*/
+import "C"
+import (
+	"encoding/json"
+	"fmt"
+	"unsafe"
+
+	core "core"
+)
+
+// ExternalThreshold is the payload size (in bytes) from which binary sends
+// are posted as external typed data instead of being copied by the VM.
+var ExternalThreshold = 64 * 1024
+
+// Logger receives bridge diagnostics. port is the Dart port involved, or 0.
+// Libraries point it at their log sink; the default prints to stdout.
+var Logger = func(level string, port int64, msg string) {
+	fmt.Println(msg)
+}
+
+type DartResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func InitDartApi(api unsafe.Pointer) {
+	if C.Dart_InitializeApiDL(api) != 0 {
+		panic("failed to create dart bridge")
+	} else {
+		Logger("info", 0, "Dart Api DL is initialized")
+	}
+}
+
+// SendResponseToPort queues response as a JSON string for port.
+func SendResponseToPort(port int64, response *DartResponse) {
+	responseJson, _ := json.Marshal(response)
+	enqueue(port, message{kind: msgString, str: string(responseJson)})
+}
+
+// SendStringToPort queues msg for port. Messages are posted in order by a
+// per-port worker, so the caller never blocks on the Dart side.
+func SendStringToPort(port int64, msg string) {
+	enqueue(port, message{kind: msgString, str: msg})
+}
+
+// SendKeyedStringToPort is SendStringToPort with a key (see core.Key) that
+// the Coalesce policy uses to replace a stale queued message.
+func SendKeyedStringToPort(port int64, key string, msg string) {
+	enqueue(port, message{kind: msgString, key: key, str: msg})
+}
+
+// SendBytesToPort queues data to be posted to Dart as a Uint8List. data
+// must not be modified after the call. It reports whether data was queued.
+func SendBytesToPort(port int64, data []byte) bool {
+	return enqueue(port, message{kind: msgBytes, payload: data})
+}
+
+// SendFrameToPort queues a framed binary message (see FrameHeader) to be
+// posted as a Uint8List. Frames are keyed by op and request id for
+// coalescing. payload must not be modified after the call.
+func SendFrameToPort(port int64, h FrameHeader, payload []byte) bool {
+	return enqueue(port, message{kind: msgFrame, key: core.Key(h.Op, h.RequestID), header: h, payload: payload})
+}
+
+// SendStreamFrameToPort queues a frame that belongs to a byte stream, such
+// as socket data. Unlike SendFrameToPort it is never coalesced or dropped
+// to make room once queued, whatever the port's policy; instead a full
+// queue refuses it, and the caller should end the stream rather than
+// leave a gap in it. payload must not be modified after the call.
+func SendStreamFrameToPort(port int64, h FrameHeader, payload []byte) bool {
+	return enqueue(port, message{kind: msgFrame, stream: true, header: h, payload: payload})
+}
+
+func postString(port int64, msg string) bool {
+	var obj C.Dart_CObject
+	obj._type = C.Dart_CObject_kString
+	msg_obj := C.CString(msg) // go string -> char*s
+	defer C.free(unsafe.Pointer(msg_obj))
+	// union type, we do a force convertion
+	ptr := unsafe.Pointer(&obj.value[0])
+	*(**C.char)(ptr) = msg_obj
+	// the VM copies the string while posting, so it is freed either way
+	ret := C.GoDart_PostCObject(C.Dart_Port_DL(port), &obj)
+	if !ret {
+		Logger("error", port, fmt.Sprintf("post to port %d failed: %s", port, msg))
+	}
+	return bool(ret)
+}
+
+func postBytes(port int64, data []byte) bool {
+	buf := C.malloc(C.size_t(len(data) + 1)) // +1 so empty payloads still get a valid pointer
+	copy(unsafe.Slice((*byte)(buf), len(data)), data)
+	return postBuffer(port, buf, len(data))
+}
+
+// postFrame writes header and payload straight into C memory, so the
+// payload is copied exactly once on the Go side.
+func postFrame(port int64, h FrameHeader, payload []byte) bool {
+	size := h.Size() + len(payload)
+	buf := C.malloc(C.size_t(size))
+	out := unsafe.Slice((*byte)(buf), size)
+	n := h.MarshalTo(out)
+	copy(out[n:], payload)
+	return postBuffer(port, buf, size)
+}
+
+// postBuffer takes ownership of a malloc'ed buffer and posts it. Large
+// buffers go out as external typed data and are freed by the Dart finalizer;
+// everything else is copied by the VM and freed here.
+func postBuffer(port int64, buf unsafe.Pointer, n int) bool {
+	data := (*C.uint8_t)(buf)
+	if n >= ExternalThreshold {
+		if C.GoDart_PostExternalTypedData(C.Dart_Port_DL(port), data, C.intptr_t(n)) {
+			return true
+		}
+		C.free(buf)
+		Logger("error", port, fmt.Sprintf("post to port %d failed: %d bytes (external)", port, n))
+		return false
+	}
+	ret := C.GoDart_PostTypedData(C.Dart_Port_DL(port), data, C.intptr_t(n))
+	C.free(buf)
+	if !ret {
+		Logger("error", port, fmt.Sprintf("post to port %d failed: %d bytes", port, n))
+	}
+	return bool(ret)
+}
@@ -0,0 +1,302 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DropPolicy decides what happens when a port's outbound queue is full.
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest queued message to make room, passing
+	// over stream frames (see SendStreamFrameToPort).
+	DropOldest DropPolicy = iota
+	// DropNewest discards the message being sent.
+	DropNewest
+	// Coalesce, when the queue is full, replaces a queued message that has
+	// the same key (op and request id) with the new one, and falls back to
+	// DropOldest when nothing matches.
+	Coalesce
+)
+
+func (p DropPolicy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop-newest"
+	case Coalesce:
+		return "coalesce"
+	default:
+		return "drop-oldest"
+	}
+}
+
+// ParseDropPolicy accepts "drop-oldest", "drop-newest" or "coalesce".
+func ParseDropPolicy(s string) (DropPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "drop-oldest", "oldest":
+		return DropOldest, nil
+	case "drop-newest", "newest":
+		return DropNewest, nil
+	case "coalesce":
+		return Coalesce, nil
+	}
+	return DropOldest, fmt.Errorf("unknown drop policy %q", s)
+}
+
+// QueueConfig bounds a port's outbound queue.
+type QueueConfig struct {
+	Capacity int
+	Policy   DropPolicy
+}
+
+// DefaultQueueConfig applies to ports that were never configured.
+var DefaultQueueConfig = QueueConfig{Capacity: 1024, Policy: DropOldest}
+
+const (
+	// queues are swept once there are this many, and then again once
+	// there are twice as many as the sweep kept
+	queueSweepAt = 64
+	// an empty, never configured queue this long unused is reaped by the
+	// next sweep
+	queueIdle = time.Minute
+)
+
+// QueueStats is a snapshot of one port's outbound queue.
+type QueueStats struct {
+	Port      int64  `json:"port"`
+	Capacity  int    `json:"capacity"`
+	Policy    string `json:"policy"`
+	Queued    int    `json:"queued"`
+	Enqueued  uint64 `json:"enqueued"`
+	Posted    uint64 `json:"posted"`
+	Dropped   uint64 `json:"dropped"`
+	Coalesced uint64 `json:"coalesced"`
+	Failed    uint64 `json:"failed"`
+}
+
+type msgKind int
+
+const (
+	msgString msgKind = iota
+	msgBytes
+	msgFrame
+)
+
+// message is held in Go memory while queued; C memory is only allocated by
+// the port worker right before posting and is always released afterwards.
+// A stream message is part of a byte stream that must arrive whole: it is
+// never dropped or coalesced once queued, only refused when the queue is
+// full.
+type message struct {
+	kind    msgKind
+	key     string
+	stream  bool
+	str     string
+	header  FrameHeader
+	payload []byte
+}
+
+type portQueue struct {
+	port   int64
+	mu     sync.Mutex
+	cond   *sync.Cond
+	cfg    QueueConfig
+	items  []message
+	stats  QueueStats
+	last   time.Time // of the latest enqueue
+	closed bool      // the worker exits once items is empty
+	reaped bool      // closed by a sweep; senders move to a fresh queue
+}
+
+var (
+	queues        = map[int64]*portQueue{}
+	queuesMu      sync.Mutex
+	queuesSweepAt = queueSweepAt
+)
+
+func queueFor(port int64) *portQueue {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+	q, ok := queues[port]
+	if !ok {
+		if len(queues) >= queuesSweepAt {
+			sweepQueues(time.Now())
+			queuesSweepAt = 2 * len(queues)
+			if queuesSweepAt < queueSweepAt {
+				queuesSweepAt = queueSweepAt
+			}
+		}
+		q = &portQueue{port: port, cfg: DefaultQueueConfig, last: time.Now()}
+		q.cond = sync.NewCond(&q.mu)
+		queues[port] = q
+		go q.run()
+	}
+	return q
+}
+
+// ConfigureQueue sets capacity and drop policy for port. The oldest
+// messages that no longer fit are dropped, stream messages excepted.
+func ConfigureQueue(port int64, cfg QueueConfig) error {
+	if cfg.Capacity <= 0 {
+		return fmt.Errorf("queue capacity must be positive, got %d", cfg.Capacity)
+	}
+	q := queueFor(port)
+	q.mu.Lock()
+	q.cfg = cfg
+	for len(q.items) > cfg.Capacity && q.evict() {
+	}
+	q.mu.Unlock()
+	return nil
+}
+
+// CloseQueue forgets port's queue once what it holds has been posted, and
+// ends its worker. A later send to port starts a fresh queue.
+func CloseQueue(port int64) {
+	queuesMu.Lock()
+	q, ok := queues[port]
+	delete(queues, port)
+	queuesMu.Unlock()
+	if !ok {
+		return
+	}
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Signal()
+	q.mu.Unlock()
+}
+
+// sweepQueues reaps the queues of ports nothing was sent to for
+// queueIdle, such as those of closed subscriber or reply ports, along
+// with their workers. Configured queues are kept. queuesMu must be held.
+func sweepQueues(now time.Time) {
+	for port, q := range queues {
+		q.mu.Lock()
+		if len(q.items) == 0 && q.cfg == DefaultQueueConfig && now.Sub(q.last) > queueIdle {
+			q.closed, q.reaped = true, true
+			q.cond.Signal()
+			delete(queues, port)
+		}
+		q.mu.Unlock()
+	}
+}
+
+// Stats returns a snapshot of every outbound queue.
+func Stats() []QueueStats {
+	queuesMu.Lock()
+	qs := make([]*portQueue, 0, len(queues))
+	for _, q := range queues {
+		qs = append(qs, q)
+	}
+	queuesMu.Unlock()
+	out := make([]QueueStats, 0, len(qs))
+	for _, q := range qs {
+		out = append(out, q.snapshot())
+	}
+	return out
+}
+
+func (q *portQueue) snapshot() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	s := q.stats
+	s.Port = q.port
+	s.Capacity = q.cfg.Capacity
+	s.Policy = q.cfg.Policy.String()
+	s.Queued = len(q.items)
+	return s
+}
+
+// enqueue never blocks; it reports whether m was accepted. A full queue
+// always refuses a stream message, and so does a closed one.
+func enqueue(port int64, m message) bool {
+	q := queueFor(port)
+	q.mu.Lock()
+	for q.reaped {
+		q.mu.Unlock()
+		q = queueFor(port)
+		q.mu.Lock()
+	}
+	defer q.mu.Unlock()
+	q.stats.Enqueued++
+	q.last = time.Now()
+	if q.closed {
+		q.stats.Dropped++
+		return false
+	}
+	if len(q.items) >= q.cfg.Capacity {
+		// only a full queue coalesces, so replies are not lost while there
+		// is room for them
+		if q.cfg.Policy == Coalesce && m.key != "" && !m.stream {
+			for i := range q.items {
+				if q.items[i].key == m.key && !q.items[i].stream {
+					q.items[i] = m
+					q.stats.Coalesced++
+					return true
+				}
+			}
+		}
+		if m.stream || q.cfg.Policy == DropNewest || !q.evict() {
+			q.stats.Dropped++
+			return false
+		}
+	}
+	q.items = append(q.items, m)
+	q.cond.Signal()
+	return true
+}
+
+// evict drops the oldest message that is not part of a stream, and
+// reports whether there was one.
+func (q *portQueue) evict() bool {
+	for i := range q.items {
+		if !q.items[i].stream {
+			copy(q.items[i:], q.items[i+1:])
+			q.items[len(q.items)-1] = message{}
+			q.items = q.items[:len(q.items)-1]
+			q.stats.Dropped++
+			return true
+		}
+	}
+	return false
+}
+
+func (q *portQueue) run() {
+	for {
+		q.mu.Lock()
+		for len(q.items) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.items) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		m := q.items[0]
+		q.items[0] = message{}
+		q.items = q.items[1:]
+		q.mu.Unlock()
+
+		ok := m.post(q.port)
+
+		q.mu.Lock()
+		if ok {
+			q.stats.Posted++
+		} else {
+			q.stats.Failed++
+		}
+		q.mu.Unlock()
+	}
+}
+
+func (m message) post(port int64) bool {
+	switch m.kind {
+	case msgBytes:
+		return postBytes(port, m.payload)
+	case msgFrame:
+		return postFrame(port, m.header, m.payload)
+	default:
+		return postString(port, m.str)
+	}
+}
@@ -0,0 +1,129 @@
+package bridge
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// stalledQueue registers a queue for port with no worker, so nothing
+// leaves it while a test fills it.
+func stalledQueue(t *testing.T, port int64, cfg QueueConfig) *portQueue {
+	t.Helper()
+	q := &portQueue{port: port, cfg: cfg}
+	q.cond = sync.NewCond(&q.mu)
+	queuesMu.Lock()
+	queues[port] = q
+	queuesMu.Unlock()
+	t.Cleanup(func() {
+		queuesMu.Lock()
+		delete(queues, port)
+		queuesMu.Unlock()
+	})
+	return q
+}
+
+func streamFrame(id int64, b byte) message {
+	return message{kind: msgFrame, stream: true, header: FrameHeader{Op: "data", RequestID: id}, payload: []byte{b}}
+}
+
+func TestStreamFramesAreNeverEvicted(t *testing.T) {
+	for _, p := range []DropPolicy{DropOldest, DropNewest, Coalesce} {
+		q := stalledQueue(t, 1, QueueConfig{Capacity: 3, Policy: p})
+		enqueue(1, streamFrame(7, 'a'))
+		enqueue(1, message{kind: msgString, key: "reply#1", str: "r1"})
+		enqueue(1, streamFrame(7, 'b'))
+		if enqueue(1, streamFrame(7, 'c')) {
+			t.Fatalf("%v: full queue took a stream frame", p)
+		}
+		if p == DropNewest {
+			continue
+		}
+		if !enqueue(1, message{kind: msgString, key: "reply#2", str: "r2"}) {
+			t.Fatalf("%v: reply refused while a reply could make room", p)
+		}
+		if q.items[0].payload[0] != 'a' || q.items[1].payload[0] != 'b' || q.items[2].str != "r2" {
+			t.Fatalf("%v: queue holds %+v", p, q.items)
+		}
+		q.items[2] = streamFrame(7, 'c')
+		if enqueue(1, message{kind: msgString, key: "reply#3", str: "r3"}) {
+			t.Fatalf("%v: reply evicted a stream frame", p)
+		}
+	}
+}
+
+func TestStreamFramesDoNotCoalesce(t *testing.T) {
+	q := stalledQueue(t, 1, QueueConfig{Capacity: 1, Policy: Coalesce})
+	enqueue(1, message{kind: msgFrame, key: "data#7", header: FrameHeader{Op: "data", RequestID: 7}})
+	if !enqueue(1, message{kind: msgFrame, key: "data#7", header: FrameHeader{Op: "data", RequestID: 7}, payload: []byte{1}}) {
+		t.Fatal("plain frame did not coalesce")
+	}
+	q.items = q.items[:0]
+	enqueue(1, streamFrame(7, 'a'))
+	if enqueue(1, message{kind: msgFrame, key: "data#7", header: FrameHeader{Op: "data", RequestID: 7}}) {
+		t.Fatal("plain frame replaced a stream frame")
+	}
+	if q.stats.Coalesced != 1 {
+		t.Fatalf("coalesced %d", q.stats.Coalesced)
+	}
+}
+
+func TestConfigureQueueKeepsStreamFrames(t *testing.T) {
+	q := stalledQueue(t, 1, QueueConfig{Capacity: 4, Policy: DropOldest})
+	enqueue(1, streamFrame(7, 'a'))
+	enqueue(1, message{kind: msgString, str: "r"})
+	enqueue(1, streamFrame(7, 'b'))
+	enqueue(1, streamFrame(7, 'c'))
+	if err := ConfigureQueue(1, QueueConfig{Capacity: 1, Policy: DropOldest}); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.items) != 3 {
+		t.Fatalf("%d items left, want the three stream frames", len(q.items))
+	}
+	for i, b := range []byte("abc") {
+		if q.items[i].payload[0] != b {
+			t.Fatalf("item %d is %q", i, q.items[i].payload)
+		}
+	}
+}
+
+func TestClosedQueueDrops(t *testing.T) {
+	q := stalledQueue(t, 1, DefaultQueueConfig)
+	// as seen by a sender that looked the queue up before CloseQueue
+	q.closed = true
+	if enqueue(1, message{kind: msgString, str: "late"}) {
+		t.Fatal("closed queue took a message")
+	}
+	if q.stats.Enqueued != 1 || q.stats.Dropped != 1 || len(q.items) != 0 {
+		t.Fatalf("closed queue stats %+v, %d items", q.stats, len(q.items))
+	}
+}
+
+func TestSweepReapsIdleQueues(t *testing.T) {
+	idle := stalledQueue(t, 1, DefaultQueueConfig)
+	busy := stalledQueue(t, 2, DefaultQueueConfig)
+	configured := stalledQueue(t, 3, QueueConfig{Capacity: 8, Policy: Coalesce})
+	busy.items = append(busy.items, message{kind: msgString, str: "queued"})
+	queuesMu.Lock()
+	sweepQueues(time.Now().Add(2 * queueIdle))
+	_, kept1 := queues[1]
+	_, kept2 := queues[2]
+	_, kept3 := queues[3]
+	queuesMu.Unlock()
+	if kept1 || !idle.reaped {
+		t.Fatal("idle queue not reaped")
+	}
+	if !kept2 || !kept3 || busy.closed || configured.closed {
+		t.Fatal("busy or configured queue reaped")
+	}
+
+	// a sender holding the reaped queue moves to a fresh one
+	if !enqueue(1, message{kind: msgString, str: "again"}) {
+		t.Fatal("send to a reaped port refused")
+	}
+	q := queueFor(1)
+	if q == idle {
+		t.Fatal("reaped queue reused")
+	}
+	CloseQueue(1)
+}
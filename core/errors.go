@@ -0,0 +1,120 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Error codes returned by exports that hand an id back synchronously. Ids
+// are always positive, so any negative return is one of these and the
+// message is available through LastError.
+const (
+	CodeFailed     int64 = -1
+	CodePanic      int64 = -2
+	CodeInvalidArg int64 = -3
+	CodeNotFound   int64 = -4
+)
+
+type codedError struct {
+	code int64
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// WithCode tags err so that Create returns code instead of CodeFailed.
+func WithCode(code int64, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+func codeOf(err error) int64 {
+	var ce *codedError
+	if errors.As(err, &ce) {
+		return ce.code
+	}
+	return CodeFailed
+}
+
+// only the most recent failures are kept; Dart reads them right after the
+// call returns, so older entries are not worth holding on to
+const maxLastErrors = 256
+
+var (
+	lastErrMu    sync.Mutex
+	lastErrs     = map[int64]string{}
+	lastErrOrder []int64
+	// calls without a request id share this slot
+	lastAnonErr    string
+	hasLastAnonErr bool
+)
+
+func setLastError(requestID int64, msg string) {
+	lastErrMu.Lock()
+	defer lastErrMu.Unlock()
+	if requestID == 0 {
+		lastAnonErr, hasLastAnonErr = msg, true
+		return
+	}
+	if _, ok := lastErrs[requestID]; !ok {
+		lastErrOrder = append(lastErrOrder, requestID)
+		if len(lastErrOrder) > maxLastErrors {
+			delete(lastErrs, lastErrOrder[0])
+			lastErrOrder = lastErrOrder[1:]
+		}
+	}
+	lastErrs[requestID] = msg
+}
+
+// LastError returns the message of the last failed Create for requestID.
+// Request id 0 has a slot of its own that holds the most recent failure
+// of any call made without a request id, so with concurrent callers it
+// may belong to another thread's call; pass a nonzero id to be sure.
+func LastError(requestID int64) (string, bool) {
+	lastErrMu.Lock()
+	defer lastErrMu.Unlock()
+	if requestID == 0 {
+		return lastAnonErr, hasLastAnonErr
+	}
+	msg, ok := lastErrs[requestID]
+	return msg, ok
+}
+
+// Create runs fn like SafeOp and additionally returns the id it produced,
+// or a negative error code whose message is recorded for LastError. The
+// response is still posted so port listeners see the result either way.
+func (c Call) Create(op string, fn func() (int64, error)) int64 {
+	return c.create(op, fn, true)
+}
+
+// CreateQuiet is Create for calls made too often to answer each one, such
+// as writes: only failures are posted, and success is left to the return
+// value.
+func (c Call) CreateQuiet(op string, fn func() (int64, error)) int64 {
+	return c.create(op, fn, false)
+}
+
+func (c Call) create(op string, fn func() (int64, error), reply bool) (id int64) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg := fmt.Sprintf("panic: %v", r)
+			setLastError(c.RequestID, msg)
+			c.Send(Resp{Op: op, Success: false, Error: msg})
+			id = CodePanic
+		}
+	}()
+	res, err := fn()
+	if err != nil {
+		setLastError(c.RequestID, err.Error())
+		c.Send(Resp{Op: op, Success: false, Error: err.Error()})
+		return codeOf(err)
+	}
+	if reply {
+		c.Send(Resp{Op: op, Success: true, Data: res})
+	}
+	return res
+}
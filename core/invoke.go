@@ -0,0 +1,263 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ParamType is the JSON type an Invoke parameter must have.
+type ParamType string
+
+const (
+	TypeInt     ParamType = "int"
+	TypeFloat   ParamType = "float"
+	TypeString  ParamType = "string"
+	TypeBool    ParamType = "bool"
+	TypeStrings ParamType = "[]string"
+	TypeObject  ParamType = "object"
+	TypeList    ParamType = "list"
+	// TypeBytes is a string of standard base64
+	TypeBytes ParamType = "base64"
+)
+
+// Param describes one named argument of an operation.
+type Param struct {
+	Name     string    `json:"name"`
+	Type     ParamType `json:"type"`
+	Optional bool      `json:"optional,omitempty"`
+}
+
+func IntParam(name string) Param    { return Param{Name: name, Type: TypeInt} }
+func FloatParam(name string) Param  { return Param{Name: name, Type: TypeFloat} }
+func StrParam(name string) Param    { return Param{Name: name, Type: TypeString} }
+func BoolParam(name string) Param   { return Param{Name: name, Type: TypeBool} }
+func StrsParam(name string) Param   { return Param{Name: name, Type: TypeStrings} }
+func ObjectParam(name string) Param { return Param{Name: name, Type: TypeObject} }
+func ListParam(name string) Param   { return Param{Name: name, Type: TypeList} }
+func BytesParam(name string) Param  { return Param{Name: name, Type: TypeBytes} }
+
+// Opt marks p as optional; missing optional params read as zero values.
+func (p Param) Opt() Param {
+	p.Optional = true
+	return p
+}
+
+// Handler runs an operation and returns what the matching export returns
+// (an id, a task id, or 0). Replies go out through call like any export.
+type Handler func(call Call, args Args) int64
+
+// Operation is one method reachable through Invoke.
+type Operation struct {
+	Method string  `json:"method"`
+	Doc    string  `json:"doc,omitempty"`
+	Params []Param `json:"params"`
+	// Returns describes the synchronous return value, if any.
+	Returns string `json:"returns,omitempty"`
+
+	handler Handler
+}
+
+var (
+	opsMu sync.RWMutex
+	ops   = map[string]Operation{}
+)
+
+// Register makes op callable through Invoke. Registering a method twice
+// replaces the earlier entry.
+func Register(op Operation, h Handler) {
+	if op.Params == nil {
+		op.Params = []Param{}
+	}
+	op.handler = h
+	opsMu.Lock()
+	ops[op.Method] = op
+	opsMu.Unlock()
+}
+
+// Operations returns every registered method sorted by name.
+func Operations() []Operation {
+	opsMu.RLock()
+	out := make([]Operation, 0, len(ops))
+	for _, op := range ops {
+		out = append(out, op)
+	}
+	opsMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Method < out[j].Method })
+	return out
+}
+
+// Request is the envelope accepted by Invoke.
+type Request struct {
+	Method string          `json:"method"`
+	ID     int64           `json:"id"`
+	Params json.RawMessage `json:"params"`
+}
+
+// Invoke decodes a Request, checks its params against the method schema
+// and runs the handler. Every library registers each of its exports under
+// its C symbol name, with params named after the export's arguments and
+// ID used as the request id, so the return value is what the export
+// itself returns. Malformed requests are answered under op "invoke" and
+// return a negative code, with the message kept for LastError.
+func Invoke(requestJSON []byte, port int64) int64 {
+	var req Request
+	if err := json.Unmarshal(requestJSON, &req); err != nil {
+		return invokeError(NewCall(port, 0), "", CodeInvalidArg, fmt.Errorf("invalid request: %v", err))
+	}
+	call := NewCall(port, req.ID)
+	opsMu.RLock()
+	op, ok := ops[req.Method]
+	opsMu.RUnlock()
+	if !ok {
+		return invokeError(call, req.Method, CodeNotFound, fmt.Errorf("unknown method %q", req.Method))
+	}
+	args, err := decodeArgs(op, req.Params)
+	if err != nil {
+		return invokeError(call, req.Method, CodeInvalidArg, err)
+	}
+	return runHandler(op, call, args)
+}
+
+func runHandler(op Operation, call Call, args Args) (ret int64) {
+	defer func() {
+		if r := recover(); r != nil {
+			ret = invokeError(call, op.Method, CodePanic, fmt.Errorf("panic: %v", r))
+		}
+	}()
+	return op.handler(call, args)
+}
+
+func invokeError(call Call, method string, code int64, err error) int64 {
+	setLastError(call.RequestID, err.Error())
+	call.Send(Resp{Op: "invoke", Success: false, Error: err.Error(), Data: method})
+	return code
+}
+
+func decodeArgs(op Operation, raw json.RawMessage) (Args, error) {
+	args := Args{}
+	if len(raw) > 0 && string(raw) != "null" {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&args); err != nil {
+			return nil, fmt.Errorf("%s: params must be an object: %v", op.Method, err)
+		}
+	}
+	for _, p := range op.Params {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("%s: missing param %q", op.Method, p.Name)
+		}
+		if !typeMatches(p.Type, v) {
+			return nil, fmt.Errorf("%s: param %q must be %s", op.Method, p.Name, p.Type)
+		}
+	}
+	return args, nil
+}
+
+func typeMatches(t ParamType, v interface{}) bool {
+	switch t {
+	case TypeInt:
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case TypeFloat:
+		_, ok := v.(json.Number)
+		return ok
+	case TypeString:
+		_, ok := v.(string)
+		return ok
+	case TypeBool:
+		_, ok := v.(bool)
+		return ok
+	case TypeStrings:
+		list, ok := v.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, e := range list {
+			if _, ok := e.(string); !ok {
+				return false
+			}
+		}
+		return true
+	case TypeObject:
+		_, ok := v.(map[string]interface{})
+		return ok
+	case TypeList:
+		_, ok := v.([]interface{})
+		return ok
+	case TypeBytes:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		_, err := base64.StdEncoding.DecodeString(s)
+		return err == nil
+	}
+	return false
+}
+
+// Args holds the decoded params of a validated request. Getters return the
+// zero value for optional params that were left out.
+type Args map[string]interface{}
+
+func (a Args) Int64(name string) int64 {
+	n, _ := a[name].(json.Number)
+	v, _ := n.Int64()
+	return v
+}
+
+func (a Args) Int(name string) int { return int(a.Int64(name)) }
+
+func (a Args) Float(name string) float64 {
+	n, _ := a[name].(json.Number)
+	v, _ := n.Float64()
+	return v
+}
+
+func (a Args) String(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+func (a Args) Bool(name string) bool {
+	b, _ := a[name].(bool)
+	return b
+}
+
+func (a Args) Strings(name string) []string {
+	list, _ := a[name].([]interface{})
+	out := make([]string, 0, len(list))
+	for _, e := range list {
+		s, _ := e.(string)
+		out = append(out, s)
+	}
+	return out
+}
+
+// Bytes decodes a base64 param.
+func (a Args) Bytes(name string) []byte {
+	b, _ := base64.StdEncoding.DecodeString(a.String(name))
+	return b
+}
+
+// JSON re-encodes a param, for handing objects or lists to exports that
+// take them as JSON strings.
+func (a Args) JSON(name string) string {
+	v, ok := a[name]
+	if !ok {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level orders log records by severity.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// ParseLevel accepts "debug", "info", "warn" (or "warning") and "error".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug", "trace":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error", "fatal", "panic":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// LogRecord is posted to the log port as the Data of a "log" response.
+type LogRecord struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Module  string                 `json:"module"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+var (
+	logMu    sync.RWMutex
+	logPort  int64
+	logLevel = LevelInfo
+)
+
+// SetLogPort routes log records to port, in addition to subscribers of
+// the "logs" topic. With neither, records go to stderr.
+func SetLogPort(port int64) {
+	logMu.Lock()
+	logPort = port
+	logMu.Unlock()
+}
+
+// SetLogLevel drops records below l from now on.
+func SetLogLevel(l Level) {
+	logMu.Lock()
+	logLevel = l
+	logMu.Unlock()
+}
+
+// LogLevel returns the current threshold.
+func LogLevel() Level {
+	logMu.RLock()
+	defer logMu.RUnlock()
+	return logLevel
+}
+
+// Log emits one record for module if l passes the current level.
+func Log(l Level, module, msg string, fields map[string]interface{}) {
+	logMu.RLock()
+	port, min := logPort, logLevel
+	logMu.RUnlock()
+	if l < min {
+		return
+	}
+	rec := LogRecord{Time: time.Now(), Level: l.String(), Module: module, Message: msg, Fields: fields}
+	if port == 0 && len(Subscribers(TopicLogs)) == 0 {
+		writeStderr(rec)
+		return
+	}
+	b, err := json.Marshal(Resp{Op: "log", Success: true, Data: rec})
+	if err != nil {
+		// fields that do not marshal are flattened to strings
+		rec.Fields = stringFields(fields)
+		b, _ = json.Marshal(Resp{Op: "log", Success: true, Data: rec})
+	}
+	// records are never keyed, so Coalesce queues cannot merge them
+	EmitString(TopicLogs, port, "", string(b))
+}
+
+func writeStderr(rec LogRecord) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %-5s [%s] %s", rec.Time.Format(time.RFC3339), rec.Level, rec.Module, rec.Message)
+	keys := make([]string, 0, len(rec.Fields))
+	for k := range rec.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, rec.Fields[k])
+	}
+	sb.WriteByte('\n')
+	os.Stderr.WriteString(sb.String())
+}
+
+func stringFields(fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+// Logger tags records with a module name.
+type Logger struct {
+	Module string
+}
+
+func (lg Logger) Debugf(format string, args ...interface{}) {
+	Log(LevelDebug, lg.Module, fmt.Sprintf(format, args...), nil)
+}
+
+func (lg Logger) Infof(format string, args ...interface{}) {
+	Log(LevelInfo, lg.Module, fmt.Sprintf(format, args...), nil)
+}
+
+func (lg Logger) Warnf(format string, args ...interface{}) {
+	Log(LevelWarn, lg.Module, fmt.Sprintf(format, args...), nil)
+}
+
+func (lg Logger) Errorf(format string, args ...interface{}) {
+	Log(LevelError, lg.Module, fmt.Sprintf(format, args...), nil)
+}
+
+// With logs msg at l with fields.
+func (lg Logger) With(l Level, msg string, fields map[string]interface{}) {
+	Log(l, lg.Module, msg, fields)
+}
+
+// LogWriter returns a writer that emits every complete line written to it
+// as a record at level l, for code that only knows how to write to an
+// io.Writer. fields are attached to every record.
+func LogWriter(module string, l Level, fields map[string]interface{}) io.Writer {
+	return &lineWriter{module: module, level: l, fields: fields}
+}
+
+type lineWriter struct {
+	mu     sync.Mutex
+	module string
+	level  Level
+	fields map[string]interface{}
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.buf[:i]), "\r")
+		w.buf = w.buf[i+1:]
+		if line != "" {
+			Log(w.level, w.module, line, w.fields)
+		}
+	}
+	return len(p), nil
+}
+
+// CaptureStdLog redirects the standard log package into records of
+// module "log" at info level.
+func CaptureStdLog() {
+	log.SetFlags(0)
+	log.SetOutput(LogWriter("log", LevelInfo, nil))
+}
+
+// BridgeLogger is meant for bridge.Logger. A failed post to a port that
+// receives logs goes straight to stderr; reporting it as a record would
+// just queue another post to the same dead port.
+func BridgeLogger(level string, port int64, msg string) {
+	l, _ := ParseLevel(level)
+	logMu.RLock()
+	toLogPort := port != 0 && port == logPort
+	logMu.RUnlock()
+	if toLogPort || subscribed(TopicLogs, port) {
+		writeStderr(LogRecord{Time: time.Now(), Level: l.String(), Module: "bridge", Message: msg})
+		return
+	}
+	var fields map[string]interface{}
+	if port != 0 {
+		fields = map[string]interface{}{"port": port}
+	}
+	Log(l, "bridge", msg, fields)
+}
@@ -0,0 +1,135 @@
+// Package core holds the state every native library shares: the Dart port
+// registry, the response envelope, panic-safe operation wrappers and the
+// task registry. Libraries wire core/bridge in with SetPoster.
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Poster delivers a message to a Dart port. key, from Key, is what the
+// bridge queue coalesces on; it is empty for plain status strings.
+type Poster func(port int64, key string, msg string)
+
+var (
+	poster   Poster = func(int64, string, string) {}
+	posterMu sync.RWMutex
+)
+
+var (
+	registeredPort int64
+	portMu         sync.RWMutex
+)
+
+// Resp is the JSON envelope for every response and event sent to Dart.
+type Resp struct {
+	Op        string      `json:"op"`
+	RequestID int64       `json:"request_id,omitempty"`
+	Success   bool        `json:"success"`
+	Error     string      `json:"error,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// SetPoster installs the function used to reach Dart, normally
+// bridge.SendKeyedStringToPort.
+func SetPoster(p Poster) {
+	posterMu.Lock()
+	poster = p
+	posterMu.Unlock()
+}
+
+// RegisterPort makes port the default for calls that pass port 0.
+func RegisterPort(port int64) {
+	portMu.Lock()
+	registeredPort = port
+	portMu.Unlock()
+	SendString(port, "registered")
+}
+
+// UnregisterPort clears the default port and returns it, 0 if none was
+// registered.
+func UnregisterPort() int64 {
+	portMu.Lock()
+	p := registeredPort
+	registeredPort = 0
+	portMu.Unlock()
+	if p != 0 {
+		SendString(p, "unregistered")
+	}
+	return p
+}
+
+// PortOrDefault returns p, or the registered port when p is 0.
+func PortOrDefault(p int64) int64 {
+	if p != 0 {
+		return p
+	}
+	portMu.RLock()
+	defer portMu.RUnlock()
+	return registeredPort
+}
+
+// Key identifies the messages of one op for one request, so a full queue
+// only ever replaces a message with a newer one of the same kind.
+func Key(op string, requestID int64) string {
+	return op + "#" + strconv.FormatInt(requestID, 10)
+}
+
+// Send marshals r and posts it to port.
+func Send(port int64, r Resp) {
+	b, _ := json.Marshal(r)
+	post(port, Key(r.Op, r.RequestID), string(b))
+}
+
+// SendString posts a plain status string to port.
+func SendString(port int64, msg string) {
+	post(port, "", msg)
+}
+
+func post(port int64, key, msg string) {
+	posterMu.RLock()
+	p := poster
+	posterMu.RUnlock()
+	p(port, key, msg)
+}
+
+// SafeOp runs fn and reports its result, error or panic to port under op.
+func SafeOp(port int64, op string, fn func() (interface{}, error)) {
+	Call{Port: port}.SafeOp(op, fn)
+}
+
+// Call identifies who asked for an operation: the port replies go to and
+// the caller-supplied request id echoed back in every reply and event.
+type Call struct {
+	Port      int64
+	RequestID int64
+}
+
+// NewCall resolves port against the registered default.
+func NewCall(port int64, requestID int64) Call {
+	return Call{Port: PortOrDefault(port), RequestID: requestID}
+}
+
+// Send stamps r with the call's request id and posts it.
+func (c Call) Send(r Resp) {
+	r.RequestID = c.RequestID
+	Send(c.Port, r)
+}
+
+// SafeOp runs fn and reports its result, error or panic under op.
+func (c Call) SafeOp(op string, fn func() (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.Send(Resp{Op: op, Success: false, Error: fmt.Sprintf("panic: %v", r)})
+		}
+	}()
+	res, err := fn()
+	if err != nil {
+		c.Send(Resp{Op: op, Success: false, Error: err.Error()})
+		return
+	}
+	c.Send(Resp{Op: op, Success: true, Data: res})
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ShutdownFunc stops one kind of resource before ctx expires and returns
+// the ones it could not stop cleanly, which are reported as forced.
+type ShutdownFunc func(ctx context.Context) (forced []string)
+
+type shutdownHook struct {
+	name string
+	fn   ShutdownFunc
+}
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []shutdownHook
+)
+
+// OnShutdown registers fn to run during Shutdown under name.
+func OnShutdown(name string, fn ShutdownFunc) {
+	shutdownMu.Lock()
+	shutdownHooks = append(shutdownHooks, shutdownHook{name: name, fn: fn})
+	shutdownMu.Unlock()
+}
+
+// ShutdownReport lists what stopped within the deadline and what was
+// abandoned. Forced tasks are dropped from the registry, but their
+// goroutines may still be running.
+type ShutdownReport struct {
+	Stopped   []string `json:"stopped"`
+	Forced    []string `json:"forced"`
+	ElapsedMs int64    `json:"elapsed_ms"`
+}
+
+// Shutdown cancels every task, runs the shutdown hooks concurrently and
+// waits for all of it until timeout has passed.
+func Shutdown(timeout time.Duration) ShutdownReport {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// canceling first lets tasks stop the servers they own on their own
+	pending := cancelAllTasks()
+
+	shutdownMu.Lock()
+	hooks := append([]shutdownHook(nil), shutdownHooks...)
+	shutdownMu.Unlock()
+
+	report := ShutdownReport{Stopped: []string{}, Forced: []string{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, h := range hooks {
+		wg.Add(1)
+		go func(h shutdownHook) {
+			defer wg.Done()
+			done := make(chan []string, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- []string{fmt.Sprintf("%s (panic: %v)", h.name, r)}
+					}
+				}()
+				done <- h.fn(ctx)
+			}()
+			var forced []string
+			select {
+			case forced = <-done:
+			case <-ctx.Done():
+				forced = []string{h.name}
+			}
+			mu.Lock()
+			if len(forced) == 0 {
+				report.Stopped = append(report.Stopped, h.name)
+			} else {
+				report.Forced = append(report.Forced, forced...)
+			}
+			mu.Unlock()
+		}(h)
+	}
+
+	for _, e := range pending {
+		name := fmt.Sprintf("task %d (%s)", e.info.ID, e.info.Op)
+		select {
+		case <-e.done:
+			mu.Lock()
+			report.Stopped = append(report.Stopped, name)
+			mu.Unlock()
+		case <-ctx.Done():
+			forceEndTask(e.info.ID, "did not stop before the shutdown deadline")
+			mu.Lock()
+			report.Forced = append(report.Forced, name)
+			mu.Unlock()
+		}
+	}
+	wg.Wait()
+
+	sort.Strings(report.Stopped)
+	sort.Strings(report.Forced)
+	report.ElapsedMs = time.Since(start).Milliseconds()
+	return report
+}
+
+// cancelAllTasks cancels every running task like StopTask does, without
+// waiting, and returns them. Uncancelable ones are returned too, so
+// Shutdown still waits for them.
+func cancelAllTasks() []*taskRec {
+	tasksMu.Lock()
+	out := make([]*taskRec, 0, len(tasks))
+	for _, e := range tasks {
+		e.canceled = true
+		out = append(out, e)
+	}
+	tasksMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].info.ID < out[j].info.ID })
+	for _, e := range out {
+		if e.cancel != nil {
+			e.cancel()
+		}
+	}
+	return out
+}
+
+// forceEndTask drops a task that would not stop, recording why.
+func forceEndTask(id int64, reason string) {
+	tasksMu.Lock()
+	if e, ok := tasks[id]; ok {
+		e.info.Error = reason
+	}
+	tasksMu.Unlock()
+	endTask(id, "")
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TaskState is the lifecycle state of a task.
+type TaskState string
+
+const (
+	TaskRunning  TaskState = "running"
+	TaskFinished TaskState = "finished"
+	TaskCanceled TaskState = "canceled"
+	TaskPanicked TaskState = "panicked"
+)
+
+// StopTimeout is how long the StopTask exports wait for a canceled task.
+const StopTimeout = 5 * time.Second
+
+// TaskInfo is the metadata reported by ListTasks, GetTask and task events.
+// Cancelable is false for tasks started with GoUncancelable.
+type TaskInfo struct {
+	ID         int64     `json:"id"`
+	Op         string    `json:"op"`
+	Port       int64     `json:"port"`
+	RequestID  int64     `json:"request_id,omitempty"`
+	State      TaskState `json:"state"`
+	Cancelable bool      `json:"cancelable"`
+	StartedAt  time.Time `json:"started_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type taskRec struct {
+	info     TaskInfo
+	cancel   context.CancelFunc
+	done     chan struct{}
+	canceled bool
+}
+
+var (
+	tasks    = map[int64]*taskRec{}
+	tasksMu  sync.Mutex
+	nextTask int64 // start from 0; atomic increment gives 1,2,...
+)
+
+// AddTask registers a running task owned by call and emits task_started.
+// A nil cancel marks a task StopTask cannot stop.
+func AddTask(op string, call Call, cancel context.CancelFunc) int64 {
+	id := atomic.AddInt64(&nextTask, 1)
+	entry := &taskRec{
+		info: TaskInfo{
+			ID:         id,
+			Op:         op,
+			Port:       call.Port,
+			RequestID:  call.RequestID,
+			State:      TaskRunning,
+			Cancelable: cancel != nil,
+			StartedAt:  time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	tasksMu.Lock()
+	tasks[id] = entry
+	tasksMu.Unlock()
+	sendTaskEvent("task_started", entry.info)
+	return id
+}
+
+// FinishTask removes a task and emits task_finished, or task_canceled when
+// it was stopped through StopTask.
+func FinishTask(id int64) {
+	endTask(id, "")
+}
+
+// PanicTask removes a task that died with a panic and emits task_panicked.
+func PanicTask(id int64, r interface{}) {
+	endTask(id, fmt.Sprintf("%v", r))
+}
+
+func endTask(id int64, panicMsg string) {
+	tasksMu.Lock()
+	e, ok := tasks[id]
+	if ok {
+		delete(tasks, id)
+		switch {
+		case panicMsg != "":
+			e.info.State = TaskPanicked
+			e.info.Error = panicMsg
+		case e.canceled:
+			e.info.State = TaskCanceled
+		default:
+			e.info.State = TaskFinished
+		}
+		close(e.done)
+	}
+	tasksMu.Unlock()
+	if ok {
+		sendTaskEvent("task_"+string(e.info.State), e.info)
+	}
+}
+
+// Go runs fn as a task owned by call. A panic in fn is reported as a failed
+// op response plus a task_panicked event; ctx is canceled by StopTask.
+func Go(op string, call Call, fn func(ctx context.Context, id int64)) int64 {
+	ctx, cancel := context.WithCancel(context.Background())
+	return goTask(ctx, cancel, op, call, fn)
+}
+
+// GoUncancelable is Go for work that has no way to be interrupted, such as
+// a server whose Run never returns. StopTask refuses the task, and
+// Shutdown reports it as forced unless it ends on its own.
+func GoUncancelable(op string, call Call, fn func(id int64)) int64 {
+	return goTask(context.Background(), nil, op, call, func(_ context.Context, id int64) {
+		fn(id)
+	})
+}
+
+func goTask(ctx context.Context, cancel context.CancelFunc, op string, call Call, fn func(ctx context.Context, id int64)) int64 {
+	id := AddTask(op, call, cancel)
+	go func() {
+		if cancel != nil {
+			defer cancel()
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				call.Send(Resp{Op: op, Success: false, Error: fmt.Sprintf("%v", r)})
+				PanicTask(id, r)
+				return
+			}
+			FinishTask(id)
+		}()
+		fn(ctx, id)
+	}()
+	return id
+}
+
+// StopTask cancels a task and waits up to timeout for it to finish. A task
+// still running then is dropped from the registry and reported as forced,
+// as Shutdown does; its goroutine may still be running.
+func StopTask(id int64, timeout time.Duration) (forced bool, err error) {
+	tasksMu.Lock()
+	entry, ok := tasks[id]
+	if ok && entry.cancel != nil {
+		entry.canceled = true
+	}
+	tasksMu.Unlock()
+	if !ok {
+		return false, fmt.Errorf("task %d not found", id)
+	}
+	if entry.cancel == nil {
+		return false, fmt.Errorf("task %d (%s) cannot be canceled", id, entry.info.Op)
+	}
+	entry.cancel()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-entry.done:
+		return false, nil
+	case <-t.C:
+		forceEndTask(id, fmt.Sprintf("did not stop within %v", timeout))
+		return true, nil
+	}
+}
+
+// ListTasks returns the running tasks ordered by id.
+func ListTasks() []TaskInfo {
+	tasksMu.Lock()
+	out := make([]TaskInfo, 0, len(tasks))
+	for _, e := range tasks {
+		out = append(out, e.info)
+	}
+	tasksMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// GetTask returns a running task's metadata.
+func GetTask(id int64) (TaskInfo, bool) {
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+	e, ok := tasks[id]
+	if !ok {
+		return TaskInfo{}, false
+	}
+	return e.info, true
+}
+
+// task lifecycle events go to the owning port and the "tasks" topic:
+// task_started, task_finished, task_canceled and task_panicked
+func sendTaskEvent(op string, info TaskInfo) {
+	Emit(TopicTasks, info.Port, Resp{Op: op, RequestID: info.RequestID, Success: info.State != TaskPanicked, Error: info.Error, Data: info})
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Topics published by the libraries. Subscribe accepts any non-empty
+// topic, so a library can add its own without touching core.
+const (
+	TopicTasks             = "tasks"              // every library
+	TopicLogs              = "logs"               // every library
+	TopicSocks5Connections = "socks5.connections" // socks5lib2
+	TopicSocks5Upstreams   = "socks5.upstreams"   // socks5lib2
+	TopicPortforwardStatus = "portforward.status" // portforward
+	TopicSentinelHook      = "sentinel.hook"      // sentinel
+	TopicSentinelMonitor   = "sentinel.monitor"   // sentinel
+)
+
+var (
+	subsMu sync.RWMutex
+	subs   = map[string]map[int64]struct{}{}
+)
+
+// Subscribe adds port to topic's subscribers.
+func Subscribe(topic string, port int64) error {
+	if topic == "" {
+		return fmt.Errorf("topic must not be empty")
+	}
+	if port == 0 {
+		return fmt.Errorf("port must not be 0")
+	}
+	subsMu.Lock()
+	defer subsMu.Unlock()
+	set, ok := subs[topic]
+	if !ok {
+		set = map[int64]struct{}{}
+		subs[topic] = set
+	}
+	set[port] = struct{}{}
+	return nil
+}
+
+// Unsubscribe removes port from topic, or from every topic when topic is
+// empty (e.g. when an isolate shuts down).
+func Unsubscribe(topic string, port int64) {
+	subsMu.Lock()
+	defer subsMu.Unlock()
+	for t, set := range subs {
+		if topic != "" && t != topic {
+			continue
+		}
+		delete(set, port)
+		if len(set) == 0 {
+			delete(subs, t)
+		}
+	}
+}
+
+// Subscribers returns topic's subscribers in ascending order.
+func Subscribers(topic string) []int64 {
+	subsMu.RLock()
+	out := make([]int64, 0, len(subs[topic]))
+	for p := range subs[topic] {
+		out = append(out, p)
+	}
+	subsMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Subscriptions maps every topic that has subscribers to its ports.
+func Subscriptions() map[string][]int64 {
+	subsMu.RLock()
+	topics := make([]string, 0, len(subs))
+	for t := range subs {
+		topics = append(topics, t)
+	}
+	subsMu.RUnlock()
+	out := make(map[string][]int64, len(topics))
+	for _, t := range topics {
+		if ps := Subscribers(t); len(ps) > 0 {
+			out[t] = ps
+		}
+	}
+	return out
+}
+
+func subscribed(topic string, port int64) bool {
+	subsMu.RLock()
+	defer subsMu.RUnlock()
+	_, ok := subs[topic][port]
+	return ok
+}
+
+// Publish posts msg to every subscriber of topic except skip, the port
+// that already received it directly (0 skips nobody).
+func Publish(topic string, skip int64, key, msg string) {
+	for _, p := range Subscribers(topic) {
+		if p != skip {
+			post(p, key, msg)
+		}
+	}
+}
+
+// PublishResp is Publish for a response envelope keyed by its op and
+// request id.
+func PublishResp(topic string, skip int64, r Resp) {
+	ps := Subscribers(topic)
+	if len(ps) == 0 {
+		return
+	}
+	b, _ := json.Marshal(r)
+	for _, p := range ps {
+		if p != skip {
+			post(p, Key(r.Op, r.RequestID), string(b))
+		}
+	}
+}
+
+// Emit sends r to port and fans it out to topic's other subscribers.
+func Emit(topic string, port int64, r Resp) {
+	if port != 0 {
+		Send(port, r)
+	}
+	PublishResp(topic, port, r)
+}
+
+// EmitString is Emit for a pre-encoded message.
+func EmitString(topic string, port int64, key, msg string) {
+	if port != 0 {
+		post(port, key, msg)
+	}
+	Publish(topic, port, key, msg)
+}
+
+// Emit is Emit stamped with the call's request id and sent to its port.
+func (c Call) Emit(topic string, r Resp) {
+	r.RequestID = c.RequestID
+	Emit(topic, c.Port, r)
+}
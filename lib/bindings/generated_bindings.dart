@@ -683,42 +683,210 @@ class SentinelLibrary {
   late final _BridgeInit =
       _BridgeInitPtr.asFunction<void Function(ffi.Pointer<ffi.Void>)>();
 
-  void Move(int x, int y, int port) {
-    return _Move(x, y, port);
+  void ConfigureQueue(
+    int targetPort,
+    int capacity,
+    ffi.Pointer<ffi.Char> policy,
+    int reqID,
+    int port,
+  ) {
+    return _ConfigureQueue(targetPort, capacity, policy, reqID, port);
+  }
+
+  late final _ConfigureQueuePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Int,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ConfigureQueue');
+  late final _ConfigureQueue =
+      _ConfigureQueuePtr.asFunction<
+        void Function(int, int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetQueueStats(int reqID, int port) {
+    return _GetQueueStats(reqID, port);
+  }
+
+  late final _GetQueueStatsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('GetQueueStats');
+  late final _GetQueueStats =
+      _GetQueueStatsPtr.asFunction<void Function(int, int)>();
+
+  void SetLogPort(
+    int logPort,
+    ffi.Pointer<ffi.Char> level,
+    int reqID,
+    int port,
+  ) {
+    return _SetLogPort(logPort, level, reqID, port);
+  }
+
+  late final _SetLogPortPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetLogPort');
+  late final _SetLogPort =
+      _SetLogPortPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void SetLogLevel(ffi.Pointer<ffi.Char> level, int reqID, int port) {
+    return _SetLogLevel(level, reqID, port);
+  }
+
+  late final _SetLogLevelPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetLogLevel');
+  late final _SetLogLevel =
+      _SetLogLevelPtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void Subscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Subscribe(topic, subPort, reqID, port);
+  }
+
+  late final _SubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Subscribe');
+  late final _Subscribe =
+      _SubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void Unsubscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Unsubscribe(topic, subPort, reqID, port);
+  }
+
+  late final _UnsubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Unsubscribe');
+  late final _Unsubscribe =
+      _UnsubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void ListSubscriptions(int reqID, int port) {
+    return _ListSubscriptions(reqID, port);
+  }
+
+  late final _ListSubscriptionsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListSubscriptions');
+  late final _ListSubscriptions =
+      _ListSubscriptionsPtr.asFunction<void Function(int, int)>();
+
+  void Shutdown(int timeoutMs, int reqID, int port) {
+    return _Shutdown(timeoutMs, reqID, port);
+  }
+
+  late final _ShutdownPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('Shutdown');
+  late final _Shutdown =
+      _ShutdownPtr.asFunction<void Function(int, int, int)>();
+
+  void Move(int x, int y, int reqID, int port) {
+    return _Move(x, y, reqID, port);
   }
 
   late final _MovePtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
       >('Move');
-  late final _Move = _MovePtr.asFunction<void Function(int, int, int)>();
+  late final _Move = _MovePtr.asFunction<void Function(int, int, int, int)>();
 
-  void MoveRelative(int x, int y, int port) {
-    return _MoveRelative(x, y, port);
+  void MoveRelative(int x, int y, int reqID, int port) {
+    return _MoveRelative(x, y, reqID, port);
   }
 
   late final _MoveRelativePtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
       >('MoveRelative');
   late final _MoveRelative =
-      _MoveRelativePtr.asFunction<void Function(int, int, int)>();
+      _MoveRelativePtr.asFunction<void Function(int, int, int, int)>();
 
-  void Click(ffi.Pointer<ffi.Char> btn, int dbl, int port) {
-    return _Click(btn, dbl, port);
+  void Click(ffi.Pointer<ffi.Char> btn, int dbl, int reqID, int port) {
+    return _Click(btn, dbl, reqID, port);
   }
 
   late final _ClickPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.Int, ffi.LongLong)
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.Int,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
         >
       >('Click');
   late final _Click =
-      _ClickPtr.asFunction<void Function(ffi.Pointer<ffi.Char>, int, int)>();
+      _ClickPtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
 
-  void Toggle(ffi.Pointer<ffi.Char> btn, ffi.Pointer<ffi.Char> dir, int port) {
-    return _Toggle(btn, dir, port);
+  void Toggle(
+    ffi.Pointer<ffi.Char> btn,
+    ffi.Pointer<ffi.Char> dir,
+    int reqID,
+    int port,
+  ) {
+    return _Toggle(btn, dir, reqID, port);
   }
 
   late final _TogglePtr =
@@ -728,139 +896,145 @@ class SentinelLibrary {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('Toggle');
   late final _Toggle =
       _TogglePtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int, int)
       >();
 
-  void Scroll(int x, int y, int port) {
-    return _Scroll(x, y, port);
+  void Scroll(int x, int y, int reqID, int port) {
+    return _Scroll(x, y, reqID, port);
   }
 
   late final _ScrollPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
       >('Scroll');
-  late final _Scroll = _ScrollPtr.asFunction<void Function(int, int, int)>();
+  late final _Scroll =
+      _ScrollPtr.asFunction<void Function(int, int, int, int)>();
 
-  void ScrollDir(int amount, ffi.Pointer<ffi.Char> dir, int port) {
-    return _ScrollDir(amount, dir, port);
+  void ScrollDir(int amount, ffi.Pointer<ffi.Char> dir, int reqID, int port) {
+    return _ScrollDir(amount, dir, reqID, port);
   }
 
   late final _ScrollDirPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Int, ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(
+            ffi.Int,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
         >
       >('ScrollDir');
   late final _ScrollDir =
       _ScrollDirPtr.asFunction<
-        void Function(int, ffi.Pointer<ffi.Char>, int)
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
       >();
 
-  void GetLocation(int port) {
-    return _GetLocation(port);
+  void GetLocation(int reqID, int port) {
+    return _GetLocation(reqID, port);
   }
 
   late final _GetLocationPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.LongLong)>>(
-        'GetLocation',
-      );
-  late final _GetLocation = _GetLocationPtr.asFunction<void Function(int)>();
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('GetLocation');
+  late final _GetLocation =
+      _GetLocationPtr.asFunction<void Function(int, int)>();
 
-  void SetMouseSleep(int ms, int port) {
-    return _SetMouseSleep(ms, port);
+  void SetMouseSleep(int ms, int reqID, int port) {
+    return _SetMouseSleep(ms, reqID, port);
   }
 
   late final _SetMouseSleepPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.LongLong)>>(
-        'SetMouseSleep',
-      );
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetMouseSleep');
   late final _SetMouseSleep =
-      _SetMouseSleepPtr.asFunction<void Function(int, int)>();
+      _SetMouseSleepPtr.asFunction<void Function(int, int, int)>();
 
-  void MilliSleep(int ms, int port) {
-    return _MilliSleep(ms, port);
+  void MilliSleep(int ms, int reqID, int port) {
+    return _MilliSleep(ms, reqID, port);
   }
 
   late final _MilliSleepPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.LongLong)>>(
-        'MilliSleep',
-      );
-  late final _MilliSleep = _MilliSleepPtr.asFunction<void Function(int, int)>();
-
-  void StopTask(int taskID, int port) {
-    return _StopTask(taskID, port);
-  }
-
-  late final _StopTaskPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
-      >('StopTask');
-  late final _StopTask = _StopTaskPtr.asFunction<void Function(int, int)>();
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('MilliSleep');
+  late final _MilliSleep =
+      _MilliSleepPtr.asFunction<void Function(int, int, int)>();
 
-  int MoveSmoothStart(int x, int y, int port) {
-    return _MoveSmoothStart(x, y, port);
+  int MoveSmoothStart(int x, int y, int reqID, int port) {
+    return _MoveSmoothStart(x, y, reqID, port);
   }
 
   late final _MoveSmoothStartPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.LongLong Function(ffi.Int, ffi.Int, ffi.LongLong)
+          ffi.LongLong Function(ffi.Int, ffi.Int, ffi.LongLong, ffi.LongLong)
         >
       >('MoveSmoothStart');
   late final _MoveSmoothStart =
-      _MoveSmoothStartPtr.asFunction<int Function(int, int, int)>();
+      _MoveSmoothStartPtr.asFunction<int Function(int, int, int, int)>();
 
-  int DragSmoothStart(int x, int y, int port) {
-    return _DragSmoothStart(x, y, port);
+  int DragSmoothStart(int x, int y, int reqID, int port) {
+    return _DragSmoothStart(x, y, reqID, port);
   }
 
   late final _DragSmoothStartPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.LongLong Function(ffi.Int, ffi.Int, ffi.LongLong)
+          ffi.LongLong Function(ffi.Int, ffi.Int, ffi.LongLong, ffi.LongLong)
         >
       >('DragSmoothStart');
   late final _DragSmoothStart =
-      _DragSmoothStartPtr.asFunction<int Function(int, int, int)>();
+      _DragSmoothStartPtr.asFunction<int Function(int, int, int, int)>();
 
-  int ScrollSmoothStart(int x, int y, int port) {
-    return _ScrollSmoothStart(x, y, port);
+  int ScrollSmoothStart(int x, int y, int reqID, int port) {
+    return _ScrollSmoothStart(x, y, reqID, port);
   }
 
   late final _ScrollSmoothStartPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.LongLong Function(ffi.Int, ffi.Int, ffi.LongLong)
+          ffi.LongLong Function(ffi.Int, ffi.Int, ffi.LongLong, ffi.LongLong)
         >
       >('ScrollSmoothStart');
   late final _ScrollSmoothStart =
-      _ScrollSmoothStartPtr.asFunction<int Function(int, int, int)>();
+      _ScrollSmoothStartPtr.asFunction<int Function(int, int, int, int)>();
 
-  void TypeStr(ffi.Pointer<ffi.Char> text, int port) {
-    return _TypeStr(text, port);
+  void TypeStr(ffi.Pointer<ffi.Char> text, int reqID, int port) {
+    return _TypeStr(text, reqID, port);
   }
 
   late final _TypeStrPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
         >
       >('TypeStr');
   late final _TypeStr =
-      _TypeStrPtr.asFunction<void Function(ffi.Pointer<ffi.Char>, int)>();
+      _TypeStrPtr.asFunction<void Function(ffi.Pointer<ffi.Char>, int, int)>();
 
   void TypeStrWithInts(
     ffi.Pointer<ffi.Char> text,
     int arg1,
     int arg2,
+    int reqID,
     int port,
   ) {
-    return _TypeStrWithInts(text, arg1, arg2, port);
+    return _TypeStrWithInts(text, arg1, arg2, reqID, port);
   }
 
   late final _TypeStrWithIntsPtr =
@@ -871,37 +1045,47 @@ class SentinelLibrary {
             ffi.Int,
             ffi.Int,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('TypeStrWithInts');
   late final _TypeStrWithInts =
       _TypeStrWithIntsPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+        void Function(ffi.Pointer<ffi.Char>, int, int, int, int)
       >();
 
-  void GoSleep(int seconds, int port) {
-    return _GoSleep(seconds, port);
+  void GoSleep(int seconds, int reqID, int port) {
+    return _GoSleep(seconds, reqID, port);
   }
 
   late final _GoSleepPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.LongLong)>>(
-        'GoSleep',
-      );
-  late final _GoSleep = _GoSleepPtr.asFunction<void Function(int, int)>();
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('GoSleep');
+  late final _GoSleep = _GoSleepPtr.asFunction<void Function(int, int, int)>();
 
-  void SetKeySleep(int ms, int port) {
-    return _SetKeySleep(ms, port);
+  void SetKeySleep(int ms, int reqID, int port) {
+    return _SetKeySleep(ms, reqID, port);
   }
 
   late final _SetKeySleepPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.LongLong)>>(
-        'SetKeySleep',
-      );
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetKeySleep');
   late final _SetKeySleep =
-      _SetKeySleepPtr.asFunction<void Function(int, int)>();
+      _SetKeySleepPtr.asFunction<void Function(int, int, int)>();
 
-  void KeyTap(ffi.Pointer<ffi.Char> key, ffi.Pointer<ffi.Char> mods, int port) {
-    return _KeyTap(key, mods, port);
+  void KeyTap(
+    ffi.Pointer<ffi.Char> key,
+    ffi.Pointer<ffi.Char> mods,
+    int reqID,
+    int port,
+  ) {
+    return _KeyTap(key, mods, reqID, port);
   }
 
   late final _KeyTapPtr =
@@ -911,20 +1095,22 @@ class SentinelLibrary {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('KeyTap');
   late final _KeyTap =
       _KeyTapPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int, int)
       >();
 
   void KeyTapArr(
     ffi.Pointer<ffi.Char> key,
     ffi.Pointer<ffi.Char> modsJson,
+    int reqID,
     int port,
   ) {
-    return _KeyTapArr(key, modsJson, port);
+    return _KeyTapArr(key, modsJson, reqID, port);
   }
 
   late final _KeyTapArrPtr =
@@ -934,20 +1120,22 @@ class SentinelLibrary {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('KeyTapArr');
   late final _KeyTapArr =
       _KeyTapArrPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int, int)
       >();
 
   void KeyToggle(
     ffi.Pointer<ffi.Char> key,
     ffi.Pointer<ffi.Char> direction,
+    int reqID,
     int port,
   ) {
-    return _KeyToggle(key, direction, port);
+    return _KeyToggle(key, direction, reqID, port);
   }
 
   late final _KeyTogglePtr =
@@ -957,69 +1145,80 @@ class SentinelLibrary {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('KeyToggle');
   late final _KeyToggle =
       _KeyTogglePtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int, int)
       >();
 
-  void WriteAll(ffi.Pointer<ffi.Char> text, int port) {
-    return _WriteAll(text, port);
+  void WriteAll(ffi.Pointer<ffi.Char> text, int reqID, int port) {
+    return _WriteAll(text, reqID, port);
   }
 
   late final _WriteAllPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
         >
       >('WriteAll');
   late final _WriteAll =
-      _WriteAllPtr.asFunction<void Function(ffi.Pointer<ffi.Char>, int)>();
+      _WriteAllPtr.asFunction<void Function(ffi.Pointer<ffi.Char>, int, int)>();
 
-  void ReadAll(int port) {
-    return _ReadAll(port);
+  void ReadAll(int reqID, int port) {
+    return _ReadAll(reqID, port);
   }
 
   late final _ReadAllPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.LongLong)>>('ReadAll');
-  late final _ReadAll = _ReadAllPtr.asFunction<void Function(int)>();
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ReadAll');
+  late final _ReadAll = _ReadAllPtr.asFunction<void Function(int, int)>();
 
-  int TypeStrStart(ffi.Pointer<ffi.Char> text, int port) {
-    return _TypeStrStart(text, port);
+  int TypeStrStart(ffi.Pointer<ffi.Char> text, int reqID, int port) {
+    return _TypeStrStart(text, reqID, port);
   }
 
   late final _TypeStrStartPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.LongLong Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.LongLong Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
         >
       >('TypeStrStart');
   late final _TypeStrStart =
-      _TypeStrStartPtr.asFunction<int Function(ffi.Pointer<ffi.Char>, int)>();
+      _TypeStrStartPtr.asFunction<
+        int Function(ffi.Pointer<ffi.Char>, int, int)
+      >();
 
-  void GetPixelColor(int x, int y, int port) {
-    return _GetPixelColor(x, y, port);
+  void GetPixelColor(int x, int y, int reqID, int port) {
+    return _GetPixelColor(x, y, reqID, port);
   }
 
   late final _GetPixelColorPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
       >('GetPixelColor');
   late final _GetPixelColor =
-      _GetPixelColorPtr.asFunction<void Function(int, int, int)>();
+      _GetPixelColorPtr.asFunction<void Function(int, int, int, int)>();
 
-  void GetScreenSize(int port) {
-    return _GetScreenSize(port);
+  void GetScreenSize(int reqID, int port) {
+    return _GetScreenSize(reqID, port);
   }
 
   late final _GetScreenSizePtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.LongLong)>>(
-        'GetScreenSize',
-      );
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('GetScreenSize');
   late final _GetScreenSize =
-      _GetScreenSizePtr.asFunction<void Function(int)>();
+      _GetScreenSizePtr.asFunction<void Function(int, int)>();
 
   void CaptureScreenSave(
     int x,
@@ -1027,9 +1226,10 @@ class SentinelLibrary {
     int w,
     int h,
     ffi.Pointer<ffi.Char> path,
+    int reqID,
     int port,
   ) {
-    return _CaptureScreenSave(x, y, w, h, path, port);
+    return _CaptureScreenSave(x, y, w, h, path, reqID, port);
   }
 
   late final _CaptureScreenSavePtr =
@@ -1042,63 +1242,106 @@ class SentinelLibrary {
             ffi.Int,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CaptureScreenSave');
   late final _CaptureScreenSave =
       _CaptureScreenSavePtr.asFunction<
-        void Function(int, int, int, int, ffi.Pointer<ffi.Char>, int)
+        void Function(int, int, int, int, ffi.Pointer<ffi.Char>, int, int)
       >();
 
-  void CaptureScreenBase64(int x, int y, int w, int h, int port) {
-    return _CaptureScreenBase64(x, y, w, h, port);
+  void CaptureScreenBase64(int x, int y, int w, int h, int reqID, int port) {
+    return _CaptureScreenBase64(x, y, w, h, reqID, port);
   }
 
   late final _CaptureScreenBase64Ptr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Int, ffi.Int, ffi.Int, ffi.Int, ffi.LongLong)
+          ffi.Void Function(
+            ffi.Int,
+            ffi.Int,
+            ffi.Int,
+            ffi.Int,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
         >
       >('CaptureScreenBase64');
   late final _CaptureScreenBase64 =
       _CaptureScreenBase64Ptr.asFunction<
-        void Function(int, int, int, int, int)
+        void Function(int, int, int, int, int, int)
       >();
 
-  void DisplaysNum(int port) {
-    return _DisplaysNum(port);
+  void CaptureScreenPNG(int x, int y, int w, int h, int reqID, int port) {
+    return _CaptureScreenPNG(x, y, w, h, reqID, port);
+  }
+
+  late final _CaptureScreenPNGPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Int,
+            ffi.Int,
+            ffi.Int,
+            ffi.Int,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('CaptureScreenPNG');
+  late final _CaptureScreenPNG =
+      _CaptureScreenPNGPtr.asFunction<
+        void Function(int, int, int, int, int, int)
+      >();
+
+  void DisplaysNum(int reqID, int port) {
+    return _DisplaysNum(reqID, port);
   }
 
   late final _DisplaysNumPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.LongLong)>>(
-        'DisplaysNum',
-      );
-  late final _DisplaysNum = _DisplaysNumPtr.asFunction<void Function(int)>();
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('DisplaysNum');
+  late final _DisplaysNum =
+      _DisplaysNumPtr.asFunction<void Function(int, int)>();
 
-  void GetDisplayBounds(int index, int port) {
-    return _GetDisplayBounds(index, port);
+  void GetDisplayBounds(int index, int reqID, int port) {
+    return _GetDisplayBounds(index, reqID, port);
   }
 
   late final _GetDisplayBoundsPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Int, ffi.LongLong)>>(
-        'GetDisplayBounds',
-      );
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetDisplayBounds');
   late final _GetDisplayBounds =
-      _GetDisplayBoundsPtr.asFunction<void Function(int, int)>();
+      _GetDisplayBoundsPtr.asFunction<void Function(int, int, int)>();
 
-  void CaptureDisplaySave(int index, ffi.Pointer<ffi.Char> path, int port) {
-    return _CaptureDisplaySave(index, path, port);
+  void CaptureDisplaySave(
+    int index,
+    ffi.Pointer<ffi.Char> path,
+    int reqID,
+    int port,
+  ) {
+    return _CaptureDisplaySave(index, path, reqID, port);
   }
 
   late final _CaptureDisplaySavePtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Int, ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(
+            ffi.Int,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
         >
       >('CaptureDisplaySave');
   late final _CaptureDisplaySave =
       _CaptureDisplaySavePtr.asFunction<
-        void Function(int, ffi.Pointer<ffi.Char>, int)
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
       >();
 
   void CaptureDisplayRegionSave(
@@ -1108,9 +1351,10 @@ class SentinelLibrary {
     int w,
     int h,
     ffi.Pointer<ffi.Char> path,
+    int reqID,
     int port,
   ) {
-    return _CaptureDisplayRegionSave(index, x, y, w, h, path, port);
+    return _CaptureDisplayRegionSave(index, x, y, w, h, path, reqID, port);
   }
 
   late final _CaptureDisplayRegionSavePtr =
@@ -1124,116 +1368,57 @@ class SentinelLibrary {
             ffi.Int,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CaptureDisplayRegionSave');
   late final _CaptureDisplayRegionSave =
       _CaptureDisplayRegionSavePtr.asFunction<
-        void Function(int, int, int, int, int, ffi.Pointer<ffi.Char>, int)
+        void Function(int, int, int, int, int, ffi.Pointer<ffi.Char>, int, int)
       >();
 
-  void SaveImageJpeg(ffi.Pointer<ffi.Char> path, int quality, int port) {
-    return _SaveImageJpeg(path, quality, port);
+  void SaveImageJpeg(
+    ffi.Pointer<ffi.Char> path,
+    int quality,
+    int reqID,
+    int port,
+  ) {
+    return _SaveImageJpeg(path, quality, reqID, port);
   }
 
   late final _SaveImageJpegPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.Int, ffi.LongLong)
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.Int,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
         >
       >('SaveImageJpeg');
   late final _SaveImageJpeg =
       _SaveImageJpegPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int, int)
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
       >();
 
-  void SaveImagePNGFromCaptureImg(ffi.Pointer<ffi.Char> path, int port) {
-    return _SaveImagePNGFromCaptureImg(path, port);
+  void SaveImagePNGFromCaptureImg(
+    ffi.Pointer<ffi.Char> path,
+    int reqID,
+    int port,
+  ) {
+    return _SaveImagePNGFromCaptureImg(path, reqID, port);
   }
 
   late final _SaveImagePNGFromCaptureImgPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
         >
       >('SaveImagePNGFromCaptureImg');
   late final _SaveImagePNGFromCaptureImg =
       _SaveImagePNGFromCaptureImgPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int)
-      >();
-
-  void CaptureScreenBitmapSave(
-    int x,
-    int y,
-    int w,
-    int h,
-    ffi.Pointer<ffi.Char> path,
-    int port,
-  ) {
-    return _CaptureScreenBitmapSave(x, y, w, h, path, port);
-  }
-
-  late final _CaptureScreenBitmapSavePtr =
-      _lookup<
-        ffi.NativeFunction<
-          ffi.Void Function(
-            ffi.Int,
-            ffi.Int,
-            ffi.Int,
-            ffi.Int,
-            ffi.Pointer<ffi.Char>,
-            ffi.LongLong,
-          )
-        >
-      >('CaptureScreenBitmapSave');
-  late final _CaptureScreenBitmapSave =
-      _CaptureScreenBitmapSavePtr.asFunction<
-        void Function(int, int, int, int, ffi.Pointer<ffi.Char>, int)
-      >();
-
-  void FindBitmapFromCapture(int x, int y, int w, int h, int port) {
-    return _FindBitmapFromCapture(x, y, w, h, port);
-  }
-
-  late final _FindBitmapFromCapturePtr =
-      _lookup<
-        ffi.NativeFunction<
-          ffi.Void Function(ffi.Int, ffi.Int, ffi.Int, ffi.Int, ffi.LongLong)
-        >
-      >('FindBitmapFromCapture');
-  late final _FindBitmapFromCapture =
-      _FindBitmapFromCapturePtr.asFunction<
-        void Function(int, int, int, int, int)
-      >();
-
-  void FindAllBitmapFromCapture(int x, int y, int w, int h, int port) {
-    return _FindAllBitmapFromCapture(x, y, w, h, port);
-  }
-
-  late final _FindAllBitmapFromCapturePtr =
-      _lookup<
-        ffi.NativeFunction<
-          ffi.Void Function(ffi.Int, ffi.Int, ffi.Int, ffi.Int, ffi.LongLong)
-        >
-      >('FindAllBitmapFromCapture');
-  late final _FindAllBitmapFromCapture =
-      _FindAllBitmapFromCapturePtr.asFunction<
-        void Function(int, int, int, int, int)
-      >();
-
-  void MoveToFoundBitmap(int x, int y, int w, int h, int port) {
-    return _MoveToFoundBitmap(x, y, w, h, port);
-  }
-
-  late final _MoveToFoundBitmapPtr =
-      _lookup<
-        ffi.NativeFunction<
-          ffi.Void Function(ffi.Int, ffi.Int, ffi.Int, ffi.Int, ffi.LongLong)
-        >
-      >('MoveToFoundBitmap');
-  late final _MoveToFoundBitmap =
-      _MoveToFoundBitmapPtr.asFunction<
-        void Function(int, int, int, int, int)
+        void Function(ffi.Pointer<ffi.Char>, int, int)
       >();
 
   void SaveBitmapToFile(
@@ -1242,9 +1427,10 @@ class SentinelLibrary {
     int w,
     int h,
     ffi.Pointer<ffi.Char> path,
+    int reqID,
     int port,
   ) {
-    return _SaveBitmapToFile(x, y, w, h, path, port);
+    return _SaveBitmapToFile(x, y, w, h, path, reqID, port);
   }
 
   late final _SaveBitmapToFilePtr =
@@ -1257,12 +1443,13 @@ class SentinelLibrary {
             ffi.Int,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('SaveBitmapToFile');
   late final _SaveBitmapToFile =
       _SaveBitmapToFilePtr.asFunction<
-        void Function(int, int, int, int, ffi.Pointer<ffi.Char>, int)
+        void Function(int, int, int, int, ffi.Pointer<ffi.Char>, int, int)
       >();
 
   void SaveCaptureRegion(
@@ -1271,9 +1458,10 @@ class SentinelLibrary {
     int y,
     int w,
     int h,
+    int reqID,
     int port,
   ) {
-    return _SaveCaptureRegion(path, x, y, w, h, port);
+    return _SaveCaptureRegion(path, x, y, w, h, reqID, port);
   }
 
   late final _SaveCaptureRegionPtr =
@@ -1286,80 +1474,37 @@ class SentinelLibrary {
             ffi.Int,
             ffi.Int,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('SaveCaptureRegion');
   late final _SaveCaptureRegion =
       _SaveCaptureRegionPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int, int, int, int, int)
+        void Function(ffi.Pointer<ffi.Char>, int, int, int, int, int, int)
       >();
 
-  void SaveCaptureFull(ffi.Pointer<ffi.Char> path, int port) {
-    return _SaveCaptureFull(path, port);
+  void SaveCaptureFull(ffi.Pointer<ffi.Char> path, int reqID, int port) {
+    return _SaveCaptureFull(path, reqID, port);
   }
 
   late final _SaveCaptureFullPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
         >
       >('SaveCaptureFull');
   late final _SaveCaptureFull =
       _SaveCaptureFullPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int)
-      >();
-
-  void BitmapOpenFind(ffi.Pointer<ffi.Char> path, int port) {
-    return _BitmapOpenFind(path, port);
-  }
-
-  late final _BitmapOpenFindPtr =
-      _lookup<
-        ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
-        >
-      >('BitmapOpenFind');
-  late final _BitmapOpenFind =
-      _BitmapOpenFindPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int)
-      >();
-
-  void BitmapFindAllFromFile(ffi.Pointer<ffi.Char> path, int port) {
-    return _BitmapFindAllFromFile(path, port);
-  }
-
-  late final _BitmapFindAllFromFilePtr =
-      _lookup<
-        ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
-        >
-      >('BitmapFindAllFromFile');
-  late final _BitmapFindAllFromFile =
-      _BitmapFindAllFromFilePtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int)
-      >();
-
-  void MoveToFoundFromFile(ffi.Pointer<ffi.Char> path, int port) {
-    return _MoveToFoundFromFile(path, port);
-  }
-
-  late final _MoveToFoundFromFilePtr =
-      _lookup<
-        ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
-        >
-      >('MoveToFoundFromFile');
-  late final _MoveToFoundFromFile =
-      _MoveToFoundFromFilePtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, int, int)
       >();
 
   void HookRegisterCombo(
     ffi.Pointer<ffi.Char> mods,
     ffi.Pointer<ffi.Char> key,
+    int reqID,
     int port,
   ) {
-    return _HookRegisterCombo(mods, key, port);
+    return _HookRegisterCombo(mods, key, reqID, port);
   }
 
   late final _HookRegisterComboPtr =
@@ -1369,21 +1514,24 @@ class SentinelLibrary {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('HookRegisterCombo');
   late final _HookRegisterCombo =
       _HookRegisterComboPtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int, int)
       >();
 
-  void HookStart(int port) {
-    return _HookStart(port);
+  void HookStart(int reqID, int port) {
+    return _HookStart(reqID, port);
   }
 
   late final _HookStartPtr =
-      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.LongLong)>>('HookStart');
-  late final _HookStart = _HookStartPtr.asFunction<void Function(int)>();
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('HookStart');
+  late final _HookStart = _HookStartPtr.asFunction<void Function(int, int)>();
 
   void HookStop() {
     return _HookStop();
@@ -1394,50 +1542,58 @@ class SentinelLibrary {
   );
   late final _HookStop = _HookStopPtr.asFunction<void Function()>();
 
-  void HookAddEvent(ffi.Pointer<ffi.Char> name, int port) {
-    return _HookAddEvent(name, port);
+  void HookAddEvent(ffi.Pointer<ffi.Char> name, int reqID, int port) {
+    return _HookAddEvent(name, reqID, port);
   }
 
   late final _HookAddEventPtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
         >
       >('HookAddEvent');
   late final _HookAddEvent =
-      _HookAddEventPtr.asFunction<void Function(ffi.Pointer<ffi.Char>, int)>();
+      _HookAddEventPtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int)
+      >();
 
-  void DecodeAndReportImageSize(ffi.Pointer<ffi.Char> path, int port) {
-    return _DecodeAndReportImageSize(path, port);
+  void DecodeAndReportImageSize(
+    ffi.Pointer<ffi.Char> path,
+    int reqID,
+    int port,
+  ) {
+    return _DecodeAndReportImageSize(path, reqID, port);
   }
 
   late final _DecodeAndReportImageSizePtr =
       _lookup<
         ffi.NativeFunction<
-          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
         >
       >('DecodeAndReportImageSize');
   late final _DecodeAndReportImageSize =
       _DecodeAndReportImageSizePtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, int, int)
       >();
 
-  int StartMonitor(int port) {
-    return _StartMonitor(port);
+  int StartMonitor(int reqID, int port) {
+    return _StartMonitor(reqID, port);
   }
 
   late final _StartMonitorPtr =
-      _lookup<ffi.NativeFunction<ffi.LongLong Function(ffi.LongLong)>>(
-        'StartMonitor',
-      );
-  late final _StartMonitor = _StartMonitorPtr.asFunction<int Function(int)>();
+      _lookup<
+        ffi.NativeFunction<ffi.LongLong Function(ffi.LongLong, ffi.LongLong)>
+      >('StartMonitor');
+  late final _StartMonitor =
+      _StartMonitorPtr.asFunction<int Function(int, int)>();
 
   void ControlService(
     ffi.Pointer<ffi.Char> name,
     ffi.Pointer<ffi.Char> action,
+    int reqID,
     int port,
   ) {
-    return _ControlService(name, action, port);
+    return _ControlService(name, action, reqID, port);
   }
 
   late final _ControlServicePtr =
@@ -1447,13 +1603,73 @@ class SentinelLibrary {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('ControlService');
   late final _ControlService =
       _ControlServicePtr.asFunction<
-        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        void Function(ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int, int)
       >();
+
+  void StopTask(int taskID, int reqID, int port) {
+    return _StopTask(taskID, reqID, port);
+  }
+
+  late final _StopTaskPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('StopTask');
+  late final _StopTask =
+      _StopTaskPtr.asFunction<void Function(int, int, int)>();
+
+  void ListTasks(int reqID, int port) {
+    return _ListTasks(reqID, port);
+  }
+
+  late final _ListTasksPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListTasks');
+  late final _ListTasks = _ListTasksPtr.asFunction<void Function(int, int)>();
+
+  void GetTask(int taskID, int reqID, int port) {
+    return _GetTask(taskID, reqID, port);
+  }
+
+  late final _GetTaskPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetTask');
+  late final _GetTask = _GetTaskPtr.asFunction<void Function(int, int, int)>();
+
+  int Invoke(ffi.Pointer<ffi.Char> requestJSON, int port) {
+    return _Invoke(requestJSON, port);
+  }
+
+  late final _InvokePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+        >
+      >('Invoke');
+  late final _Invoke =
+      _InvokePtr.asFunction<int Function(ffi.Pointer<ffi.Char>, int)>();
+
+  void ListOperations(int reqID, int port) {
+    return _ListOperations(reqID, port);
+  }
+
+  late final _ListOperationsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListOperations');
+  late final _ListOperations =
+      _ListOperationsPtr.asFunction<void Function(int, int)>();
 }
 
 typedef va_list = ffi.Pointer<ffi.Char>;
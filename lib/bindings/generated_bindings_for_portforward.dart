@@ -70,13 +70,166 @@ class PortForwardLibrary {
   late final _BridgeInit =
       _BridgeInitPtr.asFunction<void Function(ffi.Pointer<ffi.Void>)>();
 
+  void ConfigureQueue(
+    int targetPort,
+    int capacity,
+    ffi.Pointer<ffi.Char> policy,
+    int reqID,
+    int port,
+  ) {
+    return _ConfigureQueue(targetPort, capacity, policy, reqID, port);
+  }
+
+  late final _ConfigureQueuePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Int,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ConfigureQueue');
+  late final _ConfigureQueue =
+      _ConfigureQueuePtr.asFunction<
+        void Function(int, int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetQueueStats(int reqID, int port) {
+    return _GetQueueStats(reqID, port);
+  }
+
+  late final _GetQueueStatsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('GetQueueStats');
+  late final _GetQueueStats =
+      _GetQueueStatsPtr.asFunction<void Function(int, int)>();
+
+  void SetLogPort(
+    int logPort,
+    ffi.Pointer<ffi.Char> level,
+    int reqID,
+    int port,
+  ) {
+    return _SetLogPort(logPort, level, reqID, port);
+  }
+
+  late final _SetLogPortPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetLogPort');
+  late final _SetLogPort =
+      _SetLogPortPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void SetLogLevel(ffi.Pointer<ffi.Char> level, int reqID, int port) {
+    return _SetLogLevel(level, reqID, port);
+  }
+
+  late final _SetLogLevelPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetLogLevel');
+  late final _SetLogLevel =
+      _SetLogLevelPtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void Subscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Subscribe(topic, subPort, reqID, port);
+  }
+
+  late final _SubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Subscribe');
+  late final _Subscribe =
+      _SubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void Unsubscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Unsubscribe(topic, subPort, reqID, port);
+  }
+
+  late final _UnsubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Unsubscribe');
+  late final _Unsubscribe =
+      _UnsubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void ListSubscriptions(int reqID, int port) {
+    return _ListSubscriptions(reqID, port);
+  }
+
+  late final _ListSubscriptionsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListSubscriptions');
+  late final _ListSubscriptions =
+      _ListSubscriptionsPtr.asFunction<void Function(int, int)>();
+
+  void Shutdown(int timeoutMs, int reqID, int port) {
+    return _Shutdown(timeoutMs, reqID, port);
+  }
+
+  late final _ShutdownPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('Shutdown');
+  late final _Shutdown =
+      _ShutdownPtr.asFunction<void Function(int, int, int)>();
+
   int CreatePortForwarder(
     ffi.Pointer<ffi.Char> urlStr,
     ffi.Pointer<ffi.Char> portsStr,
     ffi.Pointer<ffi.Char> addressStr,
+    int reqID,
     int port,
   ) {
-    return _CreatePortForwarder(urlStr, portsStr, addressStr, port);
+    return _CreatePortForwarder(urlStr, portsStr, addressStr, reqID, port);
   }
 
   late final _CreatePortForwarderPtr =
@@ -87,6 +240,7 @@ class PortForwardLibrary {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreatePortForwarder');
@@ -97,51 +251,129 @@ class PortForwardLibrary {
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
           int,
+          int,
         )
       >();
 
-  int StartForwardPorts(int pfID, int port) {
-    return _StartForwardPorts(pfID, port);
+  int StartForwardPorts(int pfID, int reqID, int port) {
+    return _StartForwardPorts(pfID, reqID, port);
   }
 
   late final _StartForwardPortsPtr =
       _lookup<
-        ffi.NativeFunction<ffi.LongLong Function(ffi.LongLong, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
       >('StartForwardPorts');
   late final _StartForwardPorts =
-      _StartForwardPortsPtr.asFunction<int Function(int, int)>();
+      _StartForwardPortsPtr.asFunction<int Function(int, int, int)>();
 
-  void StopForwardPorts(int pfID, int port) {
-    return _StopForwardPorts(pfID, port);
+  void StopForwardPorts(int pfID, int reqID, int port) {
+    return _StopForwardPorts(pfID, reqID, port);
   }
 
   late final _StopForwardPortsPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
       >('StopForwardPorts');
   late final _StopForwardPorts =
-      _StopForwardPortsPtr.asFunction<void Function(int, int)>();
+      _StopForwardPortsPtr.asFunction<void Function(int, int, int)>();
 
-  void GetForwardedPorts(int pfID, int port) {
-    return _GetForwardedPorts(pfID, port);
+  void GetForwardedPorts(int pfID, int reqID, int port) {
+    return _GetForwardedPorts(pfID, reqID, port);
   }
 
   late final _GetForwardedPortsPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
       >('GetForwardedPorts');
   late final _GetForwardedPorts =
-      _GetForwardedPortsPtr.asFunction<void Function(int, int)>();
+      _GetForwardedPortsPtr.asFunction<void Function(int, int, int)>();
+
+  ffi.Pointer<ffi.Char> GetLastError(int reqID) {
+    return _GetLastError(reqID);
+  }
+
+  late final _GetLastErrorPtr =
+      _lookup<ffi.NativeFunction<ffi.Pointer<ffi.Char> Function(ffi.LongLong)>>(
+        'GetLastError',
+      );
+  late final _GetLastError =
+      _GetLastErrorPtr.asFunction<ffi.Pointer<ffi.Char> Function(int)>();
+
+  void FreeString(ffi.Pointer<ffi.Char> s) {
+    return _FreeString(s);
+  }
+
+  late final _FreeStringPtr =
+      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Pointer<ffi.Char>)>>(
+        'FreeString',
+      );
+  late final _FreeString =
+      _FreeStringPtr.asFunction<void Function(ffi.Pointer<ffi.Char>)>();
 
-  void StopTask(int taskID, int port) {
-    return _StopTask(taskID, port);
+  void StopTask(int taskID, int reqID, int port) {
+    return _StopTask(taskID, reqID, port);
   }
 
   late final _StopTaskPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
       >('StopTask');
-  late final _StopTask = _StopTaskPtr.asFunction<void Function(int, int)>();
+  late final _StopTask =
+      _StopTaskPtr.asFunction<void Function(int, int, int)>();
+
+  void ListTasks(int reqID, int port) {
+    return _ListTasks(reqID, port);
+  }
+
+  late final _ListTasksPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListTasks');
+  late final _ListTasks = _ListTasksPtr.asFunction<void Function(int, int)>();
+
+  void GetTask(int taskID, int reqID, int port) {
+    return _GetTask(taskID, reqID, port);
+  }
+
+  late final _GetTaskPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetTask');
+  late final _GetTask = _GetTaskPtr.asFunction<void Function(int, int, int)>();
+
+  int Invoke(ffi.Pointer<ffi.Char> requestJSON, int port) {
+    return _Invoke(requestJSON, port);
+  }
+
+  late final _InvokePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+        >
+      >('Invoke');
+  late final _Invoke =
+      _InvokePtr.asFunction<int Function(ffi.Pointer<ffi.Char>, int)>();
+
+  void ListOperations(int reqID, int port) {
+    return _ListOperations(reqID, port);
+  }
+
+  late final _ListOperationsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListOperations');
+  late final _ListOperations =
+      _ListOperationsPtr.asFunction<void Function(int, int)>();
 }
 
 typedef ptrdiff_t = ffi.LongLong;
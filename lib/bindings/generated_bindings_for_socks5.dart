@@ -70,13 +70,166 @@ class Socks5Library {
   late final _BridgeInit =
       _BridgeInitPtr.asFunction<void Function(ffi.Pointer<ffi.Void>)>();
 
+  void ConfigureQueue(
+    int targetPort,
+    int capacity,
+    ffi.Pointer<ffi.Char> policy,
+    int reqID,
+    int port,
+  ) {
+    return _ConfigureQueue(targetPort, capacity, policy, reqID, port);
+  }
+
+  late final _ConfigureQueuePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Int,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ConfigureQueue');
+  late final _ConfigureQueue =
+      _ConfigureQueuePtr.asFunction<
+        void Function(int, int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetQueueStats(int reqID, int port) {
+    return _GetQueueStats(reqID, port);
+  }
+
+  late final _GetQueueStatsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('GetQueueStats');
+  late final _GetQueueStats =
+      _GetQueueStatsPtr.asFunction<void Function(int, int)>();
+
+  void SetLogPort(
+    int logPort,
+    ffi.Pointer<ffi.Char> level,
+    int reqID,
+    int port,
+  ) {
+    return _SetLogPort(logPort, level, reqID, port);
+  }
+
+  late final _SetLogPortPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetLogPort');
+  late final _SetLogPort =
+      _SetLogPortPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void SetLogLevel(ffi.Pointer<ffi.Char> level, int reqID, int port) {
+    return _SetLogLevel(level, reqID, port);
+  }
+
+  late final _SetLogLevelPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetLogLevel');
+  late final _SetLogLevel =
+      _SetLogLevelPtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void Subscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Subscribe(topic, subPort, reqID, port);
+  }
+
+  late final _SubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Subscribe');
+  late final _Subscribe =
+      _SubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void Unsubscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Unsubscribe(topic, subPort, reqID, port);
+  }
+
+  late final _UnsubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Unsubscribe');
+  late final _Unsubscribe =
+      _UnsubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void ListSubscriptions(int reqID, int port) {
+    return _ListSubscriptions(reqID, port);
+  }
+
+  late final _ListSubscriptionsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListSubscriptions');
+  late final _ListSubscriptions =
+      _ListSubscriptionsPtr.asFunction<void Function(int, int)>();
+
+  void Shutdown(int timeoutMs, int reqID, int port) {
+    return _Shutdown(timeoutMs, reqID, port);
+  }
+
+  late final _ShutdownPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('Shutdown');
+  late final _Shutdown =
+      _ShutdownPtr.asFunction<void Function(int, int, int)>();
+
   int CreateDirectServerTCP(
     int listenPort,
     ffi.Pointer<ffi.Char> username,
     ffi.Pointer<ffi.Char> password,
+    int reqID,
     int port,
   ) {
-    return _CreateDirectServerTCP(listenPort, username, password, port);
+    return _CreateDirectServerTCP(listenPort, username, password, reqID, port);
   }
 
   late final _CreateDirectServerTCPPtr =
@@ -87,21 +240,29 @@ class Socks5Library {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreateDirectServerTCP');
   late final _CreateDirectServerTCP =
       _CreateDirectServerTCPPtr.asFunction<
-        int Function(int, ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        int Function(
+          int,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
       >();
 
   int CreateDirectServerUDP(
     int listenPort,
     ffi.Pointer<ffi.Char> username,
     ffi.Pointer<ffi.Char> password,
+    int reqID,
     int port,
   ) {
-    return _CreateDirectServerUDP(listenPort, username, password, port);
+    return _CreateDirectServerUDP(listenPort, username, password, reqID, port);
   }
 
   late final _CreateDirectServerUDPPtr =
@@ -112,12 +273,19 @@ class Socks5Library {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreateDirectServerUDP');
   late final _CreateDirectServerUDP =
       _CreateDirectServerUDPPtr.asFunction<
-        int Function(int, ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        int Function(
+          int,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
       >();
 
   int CreateProxyToSocks5ServerUDP(
@@ -127,6 +295,7 @@ class Socks5Library {
     ffi.Pointer<ffi.Char> proxyAddr,
     ffi.Pointer<ffi.Char> proxyUser,
     ffi.Pointer<ffi.Char> proxyPass,
+    int reqID,
     int port,
   ) {
     return _CreateProxyToSocks5ServerUDP(
@@ -136,6 +305,7 @@ class Socks5Library {
       proxyAddr,
       proxyUser,
       proxyPass,
+      reqID,
       port,
     );
   }
@@ -151,6 +321,7 @@ class Socks5Library {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreateProxyToSocks5ServerUDP');
@@ -164,62 +335,174 @@ class Socks5Library {
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
           int,
+          int,
         )
       >();
 
-  int CreateWithAuthServer(int listenPort, int port) {
-    return _CreateWithAuthServer(listenPort, port);
+  int CreateWithAuthServer(int listenPort, int reqID, int port) {
+    return _CreateWithAuthServer(listenPort, reqID, port);
   }
 
   late final _CreateWithAuthServerPtr =
-      _lookup<ffi.NativeFunction<ffi.LongLong Function(ffi.Int, ffi.LongLong)>>(
-        'CreateWithAuthServer',
-      );
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('CreateWithAuthServer');
   late final _CreateWithAuthServer =
-      _CreateWithAuthServerPtr.asFunction<int Function(int, int)>();
+      _CreateWithAuthServerPtr.asFunction<int Function(int, int, int)>();
 
-  int CreateWithoutAuthServer(int listenPort, int port) {
-    return _CreateWithoutAuthServer(listenPort, port);
+  int CreateWithoutAuthServer(int listenPort, int reqID, int port) {
+    return _CreateWithoutAuthServer(listenPort, reqID, port);
   }
 
   late final _CreateWithoutAuthServerPtr =
-      _lookup<ffi.NativeFunction<ffi.LongLong Function(ffi.Int, ffi.LongLong)>>(
-        'CreateWithoutAuthServer',
-      );
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('CreateWithoutAuthServer');
   late final _CreateWithoutAuthServer =
-      _CreateWithoutAuthServerPtr.asFunction<int Function(int, int)>();
+      _CreateWithoutAuthServerPtr.asFunction<int Function(int, int, int)>();
 
-  int StartSocks5Server(int srvID, int port) {
-    return _StartSocks5Server(srvID, port);
+  int StartSocks5Server(int srvID, int reqID, int port) {
+    return _StartSocks5Server(srvID, reqID, port);
   }
 
   late final _StartSocks5ServerPtr =
       _lookup<
-        ffi.NativeFunction<ffi.LongLong Function(ffi.LongLong, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
       >('StartSocks5Server');
   late final _StartSocks5Server =
-      _StartSocks5ServerPtr.asFunction<int Function(int, int)>();
+      _StartSocks5ServerPtr.asFunction<int Function(int, int, int)>();
 
-  void StopSocks5Server(int srvID, int port) {
-    return _StopSocks5Server(srvID, port);
+  void StopSocks5Server(int srvID, int reqID, int port) {
+    return _StopSocks5Server(srvID, reqID, port);
   }
 
   late final _StopSocks5ServerPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
       >('StopSocks5Server');
   late final _StopSocks5Server =
-      _StopSocks5ServerPtr.asFunction<void Function(int, int)>();
+      _StopSocks5ServerPtr.asFunction<void Function(int, int, int)>();
+
+  void SetACL(int srvID, ffi.Pointer<ffi.Char> aclJSON, int reqID, int port) {
+    return _SetACL(srvID, aclJSON, reqID, port);
+  }
+
+  late final _SetACLPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetACL');
+  late final _SetACL =
+      _SetACLPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetACL(int srvID, int reqID, int port) {
+    return _GetACL(srvID, reqID, port);
+  }
+
+  late final _GetACLPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetACL');
+  late final _GetACL = _GetACLPtr.asFunction<void Function(int, int, int)>();
+
+  ffi.Pointer<ffi.Char> GetLastError(int reqID) {
+    return _GetLastError(reqID);
+  }
+
+  late final _GetLastErrorPtr =
+      _lookup<ffi.NativeFunction<ffi.Pointer<ffi.Char> Function(ffi.LongLong)>>(
+        'GetLastError',
+      );
+  late final _GetLastError =
+      _GetLastErrorPtr.asFunction<ffi.Pointer<ffi.Char> Function(int)>();
+
+  void FreeString(ffi.Pointer<ffi.Char> s) {
+    return _FreeString(s);
+  }
+
+  late final _FreeStringPtr =
+      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Pointer<ffi.Char>)>>(
+        'FreeString',
+      );
+  late final _FreeString =
+      _FreeStringPtr.asFunction<void Function(ffi.Pointer<ffi.Char>)>();
 
-  void StopTask(int taskID, int port) {
-    return _StopTask(taskID, port);
+  void StopTask(int taskID, int reqID, int port) {
+    return _StopTask(taskID, reqID, port);
   }
 
   late final _StopTaskPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
       >('StopTask');
-  late final _StopTask = _StopTaskPtr.asFunction<void Function(int, int)>();
+  late final _StopTask =
+      _StopTaskPtr.asFunction<void Function(int, int, int)>();
+
+  void ListTasks(int reqID, int port) {
+    return _ListTasks(reqID, port);
+  }
+
+  late final _ListTasksPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListTasks');
+  late final _ListTasks = _ListTasksPtr.asFunction<void Function(int, int)>();
+
+  void GetTask(int taskID, int reqID, int port) {
+    return _GetTask(taskID, reqID, port);
+  }
+
+  late final _GetTaskPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetTask');
+  late final _GetTask = _GetTaskPtr.asFunction<void Function(int, int, int)>();
+
+  int Invoke(ffi.Pointer<ffi.Char> requestJSON, int port) {
+    return _Invoke(requestJSON, port);
+  }
+
+  late final _InvokePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+        >
+      >('Invoke');
+  late final _Invoke =
+      _InvokePtr.asFunction<int Function(ffi.Pointer<ffi.Char>, int)>();
+
+  void ListOperations(int reqID, int port) {
+    return _ListOperations(reqID, port);
+  }
+
+  late final _ListOperationsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListOperations');
+  late final _ListOperations =
+      _ListOperationsPtr.asFunction<void Function(int, int)>();
 }
 
 typedef ptrdiff_t = ffi.LongLong;
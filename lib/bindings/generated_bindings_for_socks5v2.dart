@@ -70,13 +70,166 @@ class Socks5V2Library {
   late final _BridgeInit =
       _BridgeInitPtr.asFunction<void Function(ffi.Pointer<ffi.Void>)>();
 
+  void ConfigureQueue(
+    int targetPort,
+    int capacity,
+    ffi.Pointer<ffi.Char> policy,
+    int reqID,
+    int port,
+  ) {
+    return _ConfigureQueue(targetPort, capacity, policy, reqID, port);
+  }
+
+  late final _ConfigureQueuePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Int,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ConfigureQueue');
+  late final _ConfigureQueue =
+      _ConfigureQueuePtr.asFunction<
+        void Function(int, int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetQueueStats(int reqID, int port) {
+    return _GetQueueStats(reqID, port);
+  }
+
+  late final _GetQueueStatsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('GetQueueStats');
+  late final _GetQueueStats =
+      _GetQueueStatsPtr.asFunction<void Function(int, int)>();
+
+  void SetLogPort(
+    int logPort,
+    ffi.Pointer<ffi.Char> level,
+    int reqID,
+    int port,
+  ) {
+    return _SetLogPort(logPort, level, reqID, port);
+  }
+
+  late final _SetLogPortPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetLogPort');
+  late final _SetLogPort =
+      _SetLogPortPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void SetLogLevel(ffi.Pointer<ffi.Char> level, int reqID, int port) {
+    return _SetLogLevel(level, reqID, port);
+  }
+
+  late final _SetLogLevelPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Pointer<ffi.Char>, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetLogLevel');
+  late final _SetLogLevel =
+      _SetLogLevelPtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void Subscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Subscribe(topic, subPort, reqID, port);
+  }
+
+  late final _SubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Subscribe');
+  late final _Subscribe =
+      _SubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void Unsubscribe(
+    ffi.Pointer<ffi.Char> topic,
+    int subPort,
+    int reqID,
+    int port,
+  ) {
+    return _Unsubscribe(topic, subPort, reqID, port);
+  }
+
+  late final _UnsubscribePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('Unsubscribe');
+  late final _Unsubscribe =
+      _UnsubscribePtr.asFunction<
+        void Function(ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void ListSubscriptions(int reqID, int port) {
+    return _ListSubscriptions(reqID, port);
+  }
+
+  late final _ListSubscriptionsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListSubscriptions');
+  late final _ListSubscriptions =
+      _ListSubscriptionsPtr.asFunction<void Function(int, int)>();
+
+  void Shutdown(int timeoutMs, int reqID, int port) {
+    return _Shutdown(timeoutMs, reqID, port);
+  }
+
+  late final _ShutdownPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('Shutdown');
+  late final _Shutdown =
+      _ShutdownPtr.asFunction<void Function(int, int, int)>();
+
   int CreateDirectServerTCP(
     int listenPort,
     ffi.Pointer<ffi.Char> username,
     ffi.Pointer<ffi.Char> password,
+    int reqID,
     int port,
   ) {
-    return _CreateDirectServerTCP(listenPort, username, password, port);
+    return _CreateDirectServerTCP(listenPort, username, password, reqID, port);
   }
 
   late final _CreateDirectServerTCPPtr =
@@ -87,21 +240,29 @@ class Socks5V2Library {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreateDirectServerTCP');
   late final _CreateDirectServerTCP =
       _CreateDirectServerTCPPtr.asFunction<
-        int Function(int, ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        int Function(
+          int,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
       >();
 
   int CreateDirectServerUDP(
     int listenPort,
     ffi.Pointer<ffi.Char> username,
     ffi.Pointer<ffi.Char> password,
+    int reqID,
     int port,
   ) {
-    return _CreateDirectServerUDP(listenPort, username, password, port);
+    return _CreateDirectServerUDP(listenPort, username, password, reqID, port);
   }
 
   late final _CreateDirectServerUDPPtr =
@@ -112,12 +273,19 @@ class Socks5V2Library {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreateDirectServerUDP');
   late final _CreateDirectServerUDP =
       _CreateDirectServerUDPPtr.asFunction<
-        int Function(int, ffi.Pointer<ffi.Char>, ffi.Pointer<ffi.Char>, int)
+        int Function(
+          int,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
       >();
 
   int CreateProxyToSocks5ServerTCP(
@@ -127,6 +295,7 @@ class Socks5V2Library {
     ffi.Pointer<ffi.Char> proxyAddr,
     ffi.Pointer<ffi.Char> proxyUser,
     ffi.Pointer<ffi.Char> proxyPass,
+    int reqID,
     int port,
   ) {
     return _CreateProxyToSocks5ServerTCP(
@@ -136,6 +305,7 @@ class Socks5V2Library {
       proxyAddr,
       proxyUser,
       proxyPass,
+      reqID,
       port,
     );
   }
@@ -151,6 +321,7 @@ class Socks5V2Library {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreateProxyToSocks5ServerTCP');
@@ -164,6 +335,7 @@ class Socks5V2Library {
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
           int,
+          int,
         )
       >();
 
@@ -174,6 +346,7 @@ class Socks5V2Library {
     ffi.Pointer<ffi.Char> proxyAddr,
     ffi.Pointer<ffi.Char> proxyUser,
     ffi.Pointer<ffi.Char> proxyPass,
+    int reqID,
     int port,
   ) {
     return _CreateProxyToSocks5ServerUDP(
@@ -183,6 +356,7 @@ class Socks5V2Library {
       proxyAddr,
       proxyUser,
       proxyPass,
+      reqID,
       port,
     );
   }
@@ -198,6 +372,7 @@ class Socks5V2Library {
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
       >('CreateProxyToSocks5ServerUDP');
@@ -211,125 +386,922 @@ class Socks5V2Library {
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
           int,
+          int,
         )
       >();
 
-  int CreateWithAuthServer(int listenPort, int port) {
-    return _CreateWithAuthServer(listenPort, port);
-  }
-
-  late final _CreateWithAuthServerPtr =
-      _lookup<ffi.NativeFunction<ffi.LongLong Function(ffi.Int, ffi.LongLong)>>(
-        'CreateWithAuthServer',
-      );
-  late final _CreateWithAuthServer =
-      _CreateWithAuthServerPtr.asFunction<int Function(int, int)>();
-
-  int CreateWithoutAuthServer(int listenPort, int port) {
-    return _CreateWithoutAuthServer(listenPort, port);
-  }
-
-  late final _CreateWithoutAuthServerPtr =
-      _lookup<ffi.NativeFunction<ffi.LongLong Function(ffi.Int, ffi.LongLong)>>(
-        'CreateWithoutAuthServer',
-      );
-  late final _CreateWithoutAuthServer =
-      _CreateWithoutAuthServerPtr.asFunction<int Function(int, int)>();
-
-  int StartSocks5Server(int srvID, int port) {
-    return _StartSocks5Server(srvID, port);
-  }
-
-  late final _StartSocks5ServerPtr =
-      _lookup<
-        ffi.NativeFunction<ffi.LongLong Function(ffi.LongLong, ffi.LongLong)>
-      >('StartSocks5Server');
-  late final _StartSocks5Server =
-      _StartSocks5ServerPtr.asFunction<int Function(int, int)>();
-
-  void StopSocks5Server(int srvID, int port) {
-    return _StopSocks5Server(srvID, port);
-  }
-
-  late final _StopSocks5ServerPtr =
-      _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
-      >('StopSocks5Server');
-  late final _StopSocks5Server =
-      _StopSocks5ServerPtr.asFunction<void Function(int, int)>();
-
-  int ConnectDirectTCP(
-    ffi.Pointer<ffi.Char> socksAddr,
+  int CreateProxyChainServer(
+    int listenPort,
     ffi.Pointer<ffi.Char> username,
     ffi.Pointer<ffi.Char> password,
-    ffi.Pointer<ffi.Char> targetAddr,
+    ffi.Pointer<ffi.Char> hopsJSON,
+    int reqID,
     int port,
   ) {
-    return _ConnectDirectTCP(socksAddr, username, password, targetAddr, port);
+    return _CreateProxyChainServer(
+      listenPort,
+      username,
+      password,
+      hopsJSON,
+      reqID,
+      port,
+    );
   }
 
-  late final _ConnectDirectTCPPtr =
+  late final _CreateProxyChainServerPtr =
       _lookup<
         ffi.NativeFunction<
           ffi.LongLong Function(
+            ffi.Int,
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
-            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
             ffi.LongLong,
           )
         >
-      >('ConnectDirectTCP');
-  late final _ConnectDirectTCP =
-      _ConnectDirectTCPPtr.asFunction<
+      >('CreateProxyChainServer');
+  late final _CreateProxyChainServer =
+      _CreateProxyChainServerPtr.asFunction<
         int Function(
+          int,
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
-          ffi.Pointer<ffi.Char>,
+          int,
           int,
         )
       >();
 
-  int ConnectDirectUDP(
-    ffi.Pointer<ffi.Char> socksAddr,
+  int CreateProxyPoolServer(
+    int listenPort,
     ffi.Pointer<ffi.Char> username,
     ffi.Pointer<ffi.Char> password,
+    ffi.Pointer<ffi.Char> poolJSON,
+    int reqID,
     int port,
   ) {
-    return _ConnectDirectUDP(socksAddr, username, password, port);
+    return _CreateProxyPoolServer(
+      listenPort,
+      username,
+      password,
+      poolJSON,
+      reqID,
+      port,
+    );
   }
 
-  late final _ConnectDirectUDPPtr =
+  late final _CreateProxyPoolServerPtr =
       _lookup<
         ffi.NativeFunction<
           ffi.LongLong Function(
+            ffi.Int,
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.Pointer<ffi.Char>,
             ffi.LongLong,
+            ffi.LongLong,
           )
         >
-      >('ConnectDirectUDP');
-  late final _ConnectDirectUDP =
-      _ConnectDirectUDPPtr.asFunction<
+      >('CreateProxyPoolServer');
+  late final _CreateProxyPoolServer =
+      _CreateProxyPoolServerPtr.asFunction<
         int Function(
+          int,
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
           ffi.Pointer<ffi.Char>,
           int,
+          int,
         )
       >();
 
-  void StopTask(int taskID, int port) {
-    return _StopTask(taskID, port);
+  int CreateServerFromConfig(
+    ffi.Pointer<ffi.Char> configJSON,
+    int reqID,
+    int port,
+  ) {
+    return _CreateServerFromConfig(configJSON, reqID, port);
   }
 
-  late final _StopTaskPtr =
+  late final _CreateServerFromConfigPtr =
       _lookup<
-        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
-      >('StopTask');
-  late final _StopTask = _StopTaskPtr.asFunction<void Function(int, int)>();
+        ffi.NativeFunction<
+          ffi.LongLong Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('CreateServerFromConfig');
+  late final _CreateServerFromConfig =
+      _CreateServerFromConfigPtr.asFunction<
+        int Function(ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void UpdateServerConfig(
+    int srvID,
+    ffi.Pointer<ffi.Char> configJSON,
+    int reqID,
+    int port,
+  ) {
+    return _UpdateServerConfig(srvID, configJSON, reqID, port);
+  }
+
+  late final _UpdateServerConfigPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('UpdateServerConfig');
+  late final _UpdateServerConfig =
+      _UpdateServerConfigPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  int CreateWithAuthServer(int listenPort, int reqID, int port) {
+    return _CreateWithAuthServer(listenPort, reqID, port);
+  }
+
+  late final _CreateWithAuthServerPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('CreateWithAuthServer');
+  late final _CreateWithAuthServer =
+      _CreateWithAuthServerPtr.asFunction<int Function(int, int, int)>();
+
+  int CreateWithoutAuthServer(int listenPort, int reqID, int port) {
+    return _CreateWithoutAuthServer(listenPort, reqID, port);
+  }
+
+  late final _CreateWithoutAuthServerPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('CreateWithoutAuthServer');
+  late final _CreateWithoutAuthServer =
+      _CreateWithoutAuthServerPtr.asFunction<int Function(int, int, int)>();
+
+  int StartSocks5Server(int srvID, int reqID, int port) {
+    return _StartSocks5Server(srvID, reqID, port);
+  }
+
+  late final _StartSocks5ServerPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('StartSocks5Server');
+  late final _StartSocks5Server =
+      _StartSocks5ServerPtr.asFunction<int Function(int, int, int)>();
+
+  void StopSocks5Server(int srvID, int reqID, int port) {
+    return _StopSocks5Server(srvID, reqID, port);
+  }
+
+  late final _StopSocks5ServerPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('StopSocks5Server');
+  late final _StopSocks5Server =
+      _StopSocks5ServerPtr.asFunction<void Function(int, int, int)>();
+
+  void ListConnections(int srvID, int reqID, int port) {
+    return _ListConnections(srvID, reqID, port);
+  }
+
+  late final _ListConnectionsPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('ListConnections');
+  late final _ListConnections =
+      _ListConnectionsPtr.asFunction<void Function(int, int, int)>();
+
+  void KillConnection(int srvID, int connID, int reqID, int port) {
+    return _KillConnection(srvID, connID, reqID, port);
+  }
+
+  late final _KillConnectionPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('KillConnection');
+  late final _KillConnection =
+      _KillConnectionPtr.asFunction<void Function(int, int, int, int)>();
+
+  void GetUpstreamHealth(int srvID, int reqID, int port) {
+    return _GetUpstreamHealth(srvID, reqID, port);
+  }
+
+  late final _GetUpstreamHealthPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetUpstreamHealth');
+  late final _GetUpstreamHealth =
+      _GetUpstreamHealthPtr.asFunction<void Function(int, int, int)>();
+
+  void SetLegacySocks(int srvID, int enabled, int reqID, int port) {
+    return _SetLegacySocks(srvID, enabled, reqID, port);
+  }
+
+  late final _SetLegacySocksPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetLegacySocks');
+  late final _SetLegacySocks =
+      _SetLegacySocksPtr.asFunction<void Function(int, int, int, int)>();
+
+  void SetHTTPProxy(int srvID, int enabled, int reqID, int port) {
+    return _SetHTTPProxy(srvID, enabled, reqID, port);
+  }
+
+  late final _SetHTTPProxyPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.Int, ffi.LongLong, ffi.LongLong)
+        >
+      >('SetHTTPProxy');
+  late final _SetHTTPProxy =
+      _SetHTTPProxyPtr.asFunction<void Function(int, int, int, int)>();
+
+  void AddUser(
+    int srvID,
+    ffi.Pointer<ffi.Char> username,
+    ffi.Pointer<ffi.Char> password,
+    int reqID,
+    int port,
+  ) {
+    return _AddUser(srvID, username, password, reqID, port);
+  }
+
+  late final _AddUserPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('AddUser');
+  late final _AddUser =
+      _AddUserPtr.asFunction<
+        void Function(
+          int,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
+      >();
+
+  void RemoveUser(
+    int srvID,
+    ffi.Pointer<ffi.Char> username,
+    int reqID,
+    int port,
+  ) {
+    return _RemoveUser(srvID, username, reqID, port);
+  }
+
+  late final _RemoveUserPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('RemoveUser');
+  late final _RemoveUser =
+      _RemoveUserPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void SetUserPassword(
+    int srvID,
+    ffi.Pointer<ffi.Char> username,
+    ffi.Pointer<ffi.Char> password,
+    int reqID,
+    int port,
+  ) {
+    return _SetUserPassword(srvID, username, password, reqID, port);
+  }
+
+  late final _SetUserPasswordPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetUserPassword');
+  late final _SetUserPassword =
+      _SetUserPasswordPtr.asFunction<
+        void Function(
+          int,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
+      >();
+
+  void ListUsers(int srvID, int reqID, int port) {
+    return _ListUsers(srvID, reqID, port);
+  }
+
+  late final _ListUsersPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('ListUsers');
+  late final _ListUsers =
+      _ListUsersPtr.asFunction<void Function(int, int, int)>();
+
+  void LoadUsersFile(
+    int srvID,
+    ffi.Pointer<ffi.Char> path,
+    int reqID,
+    int port,
+  ) {
+    return _LoadUsersFile(srvID, path, reqID, port);
+  }
+
+  late final _LoadUsersFilePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('LoadUsersFile');
+  late final _LoadUsersFile =
+      _LoadUsersFilePtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void SetBandwidthLimits(
+    int srvID,
+    ffi.Pointer<ffi.Char> limitsJSON,
+    int reqID,
+    int port,
+  ) {
+    return _SetBandwidthLimits(srvID, limitsJSON, reqID, port);
+  }
+
+  late final _SetBandwidthLimitsPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetBandwidthLimits');
+  late final _SetBandwidthLimits =
+      _SetBandwidthLimitsPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetBandwidthLimits(int srvID, int reqID, int port) {
+    return _GetBandwidthLimits(srvID, reqID, port);
+  }
+
+  late final _GetBandwidthLimitsPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetBandwidthLimits');
+  late final _GetBandwidthLimits =
+      _GetBandwidthLimitsPtr.asFunction<void Function(int, int, int)>();
+
+  void SetConnectionLimits(
+    int srvID,
+    int connID,
+    int uploadBps,
+    int downloadBps,
+    int reqID,
+    int port,
+  ) {
+    return _SetConnectionLimits(
+      srvID,
+      connID,
+      uploadBps,
+      downloadBps,
+      reqID,
+      port,
+    );
+  }
+
+  late final _SetConnectionLimitsPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetConnectionLimits');
+  late final _SetConnectionLimits =
+      _SetConnectionLimitsPtr.asFunction<
+        void Function(int, int, int, int, int, int)
+      >();
+
+  void SetACL(int srvID, ffi.Pointer<ffi.Char> aclJSON, int reqID, int port) {
+    return _SetACL(srvID, aclJSON, reqID, port);
+  }
+
+  late final _SetACLPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetACL');
+  late final _SetACL =
+      _SetACLPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetACL(int srvID, int reqID, int port) {
+    return _GetACL(srvID, reqID, port);
+  }
+
+  late final _GetACLPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetACL');
+  late final _GetACL = _GetACLPtr.asFunction<void Function(int, int, int)>();
+
+  void SetDNSConfig(
+    int srvID,
+    ffi.Pointer<ffi.Char> dnsJSON,
+    int reqID,
+    int port,
+  ) {
+    return _SetDNSConfig(srvID, dnsJSON, reqID, port);
+  }
+
+  late final _SetDNSConfigPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetDNSConfig');
+  late final _SetDNSConfig =
+      _SetDNSConfigPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetDNSConfig(int srvID, int reqID, int port) {
+    return _GetDNSConfig(srvID, reqID, port);
+  }
+
+  late final _GetDNSConfigPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetDNSConfig');
+  late final _GetDNSConfig =
+      _GetDNSConfigPtr.asFunction<void Function(int, int, int)>();
+
+  void SetEgress(
+    int srvID,
+    ffi.Pointer<ffi.Char> egressJSON,
+    int reqID,
+    int port,
+  ) {
+    return _SetEgress(srvID, egressJSON, reqID, port);
+  }
+
+  late final _SetEgressPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetEgress');
+  late final _SetEgress =
+      _SetEgressPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetEgress(int srvID, int reqID, int port) {
+    return _GetEgress(srvID, reqID, port);
+  }
+
+  late final _GetEgressPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetEgress');
+  late final _GetEgress =
+      _GetEgressPtr.asFunction<void Function(int, int, int)>();
+
+  void ResolveHost(int srvID, ffi.Pointer<ffi.Char> host, int reqID, int port) {
+    return _ResolveHost(srvID, host, reqID, port);
+  }
+
+  late final _ResolveHostPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ResolveHost');
+  late final _ResolveHost =
+      _ResolveHostPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void SetGuard(
+    int srvID,
+    ffi.Pointer<ffi.Char> guardJSON,
+    int reqID,
+    int port,
+  ) {
+    return _SetGuard(srvID, guardJSON, reqID, port);
+  }
+
+  late final _SetGuardPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('SetGuard');
+  late final _SetGuard =
+      _SetGuardPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  void GetGuard(int srvID, int reqID, int port) {
+    return _GetGuard(srvID, reqID, port);
+  }
+
+  late final _GetGuardPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetGuard');
+  late final _GetGuard =
+      _GetGuardPtr.asFunction<void Function(int, int, int)>();
+
+  void ListBans(int srvID, int reqID, int port) {
+    return _ListBans(srvID, reqID, port);
+  }
+
+  late final _ListBansPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('ListBans');
+  late final _ListBans =
+      _ListBansPtr.asFunction<void Function(int, int, int)>();
+
+  void ClearBans(int srvID, ffi.Pointer<ffi.Char> ip, int reqID, int port) {
+    return _ClearBans(srvID, ip, reqID, port);
+  }
+
+  late final _ClearBansPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ClearBans');
+  late final _ClearBans =
+      _ClearBansPtr.asFunction<
+        void Function(int, ffi.Pointer<ffi.Char>, int, int)
+      >();
+
+  int ConnectDirectTCP(
+    ffi.Pointer<ffi.Char> socksAddr,
+    ffi.Pointer<ffi.Char> username,
+    ffi.Pointer<ffi.Char> password,
+    ffi.Pointer<ffi.Char> targetAddr,
+    int reqID,
+    int port,
+  ) {
+    return _ConnectDirectTCP(
+      socksAddr,
+      username,
+      password,
+      targetAddr,
+      reqID,
+      port,
+    );
+  }
+
+  late final _ConnectDirectTCPPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ConnectDirectTCP');
+  late final _ConnectDirectTCP =
+      _ConnectDirectTCPPtr.asFunction<
+        int Function(
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
+      >();
+
+  int ConnectDirectUDP(
+    ffi.Pointer<ffi.Char> socksAddr,
+    ffi.Pointer<ffi.Char> username,
+    ffi.Pointer<ffi.Char> password,
+    int reqID,
+    int port,
+  ) {
+    return _ConnectDirectUDP(socksAddr, username, password, reqID, port);
+  }
+
+  late final _ConnectDirectUDPPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ConnectDirectUDP');
+  late final _ConnectDirectUDP =
+      _ConnectDirectUDPPtr.asFunction<
+        int Function(
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
+      >();
+
+  int ClientOpen(
+    ffi.Pointer<ffi.Char> socksAddr,
+    ffi.Pointer<ffi.Char> username,
+    ffi.Pointer<ffi.Char> password,
+    ffi.Pointer<ffi.Char> network,
+    ffi.Pointer<ffi.Char> targetAddr,
+    int reqID,
+    int port,
+  ) {
+    return _ClientOpen(
+      socksAddr,
+      username,
+      password,
+      network,
+      targetAddr,
+      reqID,
+      port,
+    );
+  }
+
+  late final _ClientOpenPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.Pointer<ffi.Char>,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ClientOpen');
+  late final _ClientOpen =
+      _ClientOpenPtr.asFunction<
+        int Function(
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          ffi.Pointer<ffi.Char>,
+          int,
+          int,
+        )
+      >();
+
+  int ClientWrite(
+    int sessionID,
+    ffi.Pointer<ffi.Char> data,
+    int length,
+    int reqID,
+    int port,
+  ) {
+    return _ClientWrite(sessionID, data, length, reqID, port);
+  }
+
+  late final _ClientWritePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(
+            ffi.LongLong,
+            ffi.Pointer<ffi.Char>,
+            ffi.Int,
+            ffi.LongLong,
+            ffi.LongLong,
+          )
+        >
+      >('ClientWrite');
+  late final _ClientWrite =
+      _ClientWritePtr.asFunction<
+        int Function(int, ffi.Pointer<ffi.Char>, int, int, int)
+      >();
+
+  void ClientClose(int sessionID, int reqID, int port) {
+    return _ClientClose(sessionID, reqID, port);
+  }
+
+  late final _ClientClosePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('ClientClose');
+  late final _ClientClose =
+      _ClientClosePtr.asFunction<void Function(int, int, int)>();
+
+  void ListClientSessions(int reqID, int port) {
+    return _ListClientSessions(reqID, port);
+  }
+
+  late final _ListClientSessionsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListClientSessions');
+  late final _ListClientSessions =
+      _ListClientSessionsPtr.asFunction<void Function(int, int)>();
+
+  ffi.Pointer<ffi.Char> GetLastError(int reqID) {
+    return _GetLastError(reqID);
+  }
+
+  late final _GetLastErrorPtr =
+      _lookup<ffi.NativeFunction<ffi.Pointer<ffi.Char> Function(ffi.LongLong)>>(
+        'GetLastError',
+      );
+  late final _GetLastError =
+      _GetLastErrorPtr.asFunction<ffi.Pointer<ffi.Char> Function(int)>();
+
+  void FreeString(ffi.Pointer<ffi.Char> s) {
+    return _FreeString(s);
+  }
+
+  late final _FreeStringPtr =
+      _lookup<ffi.NativeFunction<ffi.Void Function(ffi.Pointer<ffi.Char>)>>(
+        'FreeString',
+      );
+  late final _FreeString =
+      _FreeStringPtr.asFunction<void Function(ffi.Pointer<ffi.Char>)>();
+
+  void StopTask(int taskID, int reqID, int port) {
+    return _StopTask(taskID, reqID, port);
+  }
+
+  late final _StopTaskPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('StopTask');
+  late final _StopTask =
+      _StopTaskPtr.asFunction<void Function(int, int, int)>();
+
+  void ListTasks(int reqID, int port) {
+    return _ListTasks(reqID, port);
+  }
+
+  late final _ListTasksPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListTasks');
+  late final _ListTasks = _ListTasksPtr.asFunction<void Function(int, int)>();
+
+  void GetTask(int taskID, int reqID, int port) {
+    return _GetTask(taskID, reqID, port);
+  }
+
+  late final _GetTaskPtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.Void Function(ffi.LongLong, ffi.LongLong, ffi.LongLong)
+        >
+      >('GetTask');
+  late final _GetTask = _GetTaskPtr.asFunction<void Function(int, int, int)>();
+
+  int Invoke(ffi.Pointer<ffi.Char> requestJSON, int port) {
+    return _Invoke(requestJSON, port);
+  }
+
+  late final _InvokePtr =
+      _lookup<
+        ffi.NativeFunction<
+          ffi.LongLong Function(ffi.Pointer<ffi.Char>, ffi.LongLong)
+        >
+      >('Invoke');
+  late final _Invoke =
+      _InvokePtr.asFunction<int Function(ffi.Pointer<ffi.Char>, int)>();
+
+  void ListOperations(int reqID, int port) {
+    return _ListOperations(reqID, port);
+  }
+
+  late final _ListOperationsPtr =
+      _lookup<
+        ffi.NativeFunction<ffi.Void Function(ffi.LongLong, ffi.LongLong)>
+      >('ListOperations');
+  late final _ListOperations =
+      _ListOperationsPtr.asFunction<void Function(int, int)>();
 }
 
 typedef ptrdiff_t = ffi.LongLong;
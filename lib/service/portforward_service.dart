@@ -12,6 +12,7 @@ class PortForwardService {
   late final ffi.DynamicLibrary _dylib;
   ReceivePort? _receivePort;
   int _nativePort = 0;
+  int _requestID = 0;
   final Map<String, Function(Map<String, dynamic>)> _responseHandlers = {};
 
   /// Singleton instance
@@ -98,6 +99,10 @@ class PortForwardService {
     _receivePort = null;
   }
 
+  /// Returns a fresh request id, which the library echoes back as
+  /// request_id in the replies to that call
+  int _nextRequestID() => ++_requestID;
+
   // ==================== PORT FORWARD OPERATIONS ====================
 
   /// Create a port forwarder instance
@@ -129,6 +134,7 @@ class PortForwardService {
       urlPtr,
       portsPtr,
       addressPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -159,7 +165,11 @@ class PortForwardService {
       }
     };
 
-    final taskID = _bindings.StartForwardPorts(pfID, _nativePort);
+    final taskID = _bindings.StartForwardPorts(
+      pfID,
+      _nextRequestID(),
+      _nativePort,
+    );
 
     // If response is synchronous (taskID > 0), complete immediately
     if (taskID > 0) {
@@ -182,7 +192,7 @@ class PortForwardService {
       }
     };
 
-    _bindings.StopForwardPorts(pfID, _nativePort);
+    _bindings.StopForwardPorts(pfID, _nextRequestID(), _nativePort);
 
     return completer.future;
   }
@@ -202,7 +212,7 @@ class PortForwardService {
       }
     };
 
-    _bindings.GetForwardedPorts(pfID, _nativePort);
+    _bindings.GetForwardedPorts(pfID, _nextRequestID(), _nativePort);
 
     return completer.future;
   }
@@ -211,6 +221,6 @@ class PortForwardService {
   ///
   /// [taskID] - Task ID returned from startForwardPorts
   void stopTask(int taskID) {
-    _bindings.StopTask(taskID, _nativePort);
+    _bindings.StopTask(taskID, _nextRequestID(), _nativePort);
   }
 }
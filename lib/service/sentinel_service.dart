@@ -12,6 +12,7 @@ class SentinelService {
   late final ffi.DynamicLibrary _dylib;
   ReceivePort? _receivePort;
   int _nativePort = 0;
+  int _requestID = 0;
   final Map<String, Function(Map<String, dynamic>)> _responseHandlers = {};
 
   /// Singleton instance
@@ -98,6 +99,10 @@ class SentinelService {
     _receivePort = null;
   }
 
+  /// Returns a fresh request id, which the library echoes back as
+  /// request_id in the replies to that call
+  int _nextRequestID() => ++_requestID;
+
   // ==================== MOUSE OPERATIONS ====================
 
   /// Move mouse to absolute coordinates
@@ -110,7 +115,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.Move(x, y, _nativePort);
+    _bindings.Move(x, y, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -124,7 +129,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.MoveRelative(x, y, _nativePort);
+    _bindings.MoveRelative(x, y, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -139,7 +144,7 @@ class SentinelService {
       }
     };
     final btnPtr = button.toNativeUtf8().cast<ffi.Char>();
-    _bindings.Click(btnPtr, doubleClick ? 1 : 0, _nativePort);
+    _bindings.Click(btnPtr, doubleClick ? 1 : 0, _nextRequestID(), _nativePort);
     malloc.free(btnPtr);
     return completer.future;
   }
@@ -154,7 +159,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.GetLocation(_nativePort);
+    _bindings.GetLocation(_nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -168,23 +173,23 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.Scroll(x, y, _nativePort);
+    _bindings.Scroll(x, y, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
   /// Start smooth mouse movement (returns task ID)
   int moveSmoothStart(int x, int y) {
-    return _bindings.MoveSmoothStart(x, y, _nativePort);
+    return _bindings.MoveSmoothStart(x, y, _nextRequestID(), _nativePort);
   }
 
   /// Start smooth drag operation (returns task ID)
   int dragSmoothStart(int x, int y) {
-    return _bindings.DragSmoothStart(x, y, _nativePort);
+    return _bindings.DragSmoothStart(x, y, _nextRequestID(), _nativePort);
   }
 
   /// Stop a running task
   void stopTask(int taskId) {
-    _bindings.StopTask(taskId, _nativePort);
+    _bindings.StopTask(taskId, _nextRequestID(), _nativePort);
   }
 
   // ==================== KEYBOARD OPERATIONS ====================
@@ -200,7 +205,7 @@ class SentinelService {
       }
     };
     final textPtr = text.toNativeUtf8().cast<ffi.Char>();
-    _bindings.TypeStr(textPtr, _nativePort);
+    _bindings.TypeStr(textPtr, _nextRequestID(), _nativePort);
     malloc.free(textPtr);
     return completer.future;
   }
@@ -226,7 +231,7 @@ class SentinelService {
       modsPtr = ffi.nullptr;
     }
     
-    _bindings.KeyTap(keyPtr, modsPtr, _nativePort);
+    _bindings.KeyTap(keyPtr, modsPtr, _nextRequestID(), _nativePort);
     
     malloc.free(keyPtr);
     if (modsPtr != ffi.nullptr) malloc.free(modsPtr);
@@ -244,7 +249,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.ReadAll(_nativePort);
+    _bindings.ReadAll(_nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -259,7 +264,7 @@ class SentinelService {
       }
     };
     final textPtr = text.toNativeUtf8().cast<ffi.Char>();
-    _bindings.WriteAll(textPtr, _nativePort);
+    _bindings.WriteAll(textPtr, _nextRequestID(), _nativePort);
     malloc.free(textPtr);
     return completer.future;
   }
@@ -276,7 +281,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.GetScreenSize(_nativePort);
+    _bindings.GetScreenSize(_nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -290,7 +295,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.GetPixelColor(x, y, _nativePort);
+    _bindings.GetPixelColor(x, y, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -312,7 +317,15 @@ class SentinelService {
       pathPtr = ffi.nullptr;
     }
     
-    _bindings.CaptureScreenSave(x, y, w, h, pathPtr, _nativePort);
+    _bindings.CaptureScreenSave(
+      x,
+      y,
+      w,
+      h,
+      pathPtr,
+      _nextRequestID(),
+      _nativePort,
+    );
     
     if (pathPtr != ffi.nullptr) malloc.free(pathPtr);
     
@@ -329,7 +342,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.CaptureScreenBase64(x, y, w, h, _nativePort);
+    _bindings.CaptureScreenBase64(x, y, w, h, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -343,7 +356,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.DisplaysNum(_nativePort);
+    _bindings.DisplaysNum(_nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -357,7 +370,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.GetDisplayBounds(index, _nativePort);
+    _bindings.GetDisplayBounds(index, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -369,7 +382,7 @@ class SentinelService {
     if (onStats != null) {
       _responseHandlers['monitor_stats'] = onStats;
     }
-    final taskId = _bindings.StartMonitor(_nativePort);
+    final taskId = _bindings.StartMonitor(_nextRequestID(), _nativePort);
     return taskId;
   }
 
@@ -388,7 +401,7 @@ class SentinelService {
     final namePtr = serviceName.toNativeUtf8().cast<ffi.Char>();
     final actionPtr = action.toNativeUtf8().cast<ffi.Char>();
     
-    _bindings.ControlService(namePtr, actionPtr, _nativePort);
+    _bindings.ControlService(namePtr, actionPtr, _nextRequestID(), _nativePort);
     
     malloc.free(namePtr);
     malloc.free(actionPtr);
@@ -410,7 +423,7 @@ class SentinelService {
       modsPtr = ffi.nullptr;
     }
     
-    _bindings.HookRegisterCombo(modsPtr, keyPtr, _nativePort);
+    _bindings.HookRegisterCombo(modsPtr, keyPtr, _nextRequestID(), _nativePort);
     
     malloc.free(keyPtr);
     if (modsPtr != ffi.nullptr) malloc.free(modsPtr);
@@ -418,7 +431,7 @@ class SentinelService {
 
   /// Start hook event listening
   void hookStart() {
-    _bindings.HookStart(_nativePort);
+    _bindings.HookStart(_nextRequestID(), _nativePort);
   }
 
   /// Stop hook event listening
@@ -438,7 +451,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.MilliSleep(ms, _nativePort);
+    _bindings.MilliSleep(ms, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -452,7 +465,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.SetMouseSleep(ms, _nativePort);
+    _bindings.SetMouseSleep(ms, _nextRequestID(), _nativePort);
     return completer.future;
   }
 
@@ -466,7 +479,7 @@ class SentinelService {
         completer.completeError(data['error'] ?? 'Unknown error');
       }
     };
-    _bindings.SetKeySleep(ms, _nativePort);
+    _bindings.SetKeySleep(ms, _nextRequestID(), _nativePort);
     return completer.future;
   }
 }
@@ -12,6 +12,7 @@ class Socks5Service {
   late final ffi.DynamicLibrary _dylib;
   ReceivePort? _receivePort;
   int _nativePort = 0;
+  int _requestID = 0;
   final Map<String, Function(Map<String, dynamic>)> _responseHandlers = {};
 
   /// Singleton instance
@@ -110,6 +111,10 @@ class Socks5Service {
     _receivePort = null;
   }
 
+  /// Returns a fresh request id, which the library echoes back as
+  /// request_id in the replies to that call
+  int _nextRequestID() => ++_requestID;
+
   // ==================== SOCKS5 SERVER OPERATIONS ====================
 
   /// Create a direct TCP SOCKS5 server
@@ -144,6 +149,7 @@ class Socks5Service {
       listenPort,
       usernamePtr,
       passwordPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -190,6 +196,7 @@ class Socks5Service {
       listenPort,
       usernamePtr,
       passwordPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -252,6 +259,7 @@ class Socks5Service {
       proxyAddrPtr,
       proxyUserPtr,
       proxyPassPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -284,7 +292,11 @@ class Socks5Service {
       }
     };
 
-    final srvID = _bindings.CreateWithAuthServer(listenPort, _nativePort);
+    final srvID = _bindings.CreateWithAuthServer(
+      listenPort,
+      _nextRequestID(),
+      _nativePort,
+    );
 
     // If response is synchronous (srvID > 0), complete immediately
     if (srvID > 0) {
@@ -309,7 +321,11 @@ class Socks5Service {
       }
     };
 
-    final srvID = _bindings.CreateWithoutAuthServer(listenPort, _nativePort);
+    final srvID = _bindings.CreateWithoutAuthServer(
+      listenPort,
+      _nextRequestID(),
+      _nativePort,
+    );
 
     // If response is synchronous (srvID > 0), complete immediately
     if (srvID > 0) {
@@ -334,7 +350,11 @@ class Socks5Service {
       }
     };
 
-    final taskID = _bindings.StartSocks5Server(srvID, _nativePort);
+    final taskID = _bindings.StartSocks5Server(
+      srvID,
+      _nextRequestID(),
+      _nativePort,
+    );
 
     // If response is synchronous (taskID > 0), complete immediately
     if (taskID > 0) {
@@ -357,7 +377,7 @@ class Socks5Service {
       }
     };
 
-    _bindings.StopSocks5Server(srvID, _nativePort);
+    _bindings.StopSocks5Server(srvID, _nextRequestID(), _nativePort);
 
     return completer.future;
   }
@@ -366,6 +386,6 @@ class Socks5Service {
   ///
   /// [taskID] - Task ID returned from startSocks5Server
   void stopTask(int taskID) {
-    _bindings.StopTask(taskID, _nativePort);
+    _bindings.StopTask(taskID, _nextRequestID(), _nativePort);
   }
 }
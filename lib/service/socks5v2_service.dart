@@ -13,6 +13,7 @@ class Socks5V2Service {
   late final ffi.DynamicLibrary _dylib;
   ReceivePort? _receivePort;
   int _nativePort = 0;
+  int _requestID = 0;
   final Map<String, Function(Map<String, dynamic>)> _responseHandlers = {};
 
   /// Singleton instance
@@ -111,6 +112,10 @@ class Socks5V2Service {
     _receivePort = null;
   }
 
+  /// Returns a fresh request id, which the library echoes back as
+  /// request_id in the replies to that call
+  int _nextRequestID() => ++_requestID;
+
   // ==================== SOCKS5 SERVER OPERATIONS ====================
 
   /// Create a direct TCP SOCKS5 server
@@ -145,6 +150,7 @@ class Socks5V2Service {
       listenPort,
       usernamePtr,
       passwordPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -191,6 +197,7 @@ class Socks5V2Service {
       listenPort,
       usernamePtr,
       passwordPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -253,6 +260,7 @@ class Socks5V2Service {
       proxyAddrPtr,
       proxyUserPtr,
       proxyPassPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -318,6 +326,7 @@ class Socks5V2Service {
       proxyAddrPtr,
       proxyUserPtr,
       proxyPassPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -350,7 +359,11 @@ class Socks5V2Service {
       }
     };
 
-    final srvID = _bindings.CreateWithAuthServer(listenPort, _nativePort);
+    final srvID = _bindings.CreateWithAuthServer(
+      listenPort,
+      _nextRequestID(),
+      _nativePort,
+    );
 
     // If response is synchronous (srvID > 0), complete immediately
     if (srvID > 0) {
@@ -375,7 +388,11 @@ class Socks5V2Service {
       }
     };
 
-    final srvID = _bindings.CreateWithoutAuthServer(listenPort, _nativePort);
+    final srvID = _bindings.CreateWithoutAuthServer(
+      listenPort,
+      _nextRequestID(),
+      _nativePort,
+    );
 
     // If response is synchronous (srvID > 0), complete immediately
     if (srvID > 0) {
@@ -400,7 +417,11 @@ class Socks5V2Service {
       }
     };
 
-    final taskID = _bindings.StartSocks5Server(srvID, _nativePort);
+    final taskID = _bindings.StartSocks5Server(
+      srvID,
+      _nextRequestID(),
+      _nativePort,
+    );
 
     // If response is synchronous (taskID > 0), complete immediately
     if (taskID > 0) {
@@ -423,7 +444,7 @@ class Socks5V2Service {
       }
     };
 
-    _bindings.StopSocks5Server(srvID, _nativePort);
+    _bindings.StopSocks5Server(srvID, _nextRequestID(), _nativePort);
 
     return completer.future;
   }
@@ -467,6 +488,7 @@ class Socks5V2Service {
       usernamePtr,
       passwordPtr,
       targetAddrPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -516,6 +538,7 @@ class Socks5V2Service {
       socksAddrPtr,
       usernamePtr,
       passwordPtr,
+      _nextRequestID(),
       _nativePort,
     );
 
@@ -537,6 +560,6 @@ class Socks5V2Service {
   ///
   /// [taskID] - Task ID returned from startSocks5Server or connect methods
   void stopTask(int taskID) {
-    _bindings.StopTask(taskID, _nativePort);
+    _bindings.StopTask(taskID, _nextRequestID(), _nativePort);
   }
 }
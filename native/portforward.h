@@ -24,10 +24,12 @@ extern const char *_GoStringPtr(_GoString_ s);
 #line 3 "main.go"
 
 #include <stdint.h>
+#include <stdlib.h>
 
 #line 1 "cgo-generated-wrapper"
 
 
+
 /* End of preamble from import "C" comments.  */
 
 
@@ -90,11 +92,25 @@ extern "C" {
 extern __declspec(dllexport) void RegisterPort(long long port);
 extern __declspec(dllexport) void UnregisterPort(void);
 extern __declspec(dllexport) void BridgeInit(void* api);
-extern __declspec(dllexport) long long CreatePortForwarder(char* urlStr, char* portsStr, char* addressStr, long long port);
-extern __declspec(dllexport) long long StartForwardPorts(long long pfID, long long port);
-extern __declspec(dllexport) void StopForwardPorts(long long pfID, long long port);
-extern __declspec(dllexport) void GetForwardedPorts(long long pfID, long long port);
-extern __declspec(dllexport) void StopTask(long long taskID, long long port);
+extern __declspec(dllexport) void ConfigureQueue(long long targetPort, int capacity, char* policy, long long reqID, long long port);
+extern __declspec(dllexport) void GetQueueStats(long long reqID, long long port);
+extern __declspec(dllexport) void SetLogPort(long long logPort, char* level, long long reqID, long long port);
+extern __declspec(dllexport) void SetLogLevel(char* level, long long reqID, long long port);
+extern __declspec(dllexport) void Subscribe(char* topic, long long subPort, long long reqID, long long port);
+extern __declspec(dllexport) void Unsubscribe(char* topic, long long subPort, long long reqID, long long port);
+extern __declspec(dllexport) void ListSubscriptions(long long reqID, long long port);
+extern __declspec(dllexport) void Shutdown(int timeoutMs, long long reqID, long long port);
+extern __declspec(dllexport) long long CreatePortForwarder(char* urlStr, char* portsStr, char* addressStr, long long reqID, long long port);
+extern __declspec(dllexport) long long StartForwardPorts(long long pfID, long long reqID, long long port);
+extern __declspec(dllexport) void StopForwardPorts(long long pfID, long long reqID, long long port);
+extern __declspec(dllexport) void GetForwardedPorts(long long pfID, long long reqID, long long port);
+extern __declspec(dllexport) char* GetLastError(long long reqID);
+extern __declspec(dllexport) void FreeString(char* s);
+extern __declspec(dllexport) void StopTask(long long taskID, long long reqID, long long port);
+extern __declspec(dllexport) void ListTasks(long long reqID, long long port);
+extern __declspec(dllexport) void GetTask(long long taskID, long long reqID, long long port);
+extern __declspec(dllexport) long long Invoke(char* requestJSON, long long port);
+extern __declspec(dllexport) void ListOperations(long long reqID, long long port);
 
 #ifdef __cplusplus
 }
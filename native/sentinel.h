@@ -28,6 +28,7 @@ extern const char *_GoStringPtr(_GoString_ s);
 #line 1 "cgo-generated-wrapper"
 
 
+
 /* End of preamble from import "C" comments.  */
 
 
@@ -90,101 +91,62 @@ extern "C" {
 extern void RegisterPort(long long int port);
 extern void UnregisterPort(void);
 extern void BridgeInit(void* api);
-extern void Move(int x, int y, long long int port);
-extern void MoveRelative(int x, int y, long long int port);
-extern void Click(char* btn, int dbl, long long int port);
-extern void Toggle(char* btn, char* dir, long long int port);
-extern void Scroll(int x, int y, long long int port);
-extern void ScrollDir(int amount, char* dir, long long int port);
-extern void GetLocation(long long int port);
-extern void SetMouseSleep(int ms, long long int port);
-extern void MilliSleep(int ms, long long int port);
-extern void StopTask(long long int taskID, long long int port);
-
-// ---- cancellable / long-running ops (return task id) ----
-//
-extern long long int MoveSmoothStart(int x, int y, long long int port);
-extern long long int DragSmoothStart(int x, int y, long long int port);
-extern long long int ScrollSmoothStart(int x, int y, long long int port);
-extern void TypeStr(char* text, long long int port);
-
-// allows calling robotgo.TypeStr(s, int1, int2) (some usages pass additional ints)
-//
-extern void TypeStrWithInts(char* text, int arg1, int arg2, long long int port);
-extern void GoSleep(int seconds, long long int port);
-extern void SetKeySleep(int ms, long long int port);
-
-// mods: comma separated modifiers string like "alt,cmd" or NULL for none
-//
-extern void KeyTap(char* key, char* mods, long long int port);
-extern void KeyTapArr(char* key, char* modsJson, long long int port);
-
-// direction: NULL for default, or "up" / "down"
-//
-extern void KeyToggle(char* key, char* direction, long long int port);
-extern void WriteAll(char* text, long long int port);
-extern void ReadAll(long long int port);
-extern long long int TypeStrStart(char* text, long long int port);
-extern void GetPixelColor(int x, int y, long long int port);
-extern void GetScreenSize(long long int port);
-extern void CaptureScreenSave(int x, int y, int w, int h, char* path, long long int port);
-
-// returns a base64-encoded PNG of the captured region in the "data" response field.
-//
-extern void CaptureScreenBase64(int x, int y, int w, int h, long long int port);
-extern void DisplaysNum(long long int port);
-extern void GetDisplayBounds(int index, long long int port);
-
-// capture entire display specified by index and save to given path (if path NULL, create temp file)
-//
-extern void CaptureDisplaySave(int index, char* path, long long int port);
-
-// capture specific region on current display (or after setting DisplayID) and save
-//
-extern void CaptureDisplayRegionSave(int index, int x, int y, int w, int h, char* path, long long int port);
-
-// save an image.Image (captured via CaptureImg) to jpeg with quality (0-100).
-// Here we accept path and quality; we capture entire display at robotgo.DisplayID.
-//
-extern void SaveImageJpeg(char* path, int quality, long long int port);
-
-// capture current display and save PNG to path
-//
-extern void SaveImagePNGFromCaptureImg(char* path, long long int port);
-
-// capture a region and save the raw bitmap to a file using bitmap.Save.
-// If path is NULL or empty a temp filename is created (returned).
-//
-extern void CaptureScreenBitmapSave(int x, int y, int w, int h, char* path, long long int port);
-
-// capture region -> convert -> search on screen -> return found coords {x,y}
-// This uses the same approach as your example: capture -> ToImage -> ImgToBitmap -> ToCBitmap -> bitmap.Find
-//
-extern void FindBitmapFromCapture(int x, int y, int w, int h, long long int port);
-
-// capture region -> convert -> find all occurrences on screen -> return array of points
-//
-extern void FindAllBitmapFromCapture(int x, int y, int w, int h, long long int port);
-extern void MoveToFoundBitmap(int x, int y, int w, int h, long long int port);
-
-// Save a captured region to a file (PNG). This uses robotgo.ToImage + robotgo.Save (or bitmap.Save).
-//
-extern void SaveBitmapToFile(int x, int y, int w, int h, char* path, long long int port);
-extern void SaveCaptureRegion(char* path, int x, int y, int w, int h, long long int port);
-extern void SaveCaptureFull(char* path, long long int port);
-extern void BitmapOpenFind(char* path, long long int port);
-extern void BitmapFindAllFromFile(char* path, long long int port);
-extern void MoveToFoundFromFile(char* path, long long int port);
-extern void HookRegisterCombo(char* mods, char* key, long long int port);
-extern void HookStart(long long int port);
+extern void ConfigureQueue(long long int targetPort, int capacity, char* policy, long long int reqID, long long int port);
+extern void GetQueueStats(long long int reqID, long long int port);
+extern void SetLogPort(long long int logPort, char* level, long long int reqID, long long int port);
+extern void SetLogLevel(char* level, long long int reqID, long long int port);
+extern void Subscribe(char* topic, long long int subPort, long long int reqID, long long int port);
+extern void Unsubscribe(char* topic, long long int subPort, long long int reqID, long long int port);
+extern void ListSubscriptions(long long int reqID, long long int port);
+extern void Shutdown(int timeoutMs, long long int reqID, long long int port);
+extern void Move(int x, int y, long long int reqID, long long int port);
+extern void MoveRelative(int x, int y, long long int reqID, long long int port);
+extern void Click(char* btn, int dbl, long long int reqID, long long int port);
+extern void Toggle(char* btn, char* dir, long long int reqID, long long int port);
+extern void Scroll(int x, int y, long long int reqID, long long int port);
+extern void ScrollDir(int amount, char* dir, long long int reqID, long long int port);
+extern void GetLocation(long long int reqID, long long int port);
+extern void SetMouseSleep(int ms, long long int reqID, long long int port);
+extern void MilliSleep(int ms, long long int reqID, long long int port);
+extern long long int MoveSmoothStart(int x, int y, long long int reqID, long long int port);
+extern long long int DragSmoothStart(int x, int y, long long int reqID, long long int port);
+extern long long int ScrollSmoothStart(int x, int y, long long int reqID, long long int port);
+extern void TypeStr(char* text, long long int reqID, long long int port);
+extern void TypeStrWithInts(char* text, int arg1, int arg2, long long int reqID, long long int port);
+extern void GoSleep(int seconds, long long int reqID, long long int port);
+extern void SetKeySleep(int ms, long long int reqID, long long int port);
+extern void KeyTap(char* key, char* mods, long long int reqID, long long int port);
+extern void KeyTapArr(char* key, char* modsJson, long long int reqID, long long int port);
+extern void KeyToggle(char* key, char* direction, long long int reqID, long long int port);
+extern void WriteAll(char* text, long long int reqID, long long int port);
+extern void ReadAll(long long int reqID, long long int port);
+extern long long int TypeStrStart(char* text, long long int reqID, long long int port);
+extern void GetPixelColor(int x, int y, long long int reqID, long long int port);
+extern void GetScreenSize(long long int reqID, long long int port);
+extern void CaptureScreenSave(int x, int y, int w, int h, char* path, long long int reqID, long long int port);
+extern void CaptureScreenBase64(int x, int y, int w, int h, long long int reqID, long long int port);
+extern void CaptureScreenPNG(int x, int y, int w, int h, long long int reqID, long long int port);
+extern void DisplaysNum(long long int reqID, long long int port);
+extern void GetDisplayBounds(int index, long long int reqID, long long int port);
+extern void CaptureDisplaySave(int index, char* path, long long int reqID, long long int port);
+extern void CaptureDisplayRegionSave(int index, int x, int y, int w, int h, char* path, long long int reqID, long long int port);
+extern void SaveImageJpeg(char* path, int quality, long long int reqID, long long int port);
+extern void SaveImagePNGFromCaptureImg(char* path, long long int reqID, long long int port);
+extern void SaveBitmapToFile(int x, int y, int w, int h, char* path, long long int reqID, long long int port);
+extern void SaveCaptureRegion(char* path, int x, int y, int w, int h, long long int reqID, long long int port);
+extern void SaveCaptureFull(char* path, long long int reqID, long long int port);
+extern void HookRegisterCombo(char* mods, char* key, long long int reqID, long long int port);
+extern void HookStart(long long int reqID, long long int port);
 extern void HookStop(void);
-extern void HookAddEvent(char* name, long long int port);
-
-// convenience debug helper: decode image file and return width/height
-//
-extern void DecodeAndReportImageSize(char* path, long long int port);
-extern long long int StartMonitor(long long int port);
-extern void ControlService(char* name, char* action, long long int port);
+extern void HookAddEvent(char* name, long long int reqID, long long int port);
+extern void DecodeAndReportImageSize(char* path, long long int reqID, long long int port);
+extern long long int StartMonitor(long long int reqID, long long int port);
+extern void ControlService(char* name, char* action, long long int reqID, long long int port);
+extern void StopTask(long long int taskID, long long int reqID, long long int port);
+extern void ListTasks(long long int reqID, long long int port);
+extern void GetTask(long long int taskID, long long int reqID, long long int port);
+extern long long int Invoke(char* requestJSON, long long int port);
+extern void ListOperations(long long int reqID, long long int port);
 
 #ifdef __cplusplus
 }
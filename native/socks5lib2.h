@@ -24,10 +24,12 @@ extern const char *_GoStringPtr(_GoString_ s);
 #line 3 "main.go"
 
 #include <stdint.h>
+#include <stdlib.h>
 
 #line 1 "cgo-generated-wrapper"
 
 
+
 /* End of preamble from import "C" comments.  */
 
 
@@ -90,17 +92,63 @@ extern "C" {
 extern void RegisterPort(long long int port);
 extern void UnregisterPort(void);
 extern void BridgeInit(void* api);
-extern long long int CreateDirectServerTCP(int listenPort, char* username, char* password, long long int port);
-extern long long int CreateDirectServerUDP(int listenPort, char* username, char* password, long long int port);
-extern long long int CreateProxyToSocks5ServerTCP(int listenPort, char* username, char* password, char* proxyAddr, char* proxyUser, char* proxyPass, long long int port);
-extern long long int CreateProxyToSocks5ServerUDP(int listenPort, char* username, char* password, char* proxyAddr, char* proxyUser, char* proxyPass, long long int port);
-extern long long int CreateWithAuthServer(int listenPort, long long int port);
-extern long long int CreateWithoutAuthServer(int listenPort, long long int port);
-extern long long int StartSocks5Server(long long int srvID, long long int port);
-extern void StopSocks5Server(long long int srvID, long long int port);
-extern long long int ConnectDirectTCP(char* socksAddr, char* username, char* password, char* targetAddr, long long int port);
-extern long long int ConnectDirectUDP(char* socksAddr, char* username, char* password, long long int port);
-extern void StopTask(long long int taskID, long long int port);
+extern void ConfigureQueue(long long int targetPort, int capacity, char* policy, long long int reqID, long long int port);
+extern void GetQueueStats(long long int reqID, long long int port);
+extern void SetLogPort(long long int logPort, char* level, long long int reqID, long long int port);
+extern void SetLogLevel(char* level, long long int reqID, long long int port);
+extern void Subscribe(char* topic, long long int subPort, long long int reqID, long long int port);
+extern void Unsubscribe(char* topic, long long int subPort, long long int reqID, long long int port);
+extern void ListSubscriptions(long long int reqID, long long int port);
+extern void Shutdown(int timeoutMs, long long int reqID, long long int port);
+extern long long int CreateDirectServerTCP(int listenPort, char* username, char* password, long long int reqID, long long int port);
+extern long long int CreateDirectServerUDP(int listenPort, char* username, char* password, long long int reqID, long long int port);
+extern long long int CreateProxyToSocks5ServerTCP(int listenPort, char* username, char* password, char* proxyAddr, char* proxyUser, char* proxyPass, long long int reqID, long long int port);
+extern long long int CreateProxyToSocks5ServerUDP(int listenPort, char* username, char* password, char* proxyAddr, char* proxyUser, char* proxyPass, long long int reqID, long long int port);
+extern long long int CreateProxyChainServer(int listenPort, char* username, char* password, char* hopsJSON, long long int reqID, long long int port);
+extern long long int CreateProxyPoolServer(int listenPort, char* username, char* password, char* poolJSON, long long int reqID, long long int port);
+extern long long int CreateServerFromConfig(char* configJSON, long long int reqID, long long int port);
+extern void UpdateServerConfig(long long int srvID, char* configJSON, long long int reqID, long long int port);
+extern long long int CreateWithAuthServer(int listenPort, long long int reqID, long long int port);
+extern long long int CreateWithoutAuthServer(int listenPort, long long int reqID, long long int port);
+extern long long int StartSocks5Server(long long int srvID, long long int reqID, long long int port);
+extern void StopSocks5Server(long long int srvID, long long int reqID, long long int port);
+extern void ListConnections(long long int srvID, long long int reqID, long long int port);
+extern void KillConnection(long long int srvID, long long int connID, long long int reqID, long long int port);
+extern void GetUpstreamHealth(long long int srvID, long long int reqID, long long int port);
+extern void SetLegacySocks(long long int srvID, int enabled, long long int reqID, long long int port);
+extern void SetHTTPProxy(long long int srvID, int enabled, long long int reqID, long long int port);
+extern void AddUser(long long int srvID, char* username, char* password, long long int reqID, long long int port);
+extern void RemoveUser(long long int srvID, char* username, long long int reqID, long long int port);
+extern void SetUserPassword(long long int srvID, char* username, char* password, long long int reqID, long long int port);
+extern void ListUsers(long long int srvID, long long int reqID, long long int port);
+extern void LoadUsersFile(long long int srvID, char* path, long long int reqID, long long int port);
+extern void SetBandwidthLimits(long long int srvID, char* limitsJSON, long long int reqID, long long int port);
+extern void GetBandwidthLimits(long long int srvID, long long int reqID, long long int port);
+extern void SetConnectionLimits(long long int srvID, long long int connID, long long int uploadBps, long long int downloadBps, long long int reqID, long long int port);
+extern void SetACL(long long int srvID, char* aclJSON, long long int reqID, long long int port);
+extern void GetACL(long long int srvID, long long int reqID, long long int port);
+extern void SetDNSConfig(long long int srvID, char* dnsJSON, long long int reqID, long long int port);
+extern void GetDNSConfig(long long int srvID, long long int reqID, long long int port);
+extern void SetEgress(long long int srvID, char* egressJSON, long long int reqID, long long int port);
+extern void GetEgress(long long int srvID, long long int reqID, long long int port);
+extern void ResolveHost(long long int srvID, char* host, long long int reqID, long long int port);
+extern void SetGuard(long long int srvID, char* guardJSON, long long int reqID, long long int port);
+extern void GetGuard(long long int srvID, long long int reqID, long long int port);
+extern void ListBans(long long int srvID, long long int reqID, long long int port);
+extern void ClearBans(long long int srvID, char* ip, long long int reqID, long long int port);
+extern long long int ConnectDirectTCP(char* socksAddr, char* username, char* password, char* targetAddr, long long int reqID, long long int port);
+extern long long int ConnectDirectUDP(char* socksAddr, char* username, char* password, long long int reqID, long long int port);
+extern long long int ClientOpen(char* socksAddr, char* username, char* password, char* network, char* targetAddr, long long int reqID, long long int port);
+extern long long int ClientWrite(long long int sessionID, char* data, int length, long long int reqID, long long int port);
+extern void ClientClose(long long int sessionID, long long int reqID, long long int port);
+extern void ListClientSessions(long long int reqID, long long int port);
+extern char* GetLastError(long long int reqID);
+extern void FreeString(char* s);
+extern void StopTask(long long int taskID, long long int reqID, long long int port);
+extern void ListTasks(long long int reqID, long long int port);
+extern void GetTask(long long int taskID, long long int reqID, long long int port);
+extern long long int Invoke(char* requestJSON, long long int port);
+extern void ListOperations(long long int reqID, long long int port);
 
 #ifdef __cplusplus
 }
@@ -3,7 +3,7 @@ module portforward
 go 1.25.4
 
 require (
-	bridge v0.0.0-00010101000000-000000000000
+	core v0.0.0-00010101000000-000000000000
 	k8s.io/client-go v0.34.2
 )
 
@@ -39,4 +39,5 @@ require (
 	sigs.k8s.io/yaml v1.6.0 // indirect
 )
 
-replace bridge => ./bridge
+replace core => ../core
+
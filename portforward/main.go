@@ -2,20 +2,21 @@ package main
 
 /*
 #include <stdint.h>
+#include <stdlib.h>
 */
 import "C"
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
-	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
-	bridge "bridge"
+	core "core"
+	bridge "core/bridge"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/portforward"
@@ -33,123 +34,199 @@ var (
 	nextPFID       int64 // atomic increment
 )
 
-type taskRec struct {
-	cancel context.CancelFunc
-	done   chan struct{}
-}
-
-var (
-	tasks    = map[int64]*taskRec{}
-	tasksMu  sync.Mutex
-	nextTask int64 // start from 0; atomic increment gives 1,2,...
-)
-var (
-	registeredPort int64
-	portMu         sync.RWMutex
-)
-
-type simpleResp struct {
-	Op      string      `json:"op"`
-	Success bool        `json:"success"`
-	Error   string      `json:"error,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-}
-
 type PortForwarderWrapper struct {
 	PF       *portforward.PortForwarder
 	Ready    <-chan struct{}
 	StopChan chan struct{}
 	ID       int64
+	stopOnce sync.Once
 }
 
-func addTask(cancel context.CancelFunc) int64 {
-	id := atomic.AddInt64(&nextTask, 1)
-	entry := &taskRec{
-		cancel: cancel,
-		done:   make(chan struct{}),
-	}
-	tasksMu.Lock()
-	tasks[id] = entry
-	tasksMu.Unlock()
-	return id
+// stop closes StopChan and the forwarder; safe to call more than once.
+func (w *PortForwarderWrapper) stop() {
+	w.stopOnce.Do(func() {
+		close(w.StopChan)
+		w.PF.Close()
+	})
 }
 
-func finishTask(id int64) {
-	tasksMu.Lock()
-	if e, ok := tasks[id]; ok {
-		close(e.done)
-		delete(tasks, id)
+func init() {
+	core.SetPoster(bridge.SendKeyedStringToPort)
+	bridge.Logger = core.BridgeLogger
+	core.CaptureStdLog()
+	core.OnShutdown("port forwarders", shutdownForwarders)
+}
+
+// shutdownForwarders stops every forwarder; their tasks return on their own.
+func shutdownForwarders(ctx context.Context) []string {
+	portFWsMu.Lock()
+	ws := make([]*PortForwarderWrapper, 0, len(portForwarders))
+	for id, w := range portForwarders {
+		ws = append(ws, w)
+		delete(portForwarders, id)
 	}
-	tasksMu.Unlock()
+	portFWsMu.Unlock()
+	for _, w := range ws {
+		w.stop()
+	}
+	return nil
 }
 
 //export RegisterPort
 func RegisterPort(port C.longlong) {
-	portMu.Lock()
-	registeredPort = int64(port)
-	portMu.Unlock()
-	bridge.SendStringToPort(int64(port), "registered")
+	core.RegisterPort(int64(port))
 }
 
 //export UnregisterPort
 func UnregisterPort() {
-	portMu.Lock()
-	p := registeredPort
-	registeredPort = 0
-	portMu.Unlock()
-	if p != 0 {
-		bridge.SendStringToPort(p, "unregistered")
+	if p := core.UnregisterPort(); p != 0 {
+		bridge.CloseQueue(p)
 	}
 }
 
-func getPortOrDefault(p C.longlong) int64 {
-	if int64(p) != 0 {
-		return int64(p)
-	}
-	portMu.RLock()
-	defer portMu.RUnlock()
-	return registeredPort
-}
-
-func sendToPort(port int64, r simpleResp) {
-	b, _ := json.Marshal(r)
-	bridge.SendStringToPort(port, string(b))
-}
-
-// ---- wrapper helpers ----
-func safeOp(port int64, op string, fn func() (interface{}, error)) {
-	defer func() {
-		if r := recover(); r != nil {
-			sendToPort(port, simpleResp{Op: op, Success: false, Error: fmt.Sprintf("panic: %v", r)})
-		}
-	}()
-	res, err := fn()
-	if err != nil {
-		sendToPort(port, simpleResp{Op: op, Success: false, Error: err.Error()})
-		return
-	}
-	sendToPort(port, simpleResp{Op: op, Success: true, Data: res})
-}
-
 //export BridgeInit
 func BridgeInit(api unsafe.Pointer) {
 	// panic-safe init
 	defer func() {
 		if r := recover(); r != nil {
-			port := getPortOrDefault(0)
+			port := core.PortOrDefault(0)
 			if port != 0 {
-				sendToPort(port, simpleResp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
+				core.Send(port, core.Resp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
 			}
 		}
 	}()
 	bridge.InitDartApi(api)
 }
 
+// ---- outbound queue ----
+
+// targetPort 0 configures the queue of port itself; policy is one of
+// "drop-oldest", "drop-newest" or "coalesce".
+//
+//export ConfigureQueue
+func ConfigureQueue(targetPort C.longlong, capacity C.int, policy *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	target := int64(targetPort)
+	if target == 0 {
+		target = call.Port
+	}
+	pol := C.GoString(policy)
+	call.SafeOp("configure_queue", func() (interface{}, error) {
+		dp, err := bridge.ParseDropPolicy(pol)
+		if err != nil {
+			return nil, err
+		}
+		if err := bridge.ConfigureQueue(target, bridge.QueueConfig{Capacity: int(capacity), Policy: dp}); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"port": target, "capacity": int(capacity), "policy": dp.String()}, nil
+	})
+}
+
+//export GetQueueStats
+func GetQueueStats(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("queue_stats", func() (interface{}, error) {
+		return bridge.Stats(), nil
+	})
+}
+
+// ---- logging ----
+
+// logPort 0 sends records to stderr; an empty level keeps the current one.
+//
+//export SetLogPort
+func SetLogPort(logPort C.longlong, level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_port", func() (interface{}, error) {
+		if lvl != "" {
+			l, err := core.ParseLevel(lvl)
+			if err != nil {
+				return nil, err
+			}
+			core.SetLogLevel(l)
+		}
+		core.SetLogPort(int64(logPort))
+		return map[string]interface{}{"port": int64(logPort), "level": core.LogLevel().String()}, nil
+	})
+}
+
+//export SetLogLevel
+func SetLogLevel(level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_level", func() (interface{}, error) {
+		l, err := core.ParseLevel(lvl)
+		if err != nil {
+			return nil, err
+		}
+		core.SetLogLevel(l)
+		return l.String(), nil
+	})
+}
+
+// ---- subscriptions ----
+
+// Subscribe adds subPort to topic. This library publishes "tasks", "logs"
+// and "portforward.status"; see core/topic.go. Every subscriber gets its
+// own copy of each event.
+//
+//export Subscribe
+func Subscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("subscribe", func() (interface{}, error) {
+		if err := core.Subscribe(t, int64(subPort)); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"topic": t, "subscribers": core.Subscribers(t)}, nil
+	})
+}
+
+// An empty topic removes subPort from every topic.
+//
+//export Unsubscribe
+func Unsubscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("unsubscribe", func() (interface{}, error) {
+		core.Unsubscribe(t, int64(subPort))
+		return map[string]interface{}{"topic": t, "port": int64(subPort)}, nil
+	})
+}
+
+//export ListSubscriptions
+func ListSubscriptions(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_subscriptions", func() (interface{}, error) {
+		return core.Subscriptions(), nil
+	})
+}
+
+// ---- shutdown ----
+
+// Shutdown cancels every task and stops everything the library started,
+// waiting at most timeoutMs (5s when <= 0). The reply lists what stopped
+// and what had to be abandoned.
+//
+//export Shutdown
+func Shutdown(timeoutMs C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	call.SafeOp("shutdown", func() (interface{}, error) {
+		return core.Shutdown(timeout), nil
+	})
+}
+
 // ---- Port Forwarder Exports ----
 
 //export CreatePortForwarder
-func CreatePortForwarder(urlStr *C.char, portsStr *C.char, addressStr *C.char, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func CreatePortForwarder(urlStr *C.char, portsStr *C.char, addressStr *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	goURL := C.GoString(urlStr)
 	goPorts := strings.Split(C.GoString(portsStr), ",")
 	for i, portStr := range goPorts {
@@ -169,10 +246,10 @@ func CreatePortForwarder(urlStr *C.char, portsStr *C.char, addressStr *C.char, p
 		goAddresses = []string{"localhost"} // default to localhost
 	}
 
-	safeOp(p, "create_port_forwarder", func() (interface{}, error) {
+	return C.longlong(call.Create("create_port_forwarder", func() (int64, error) {
 		u, err := url.Parse(goURL)
 		if err != nil {
-			return nil, fmt.Errorf("invalid URL: %v", err)
+			return 0, core.WithCode(core.CodeInvalidArg, fmt.Errorf("invalid URL: %v", err))
 		}
 		config := &rest.Config{
 			Host: u.Host,
@@ -183,13 +260,16 @@ func CreatePortForwarder(urlStr *C.char, portsStr *C.char, addressStr *C.char, p
 		}
 		dialer, err := portforward.NewSPDYOverWebsocketDialer(u, config)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create dialer: %v", err)
+			return 0, fmt.Errorf("failed to create dialer: %v", err)
 		}
 
+		id := atomic.AddInt64(&nextPFID, 1)
 		stopChan := make(chan struct{})
 		readyChan := make(chan struct{})
-		out := os.Stdout
-		errOut := os.Stderr
+		// client-go reports forwarding progress and errors on these writers
+		fields := map[string]interface{}{"forwarder": id}
+		out := core.LogWriter("portforward", core.LevelInfo, fields)
+		errOut := core.LogWriter("portforward", core.LevelError, fields)
 
 		var pf *portforward.PortForwarder
 		if len(goAddresses) > 0 {
@@ -198,10 +278,9 @@ func CreatePortForwarder(urlStr *C.char, portsStr *C.char, addressStr *C.char, p
 			pf, err = portforward.New(dialer, goPorts, stopChan, readyChan, out, errOut)
 		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to create port forwarder: %v", err)
+			return 0, fmt.Errorf("failed to create port forwarder: %v", err)
 		}
 
-		id := atomic.AddInt64(&nextPFID, 1)
 		wrapper := &PortForwarderWrapper{
 			PF:       pf,
 			Ready:    readyChan,
@@ -213,111 +292,158 @@ func CreatePortForwarder(urlStr *C.char, portsStr *C.char, addressStr *C.char, p
 		portFWsMu.Unlock()
 
 		return id, nil
-	})
-	return 0 // Task ID not used here; returns the PF ID in response
+	}))
 }
 
 //export StartForwardPorts
-func StartForwardPorts(pfID C.longlong, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func StartForwardPorts(pfID C.longlong, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	id := int64(pfID)
 	portFWsMu.Lock()
 	wrapper, ok := portForwarders[id]
 	portFWsMu.Unlock()
 	if !ok {
-		sendToPort(p, simpleResp{Op: "start_forward_ports", Success: false, Error: fmt.Sprintf("port forwarder %d not found", id)})
+		call.Send(core.Resp{Op: "start_forward_ports", Success: false, Error: fmt.Sprintf("port forwarder %d not found", id)})
 		return 0
 	}
 
-	_, cancel := context.WithCancel(context.Background())
-	taskID := addTask(cancel)
-
-	go func(tid int64, w *PortForwarderWrapper) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "start_forward_ports", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
+	w := wrapper
+	taskID := core.Go("start_forward_ports", call, func(ctx context.Context, tid int64) {
 		defer func() {
 			portFWsMu.Lock()
 			delete(portForwarders, w.ID)
 			portFWsMu.Unlock()
-			close(w.StopChan)
-			w.PF.Close()
+			w.stop()
+		}()
+		// StopTask cancels ctx; closing StopChan makes ForwardPorts return
+		stop := context.AfterFunc(ctx, w.stop)
+		defer stop()
+
+		go func() {
+			select {
+			case <-w.Ready:
+				ports, _ := forwardedPorts(w.PF)
+				call.Emit(core.TopicPortforwardStatus, core.Resp{Op: "portforward_ready", Success: true, Data: map[string]interface{}{"forwarder": w.ID, "ports": ports}})
+			case <-w.StopChan:
+			}
 		}()
 
 		err := w.PF.ForwardPorts()
+		status := map[string]interface{}{"forwarder": w.ID, "task": tid}
 		if err != nil {
-			sendToPort(p, simpleResp{Op: "start_forward_ports", Success: false, Error: err.Error()})
+			call.Emit(core.TopicPortforwardStatus, core.Resp{Op: "portforward_stopped", Success: false, Error: err.Error(), Data: status})
+			call.Send(core.Resp{Op: "start_forward_ports", Success: false, Error: err.Error()})
 			return
 		}
-		sendToPort(p, simpleResp{Op: "start_forward_ports", Success: true, Data: tid})
-	}(taskID, wrapper)
+		call.Emit(core.TopicPortforwardStatus, core.Resp{Op: "portforward_stopped", Success: true, Data: status})
+		call.Send(core.Resp{Op: "start_forward_ports", Success: true, Data: tid})
+	})
 
 	return C.longlong(taskID)
 }
 
 //export StopForwardPorts
-func StopForwardPorts(pfID C.longlong, port C.longlong) {
-	p := getPortOrDefault(port)
+func StopForwardPorts(pfID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	id := int64(pfID)
 	portFWsMu.Lock()
 	wrapper, ok := portForwarders[id]
 	portFWsMu.Unlock()
 	if !ok {
-		sendToPort(p, simpleResp{Op: "stop_forward_ports", Success: false, Error: fmt.Sprintf("port forwarder %d not found", id)})
+		call.Send(core.Resp{Op: "stop_forward_ports", Success: false, Error: fmt.Sprintf("port forwarder %d not found", id)})
 		return
 	}
-	close(wrapper.StopChan)
-	wrapper.PF.Close()
+	wrapper.stop()
 	portFWsMu.Lock()
 	delete(portForwarders, id)
 	portFWsMu.Unlock()
-	sendToPort(p, simpleResp{Op: "stop_forward_ports", Success: true, Data: id})
+	call.Send(core.Resp{Op: "stop_forward_ports", Success: true, Data: id})
 }
 
 //export GetForwardedPorts
-func GetForwardedPorts(pfID C.longlong, port C.longlong) {
-	p := getPortOrDefault(port)
+func GetForwardedPorts(pfID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	id := int64(pfID)
 	portFWsMu.Lock()
 	wrapper, ok := portForwarders[id]
 	portFWsMu.Unlock()
 	if !ok {
-		sendToPort(p, simpleResp{Op: "get_forwarded_ports", Success: false, Error: fmt.Sprintf("port forwarder %d not found", id)})
+		call.Send(core.Resp{Op: "get_forwarded_ports", Success: false, Error: fmt.Sprintf("port forwarder %d not found", id)})
 		return
 	}
-	safeOp(p, "get_forwarded_ports", func() (interface{}, error) {
-		ports, err := wrapper.PF.GetPorts()
-		if err != nil {
-			return nil, err
-		}
-		var res []map[string]uint16
-		for _, port := range ports {
-			res = append(res, map[string]uint16{"local": port.Local, "remote": port.Remote})
-		}
-		return res, nil
+	call.SafeOp("get_forwarded_ports", func() (interface{}, error) {
+		return forwardedPorts(wrapper.PF)
 	})
 }
 
-//export StopTask
-func StopTask(taskID C.longlong, port C.longlong) {
-	id := int64(taskID)
-	p := getPortOrDefault(port)
-	tasksMu.Lock()
-	entry, ok := tasks[id]
-	if ok {
-		delete(tasks, id)
+func forwardedPorts(pf *portforward.PortForwarder) ([]map[string]uint16, error) {
+	ports, err := pf.GetPorts()
+	if err != nil {
+		return nil, err
+	}
+	var res []map[string]uint16
+	for _, port := range ports {
+		res = append(res, map[string]uint16{"local": port.Local, "remote": port.Remote})
 	}
-	tasksMu.Unlock()
+	return res, nil
+}
+
+// ---- errors ----
+
+// GetLastError returns the message behind a negative code returned by a
+// Create* call made with reqID, or NULL. Free it with FreeString. reqID 0
+// reads the latest failure among all calls made without a request id.
+//
+//export GetLastError
+func GetLastError(reqID C.longlong) *C.char {
+	msg, ok := core.LastError(int64(reqID))
 	if !ok {
-		sendToPort(p, simpleResp{Op: "stop", Success: false, Error: fmt.Sprintf("task %d not found", id)})
+		return nil
+	}
+	return C.CString(msg)
+}
+
+//export FreeString
+func FreeString(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// ---- task registry ----
+
+// StopTask cancels a task and waits up to core.StopTimeout for it. A task
+// that does not stop by then is abandoned and the reply says forced.
+//
+//export StopTask
+func StopTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
+	id := int64(taskID)
+	call := core.NewCall(int64(port), int64(reqID))
+	forced, err := core.StopTask(id, core.StopTimeout)
+	if err != nil {
+		call.Send(core.Resp{Op: "stop", Success: false, Error: err.Error()})
 		return
 	}
-	entry.cancel()
-	<-entry.done
-	sendToPort(p, simpleResp{Op: "stop", Success: true, Data: id})
+	call.Send(core.Resp{Op: "stop", Success: true, Data: map[string]interface{}{"id": id, "forced": forced}})
+}
+
+//export ListTasks
+func ListTasks(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_tasks", func() (interface{}, error) {
+		return core.ListTasks(), nil
+	})
+}
+
+//export GetTask
+func GetTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
+	id := int64(taskID)
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_task", func() (interface{}, error) {
+		info, ok := core.GetTask(id)
+		if !ok {
+			return nil, fmt.Errorf("task %d not found", id)
+		}
+		return info, nil
+	})
 }
 
-func main() {}
\ No newline at end of file
+func main() {}
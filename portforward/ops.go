@@ -0,0 +1,103 @@
+package main
+
+import "C"
+import (
+	core "core"
+	bridge "core/bridge"
+)
+
+// Invoke runs one export by name, as core.Invoke describes. For example
+//
+//	{"method": "CreatePortForwarder", "id": 7, "params": {"urlStr": "https://kubernetes.default.svc", "portsStr": "8080:80"}}
+//
+// creates a forwarder and returns its id, or a negative error code.
+//
+//export Invoke
+func Invoke(requestJSON *C.char, port C.longlong) C.longlong {
+	return C.longlong(core.Invoke([]byte(C.GoString(requestJSON)), int64(port)))
+}
+
+//export ListOperations
+func ListOperations(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_operations", func() (interface{}, error) {
+		return core.Operations(), nil
+	})
+}
+
+func init() {
+	core.Register(core.Operation{Method: "ConfigureQueue", Params: []core.Param{core.IntParam("targetPort"), core.IntParam("capacity"), core.StrParam("policy")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ConfigureQueue(C.longlong(a.Int64("targetPort")), C.int(a.Int("capacity")), (*C.char)(cs.Str(a.String("policy"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetQueueStats"}, func(call core.Call, a core.Args) int64 {
+		GetQueueStats(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogPort", Params: []core.Param{core.IntParam("logPort"), core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogPort(C.longlong(a.Int64("logPort")), (*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogLevel", Params: []core.Param{core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogLevel((*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Subscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Subscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Unsubscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Unsubscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListSubscriptions"}, func(call core.Call, a core.Args) int64 {
+		ListSubscriptions(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Shutdown", Params: []core.Param{core.IntParam("timeoutMs")}}, func(call core.Call, a core.Args) int64 {
+		Shutdown(C.int(a.Int("timeoutMs")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CreatePortForwarder", Params: []core.Param{core.StrParam("urlStr"), core.StrParam("portsStr"), core.StrParam("addressStr").Opt()}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreatePortForwarder((*C.char)(cs.Str(a.String("urlStr"))), (*C.char)(cs.Str(a.String("portsStr"))), (*C.char)(cs.Str(a.String("addressStr"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "StartForwardPorts", Params: []core.Param{core.IntParam("pfID")}, Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		return int64(StartForwardPorts(C.longlong(a.Int64("pfID")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "StopForwardPorts", Params: []core.Param{core.IntParam("pfID")}}, func(call core.Call, a core.Args) int64 {
+		StopForwardPorts(C.longlong(a.Int64("pfID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetForwardedPorts", Params: []core.Param{core.IntParam("pfID")}}, func(call core.Call, a core.Args) int64 {
+		GetForwardedPorts(C.longlong(a.Int64("pfID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "StopTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		StopTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListTasks"}, func(call core.Call, a core.Args) int64 {
+		ListTasks(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		GetTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListOperations"}, func(call core.Call, a core.Args) int64 {
+		ListOperations(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+}
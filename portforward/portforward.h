@@ -24,10 +24,12 @@ extern const char *_GoStringPtr(_GoString_ s);
 #line 3 "main.go"
 
 #include <stdint.h>
+#include <stdlib.h>
 
 #line 1 "cgo-generated-wrapper"
 
 
+
 /* End of preamble from import "C" comments.  */
 
 
@@ -90,11 +92,25 @@ extern "C" {
 extern void RegisterPort(long long int port);
 extern void UnregisterPort(void);
 extern void BridgeInit(void* api);
-extern long long int CreatePortForwarder(char* urlStr, char* portsStr, char* addressStr, long long int port);
-extern long long int StartForwardPorts(long long int pfID, long long int port);
-extern void StopForwardPorts(long long int pfID, long long int port);
-extern void GetForwardedPorts(long long int pfID, long long int port);
-extern void StopTask(long long int taskID, long long int port);
+extern void ConfigureQueue(long long int targetPort, int capacity, char* policy, long long int reqID, long long int port);
+extern void GetQueueStats(long long int reqID, long long int port);
+extern void SetLogPort(long long int logPort, char* level, long long int reqID, long long int port);
+extern void SetLogLevel(char* level, long long int reqID, long long int port);
+extern void Subscribe(char* topic, long long int subPort, long long int reqID, long long int port);
+extern void Unsubscribe(char* topic, long long int subPort, long long int reqID, long long int port);
+extern void ListSubscriptions(long long int reqID, long long int port);
+extern void Shutdown(int timeoutMs, long long int reqID, long long int port);
+extern long long int CreatePortForwarder(char* urlStr, char* portsStr, char* addressStr, long long int reqID, long long int port);
+extern long long int StartForwardPorts(long long int pfID, long long int reqID, long long int port);
+extern void StopForwardPorts(long long int pfID, long long int reqID, long long int port);
+extern void GetForwardedPorts(long long int pfID, long long int reqID, long long int port);
+extern char* GetLastError(long long int reqID);
+extern void FreeString(char* s);
+extern void StopTask(long long int taskID, long long int reqID, long long int port);
+extern void ListTasks(long long int reqID, long long int port);
+extern void GetTask(long long int taskID, long long int reqID, long long int port);
+extern long long int Invoke(char* requestJSON, long long int port);
+extern void ListOperations(long long int reqID, long long int port);
 
 #ifdef __cplusplus
 }
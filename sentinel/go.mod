@@ -3,7 +3,7 @@ module sentinel
 go 1.25.4
 
 require (
-	bridge v0.0.0-00010101000000-000000000000
+	core v0.0.0-00010101000000-000000000000
 	github.com/coreos/go-systemd/v22 v22.6.0
 	github.com/go-vgo/robotgo v1.0.0
 	github.com/gorilla/websocket v1.5.3
@@ -40,4 +40,5 @@ require (
 	golang.org/x/sys v0.38.0 // indirect
 )
 
-replace bridge => ./bridge
+replace core => ../core
+
@@ -17,11 +17,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 	"unsafe"
 
-	bridge "bridge"
+	core "core"
+	bridge "core/bridge"
 
 	"github.com/coreos/go-systemd/v22/dbus"
 	"github.com/go-vgo/robotgo"
@@ -236,106 +236,176 @@ var (
 
 var Special = keycode.Special
 
-type taskRec struct {
-	cancel context.CancelFunc
-	done   chan struct{}
-}
-
-var (
-	tasks    = map[int64]*taskRec{}
-	tasksMu  sync.Mutex
-	nextTask int64 // start from 0; atomic increment gives 1,2,...
-)
-var (
-	registeredPort int64
-	portMu         sync.RWMutex
-)
-
-type simpleResp struct {
-	Op      string      `json:"op"`
-	Success bool        `json:"success"`
-	Error   string      `json:"error,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-}
-
-func addTask(cancel context.CancelFunc) int64 {
-	id := atomic.AddInt64(&nextTask, 1)
-	entry := &taskRec{
-		cancel: cancel,
-		done:   make(chan struct{}),
-	}
-	tasksMu.Lock()
-	tasks[id] = entry
-	tasksMu.Unlock()
-	return id
+func init() {
+	core.SetPoster(bridge.SendKeyedStringToPort)
+	bridge.Logger = core.BridgeLogger
+	core.CaptureStdLog()
+	// monitors and smooth moves are tasks and stop with them
+	core.OnShutdown("input hook", func(ctx context.Context) []string {
+		hookStartMu.Lock()
+		started := hookStarted
+		hookStartMu.Unlock()
+		if started {
+			HookStop()
+		}
+		return nil
+	})
 }
 
 //export RegisterPort
 func RegisterPort(port C.longlong) {
-	portMu.Lock()
-	registeredPort = int64(port)
-	portMu.Unlock()
-	bridge.SendStringToPort(int64(port), "registered")
+	core.RegisterPort(int64(port))
 }
 
 //export UnregisterPort
 func UnregisterPort() {
-	portMu.Lock()
-	p := registeredPort
-	registeredPort = 0
-	portMu.Unlock()
-	if p != 0 {
-		bridge.SendStringToPort(p, "unregistered")
+	if p := core.UnregisterPort(); p != 0 {
+		bridge.CloseQueue(p)
 	}
 }
 
-func getPortOrDefault(p C.longlong) int64 {
-	if int64(p) != 0 {
-		return int64(p)
-	}
-	portMu.RLock()
-	defer portMu.RUnlock()
-	return registeredPort
-}
-
-func sendToPort(port int64, r simpleResp) {
-	b, _ := json.Marshal(r)
-	bridge.SendStringToPort(port, string(b))
-}
-
-// ---- wrapper helpers ----
-func safeOp(port int64, op string, fn func() (interface{}, error)) {
-	defer func() {
-		if r := recover(); r != nil {
-			sendToPort(port, simpleResp{Op: op, Success: false, Error: fmt.Sprintf("panic: %v", r)})
-		}
-	}()
-	res, err := fn()
-	if err != nil {
-		sendToPort(port, simpleResp{Op: op, Success: false, Error: err.Error()})
-		return
-	}
-	sendToPort(port, simpleResp{Op: op, Success: true, Data: res})
-}
-
 //export BridgeInit
 func BridgeInit(api unsafe.Pointer) {
 	// panic-safe init
 	defer func() {
 		if r := recover(); r != nil {
-			port := getPortOrDefault(0)
+			port := core.PortOrDefault(0)
 			if port != 0 {
-				sendToPort(port, simpleResp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
+				core.Send(port, core.Resp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
 			}
 		}
 	}()
 	bridge.InitDartApi(api)
 }
 
+// ---- outbound queue ----
+
+// targetPort 0 configures the queue of port itself; policy is one of
+// "drop-oldest", "drop-newest" or "coalesce".
+//
+//export ConfigureQueue
+func ConfigureQueue(targetPort C.longlong, capacity C.int, policy *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	target := int64(targetPort)
+	if target == 0 {
+		target = call.Port
+	}
+	pol := C.GoString(policy)
+	call.SafeOp("configure_queue", func() (interface{}, error) {
+		dp, err := bridge.ParseDropPolicy(pol)
+		if err != nil {
+			return nil, err
+		}
+		if err := bridge.ConfigureQueue(target, bridge.QueueConfig{Capacity: int(capacity), Policy: dp}); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"port": target, "capacity": int(capacity), "policy": dp.String()}, nil
+	})
+}
+
+//export GetQueueStats
+func GetQueueStats(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("queue_stats", func() (interface{}, error) {
+		return bridge.Stats(), nil
+	})
+}
+
+// ---- logging ----
+
+// logPort 0 sends records to stderr; an empty level keeps the current one.
+//
+//export SetLogPort
+func SetLogPort(logPort C.longlong, level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_port", func() (interface{}, error) {
+		if lvl != "" {
+			l, err := core.ParseLevel(lvl)
+			if err != nil {
+				return nil, err
+			}
+			core.SetLogLevel(l)
+		}
+		core.SetLogPort(int64(logPort))
+		return map[string]interface{}{"port": int64(logPort), "level": core.LogLevel().String()}, nil
+	})
+}
+
+//export SetLogLevel
+func SetLogLevel(level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_level", func() (interface{}, error) {
+		l, err := core.ParseLevel(lvl)
+		if err != nil {
+			return nil, err
+		}
+		core.SetLogLevel(l)
+		return l.String(), nil
+	})
+}
+
+// ---- subscriptions ----
+
+// Subscribe adds subPort to topic. This library publishes "tasks", "logs",
+// "sentinel.hook" and "sentinel.monitor"; see core/topic.go. Every
+// subscriber gets its own copy of each event.
+//
+//export Subscribe
+func Subscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("subscribe", func() (interface{}, error) {
+		if err := core.Subscribe(t, int64(subPort)); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"topic": t, "subscribers": core.Subscribers(t)}, nil
+	})
+}
+
+// An empty topic removes subPort from every topic.
+//
+//export Unsubscribe
+func Unsubscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("unsubscribe", func() (interface{}, error) {
+		core.Unsubscribe(t, int64(subPort))
+		return map[string]interface{}{"topic": t, "port": int64(subPort)}, nil
+	})
+}
+
+//export ListSubscriptions
+func ListSubscriptions(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_subscriptions", func() (interface{}, error) {
+		return core.Subscriptions(), nil
+	})
+}
+
+// ---- shutdown ----
+
+// Shutdown cancels every task and stops everything the library started,
+// waiting at most timeoutMs (5s when <= 0). The reply lists what stopped
+// and what had to be abandoned.
+//
+//export Shutdown
+func Shutdown(timeoutMs C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	call.SafeOp("shutdown", func() (interface{}, error) {
+		return core.Shutdown(timeout), nil
+	})
+}
+
 //export Move
-func Move(x C.int, y C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "move", func() (interface{}, error) {
+func Move(x C.int, y C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("move", func() (interface{}, error) {
 		robotgo.Move(int(x), int(y))
 		lx, ly := robotgo.Location()
 		return map[string]int{"x": lx, "y": ly}, nil
@@ -343,9 +413,9 @@ func Move(x C.int, y C.int, port C.longlong) {
 }
 
 //export MoveRelative
-func MoveRelative(x C.int, y C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "move_relative", func() (interface{}, error) {
+func MoveRelative(x C.int, y C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("move_relative", func() (interface{}, error) {
 		robotgo.MoveRelative(int(x), int(y))
 		lx, ly := robotgo.Location()
 		return map[string]int{"x": lx, "y": ly}, nil
@@ -353,10 +423,10 @@ func MoveRelative(x C.int, y C.int, port C.longlong) {
 }
 
 //export Click
-func Click(btn *C.char, dbl C.int, port C.longlong) {
-	p := getPortOrDefault(port)
+func Click(btn *C.char, dbl C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	button := C.GoString(btn)
-	safeOp(p, "click", func() (interface{}, error) {
+	call.SafeOp("click", func() (interface{}, error) {
 		if dbl != 0 {
 			robotgo.Click(button, true)
 		} else {
@@ -367,14 +437,14 @@ func Click(btn *C.char, dbl C.int, port C.longlong) {
 }
 
 //export Toggle
-func Toggle(btn *C.char, dir *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func Toggle(btn *C.char, dir *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	button := C.GoString(btn)
 	var direction string
 	if dir != nil {
 		direction = C.GoString(dir)
 	}
-	safeOp(p, "toggle", func() (interface{}, error) {
+	call.SafeOp("toggle", func() (interface{}, error) {
 		if direction == "" {
 			robotgo.Toggle(button)
 			return button, nil
@@ -385,94 +455,56 @@ func Toggle(btn *C.char, dir *C.char, port C.longlong) {
 }
 
 //export Scroll
-func Scroll(x C.int, y C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "scroll", func() (interface{}, error) {
+func Scroll(x C.int, y C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("scroll", func() (interface{}, error) {
 		robotgo.Scroll(int(x), int(y))
 		return map[string]int{"x": int(x), "y": int(y)}, nil
 	})
 }
 
 //export ScrollDir
-func ScrollDir(amount C.int, dir *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func ScrollDir(amount C.int, dir *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	goDir := C.GoString(dir)
-	safeOp(p, "scrolldir", func() (interface{}, error) {
+	call.SafeOp("scrolldir", func() (interface{}, error) {
 		robotgo.ScrollDir(int(amount), goDir)
 		return map[string]interface{}{"amount": int(amount), "dir": goDir}, nil
 	})
 }
 
 //export GetLocation
-func GetLocation(port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "location", func() (interface{}, error) {
+func GetLocation(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("location", func() (interface{}, error) {
 		lx, ly := robotgo.Location()
 		return map[string]int{"x": lx, "y": ly}, nil
 	})
 }
 
 //export SetMouseSleep
-func SetMouseSleep(ms C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "set_mouse_sleep", func() (interface{}, error) {
+func SetMouseSleep(ms C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("set_mouse_sleep", func() (interface{}, error) {
 		robotgo.MouseSleep = int(ms)
 		return robotgo.MouseSleep, nil
 	})
 }
 
 //export MilliSleep
-func MilliSleep(ms C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "milli_sleep", func() (interface{}, error) {
+func MilliSleep(ms C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("milli_sleep", func() (interface{}, error) {
 		time.Sleep(time.Duration(ms) * time.Millisecond)
 		return ms, nil
 	})
 }
-func finishTask(id int64) {
-	tasksMu.Lock()
-	if e, ok := tasks[id]; ok {
-		close(e.done)
-		delete(tasks, id)
-	}
-	tasksMu.Unlock()
-}
-
-//export StopTask
-func StopTask(taskID C.longlong, port C.longlong) {
-	id := int64(taskID)
-	p := getPortOrDefault(port)
-	tasksMu.Lock()
-	entry, ok := tasks[id]
-	if ok {
-		delete(tasks, id)
-	}
-	tasksMu.Unlock()
-	if !ok {
-		sendToPort(p, simpleResp{Op: "stop", Success: false, Error: fmt.Sprintf("task %d not found", id)})
-		return
-	}
-	entry.cancel()
-	<-entry.done
-	sendToPort(p, simpleResp{Op: "stop", Success: true, Data: id})
-}
-
 // ---- cancellable / long-running ops (return task id) ----
 //
 //export MoveSmoothStart
-func MoveSmoothStart(x C.int, y C.int, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
-	ctx, cancel := context.WithCancel(context.Background())
-	id := addTask(cancel)
-
-	go func(tid int64) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "move_smooth", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
-
+func MoveSmoothStart(x C.int, y C.int, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	id := core.Go("move_smooth", call, func(ctx context.Context, tid int64) {
 		startX, startY := robotgo.GetMousePos()
 		tx := int(x)
 		ty := int(y)
@@ -493,7 +525,7 @@ func MoveSmoothStart(x C.int, y C.int, port C.longlong) C.longlong {
 		for i := 1; i <= steps; i++ {
 			select {
 			case <-ctx.Done():
-				sendToPort(p, simpleResp{Op: "move_smooth", Success: false, Error: "canceled", Data: tid})
+				call.Send(core.Resp{Op: "move_smooth", Success: false, Error: "canceled", Data: tid})
 				return
 			default:
 				nx := int(math.Round(float64(startX) + dx*float64(i)))
@@ -505,26 +537,16 @@ func MoveSmoothStart(x C.int, y C.int, port C.longlong) C.longlong {
 		// Ensure exact final position
 		robotgo.Move(tx, ty)
 		lx, ly := robotgo.Location()
-		sendToPort(p, simpleResp{Op: "move_smooth", Success: true, Data: map[string]int{"x": lx, "y": ly}})
-	}(id)
+		call.Send(core.Resp{Op: "move_smooth", Success: true, Data: map[string]int{"x": lx, "y": ly}})
+	})
 
 	return C.longlong(id)
 }
 
 //export DragSmoothStart
-func DragSmoothStart(x C.int, y C.int, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
-	ctx, cancel := context.WithCancel(context.Background())
-	id := addTask(cancel)
-
-	go func(tid int64) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "drag_smooth", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
-
+func DragSmoothStart(x C.int, y C.int, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	id := core.Go("drag_smooth", call, func(ctx context.Context, tid int64) {
 		robotgo.Toggle("down")
 		startX, startY := robotgo.GetMousePos()
 		tx := int(x)
@@ -546,7 +568,7 @@ func DragSmoothStart(x C.int, y C.int, port C.longlong) C.longlong {
 			select {
 			case <-ctx.Done():
 				robotgo.Toggle("up")
-				sendToPort(p, simpleResp{Op: "drag_smooth", Success: false, Error: "canceled", Data: tid})
+				call.Send(core.Resp{Op: "drag_smooth", Success: false, Error: "canceled", Data: tid})
 				return
 			default:
 				nx := int(math.Round(float64(startX) + dx*float64(i)))
@@ -558,50 +580,40 @@ func DragSmoothStart(x C.int, y C.int, port C.longlong) C.longlong {
 		robotgo.Move(tx, ty)
 		robotgo.Toggle("up")
 		lx, ly := robotgo.Location()
-		sendToPort(p, simpleResp{Op: "drag_smooth", Success: true, Data: map[string]int{"x": lx, "y": ly}})
-	}(id)
+		call.Send(core.Resp{Op: "drag_smooth", Success: true, Data: map[string]int{"x": lx, "y": ly}})
+	})
 
 	return C.longlong(id)
 }
 
 //export ScrollSmoothStart
-func ScrollSmoothStart(x C.int, y C.int, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
-	ctx, cancel := context.WithCancel(context.Background())
-	id := addTask(cancel)
-
-	go func(tid int64) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "scroll_smooth", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
-
+func ScrollSmoothStart(x C.int, y C.int, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	id := core.Go("scroll_smooth", call, func(ctx context.Context, tid int64) {
 		steps := 20
 		dx := int(x) / steps
 		dy := int(y) / steps
 		for i := 0; i < steps; i++ {
 			select {
 			case <-ctx.Done():
-				sendToPort(p, simpleResp{Op: "scroll_smooth", Success: false, Error: "canceled", Data: tid})
+				call.Send(core.Resp{Op: "scroll_smooth", Success: false, Error: "canceled", Data: tid})
 				return
 			default:
 				robotgo.Scroll(dx, dy)
 				time.Sleep(10 * time.Millisecond)
 			}
 		}
-		sendToPort(p, simpleResp{Op: "scroll_smooth", Success: true, Data: map[string]int{"x": int(x), "y": int(y)}})
-	}(id)
+		call.Send(core.Resp{Op: "scroll_smooth", Success: true, Data: map[string]int{"x": int(x), "y": int(y)}})
+	})
 
 	return C.longlong(id)
 }
 
 //export TypeStr
-func TypeStr(text *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func TypeStr(text *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	s := C.GoString(text)
-	safeOp(p, "type_str", func() (interface{}, error) {
+	call.SafeOp("type_str", func() (interface{}, error) {
 		robotgo.TypeStr(s)
 		return nil, nil
 	})
@@ -610,32 +622,32 @@ func TypeStr(text *C.char, port C.longlong) {
 // allows calling robotgo.TypeStr(s, int1, int2) (some usages pass additional ints)
 //
 //export TypeStrWithInts
-func TypeStrWithInts(text *C.char, arg1 C.int, arg2 C.int, port C.longlong) {
-	p := getPortOrDefault(port)
+func TypeStrWithInts(text *C.char, arg1 C.int, arg2 C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	s := C.GoString(text)
 	a1 := int(arg1)
 	a2 := int(arg2)
-	safeOp(p, "type_str_with_ints", func() (interface{}, error) {
+	call.SafeOp("type_str_with_ints", func() (interface{}, error) {
 		robotgo.TypeStr(s, a1, a2)
 		return map[string]int{"arg1": a1, "arg2": a2}, nil
 	})
 }
 
 //export GoSleep
-func GoSleep(seconds C.int, port C.longlong) {
-	p := getPortOrDefault(port)
+func GoSleep(seconds C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	secs := int(seconds)
-	safeOp(p, "sleep", func() (interface{}, error) {
+	call.SafeOp("sleep", func() (interface{}, error) {
 		robotgo.Sleep(secs)
 		return secs, nil
 	})
 }
 
 //export SetKeySleep
-func SetKeySleep(ms C.int, port C.longlong) {
-	p := getPortOrDefault(port)
+func SetKeySleep(ms C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	n := int(ms)
-	safeOp(p, "set_key_sleep", func() (interface{}, error) {
+	call.SafeOp("set_key_sleep", func() (interface{}, error) {
 		robotgo.KeySleep = n
 		return robotgo.KeySleep, nil
 	})
@@ -644,8 +656,8 @@ func SetKeySleep(ms C.int, port C.longlong) {
 // mods: comma separated modifiers string like "alt,cmd" or NULL for none
 //
 //export KeyTap
-func KeyTap(key *C.char, mods *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func KeyTap(key *C.char, mods *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	k := C.GoString(key)
 	var modsSlice []interface{}
 	if mods != nil {
@@ -662,7 +674,7 @@ func KeyTap(key *C.char, mods *C.char, port C.longlong) {
 			}
 		}
 	}
-	safeOp(p, "key_tap", func() (interface{}, error) {
+	call.SafeOp("key_tap", func() (interface{}, error) {
 		if len(modsSlice) == 0 {
 			robotgo.KeyTap(k)
 		} else {
@@ -673,15 +685,15 @@ func KeyTap(key *C.char, mods *C.char, port C.longlong) {
 }
 
 //export KeyTapArr
-func KeyTapArr(key *C.char, modsJson *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func KeyTapArr(key *C.char, modsJson *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	k := C.GoString(key)
 	var modsSlice []interface{}
 	if modsJson != nil {
 		js := C.GoString(modsJson)
 		_ = json.Unmarshal([]byte(js), &modsSlice) // ignore error -> empty slice if malformed
 	}
-	safeOp(p, "key_tap_arr", func() (interface{}, error) {
+	call.SafeOp("key_tap_arr", func() (interface{}, error) {
 		if len(modsSlice) == 0 {
 			robotgo.KeyTap(k)
 		} else {
@@ -694,14 +706,14 @@ func KeyTapArr(key *C.char, modsJson *C.char, port C.longlong) {
 // direction: NULL for default, or "up" / "down"
 //
 //export KeyToggle
-func KeyToggle(key *C.char, direction *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func KeyToggle(key *C.char, direction *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	k := C.GoString(key)
 	dir := ""
 	if direction != nil {
 		dir = C.GoString(direction)
 	}
-	safeOp(p, "key_toggle", func() (interface{}, error) {
+	call.SafeOp("key_toggle", func() (interface{}, error) {
 		if dir == "" {
 			robotgo.KeyToggle(k)
 			return map[string]string{"key": k}, nil
@@ -712,19 +724,19 @@ func KeyToggle(key *C.char, direction *C.char, port C.longlong) {
 }
 
 //export WriteAll
-func WriteAll(text *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func WriteAll(text *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	s := C.GoString(text)
-	safeOp(p, "write_all", func() (interface{}, error) {
+	call.SafeOp("write_all", func() (interface{}, error) {
 		robotgo.WriteAll(s)
 		return s, nil
 	})
 }
 
 //export ReadAll
-func ReadAll(port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "read_all", func() (interface{}, error) {
+func ReadAll(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("read_all", func() (interface{}, error) {
 		txt, err := robotgo.ReadAll()
 		if err != nil {
 			return nil, err
@@ -734,49 +746,39 @@ func ReadAll(port C.longlong) {
 }
 
 //export TypeStrStart
-func TypeStrStart(text *C.char, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func TypeStrStart(text *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	s := C.GoString(text)
-	ctx, cancel := context.WithCancel(context.Background())
-	id := addTask(cancel)
-
-	go func(tid int64, c context.Context) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "type_str_async", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
-
+	id := core.Go("type_str_async", call, func(c context.Context, tid int64) {
 		for _, r := range s {
 			select {
 			case <-c.Done():
-				sendToPort(p, simpleResp{Op: "type_str_async", Success: false, Error: "canceled", Data: tid})
+				call.Send(core.Resp{Op: "type_str_async", Success: false, Error: "canceled", Data: tid})
 				return
 			default:
 				robotgo.TypeStr(string(r))
 				time.Sleep(time.Duration(robotgo.KeySleep) * time.Millisecond)
 			}
 		}
-		sendToPort(p, simpleResp{Op: "type_str_async", Success: true, Data: s})
-	}(id, ctx)
+		call.Send(core.Resp{Op: "type_str_async", Success: true, Data: s})
+	})
 
 	return C.longlong(id)
 }
 
 //export GetPixelColor
-func GetPixelColor(x C.int, y C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "get_pixel_color", func() (interface{}, error) {
+func GetPixelColor(x C.int, y C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_pixel_color", func() (interface{}, error) {
 		col := robotgo.GetPixelColor(int(x), int(y))
 		return map[string]interface{}{"x": int(x), "y": int(y), "color": col}, nil
 	})
 }
 
 //export GetScreenSize
-func GetScreenSize(port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "get_screen_size", func() (interface{}, error) {
+func GetScreenSize(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_screen_size", func() (interface{}, error) {
 		sx, sy := robotgo.GetScreenSize()
 		return map[string]int{"width": sx, "height": sy}, nil
 	})
@@ -785,9 +787,9 @@ func GetScreenSize(port C.longlong) {
 // path: file path to save (PNG). If path is NULL, generates a temp file and returns its path.
 
 //export CaptureScreenSave
-func CaptureScreenSave(x C.int, y C.int, w C.int, h C.int, path *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "capture_screen_save", func() (interface{}, error) {
+func CaptureScreenSave(x C.int, y C.int, w C.int, h C.int, path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("capture_screen_save", func() (interface{}, error) {
 		bit := robotgo.CaptureScreen(int(x), int(y), int(w), int(h))
 		if bit == nil {
 			return nil, fmt.Errorf("capture returned nil bitmap")
@@ -822,42 +824,70 @@ func CaptureScreenSave(x C.int, y C.int, w C.int, h C.int, path *C.char, port C.
 // returns a base64-encoded PNG of the captured region in the "data" response field.
 //
 //export CaptureScreenBase64
-func CaptureScreenBase64(x C.int, y C.int, w C.int, h C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "capture_screen_base64", func() (interface{}, error) {
-		bit := robotgo.CaptureScreen(int(x), int(y), int(w), int(h))
-		if bit == nil {
-			return nil, fmt.Errorf("capture returned nil bitmap")
-		}
-		defer robotgo.FreeBitmap(bit)
-
-		img := robotgo.ToImage(bit)
-		if img == nil {
-			return nil, fmt.Errorf("failed to convert bitmap to image")
-		}
-
-		var buf bytes.Buffer
-		if err := png.Encode(&buf, img); err != nil {
+func CaptureScreenBase64(x C.int, y C.int, w C.int, h C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("capture_screen_base64", func() (interface{}, error) {
+		b, err := capturePNG(int(x), int(y), int(w), int(h))
+		if err != nil {
 			return nil, err
 		}
-		encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+		encoded := base64.StdEncoding.EncodeToString(b)
 		return map[string]string{"base64_png": encoded}, nil
 	})
 }
 
+// posts the captured region as a binary frame (op "capture_screen_png", kind PNG)
+// instead of base64 JSON; errors still arrive as a JSON response.
+//
+//export CaptureScreenPNG
+func CaptureScreenPNG(x C.int, y C.int, w C.int, h C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	defer func() {
+		if r := recover(); r != nil {
+			call.Send(core.Resp{Op: "capture_screen_png", Success: false, Error: fmt.Sprintf("panic: %v", r)})
+		}
+	}()
+	b, err := capturePNG(int(x), int(y), int(w), int(h))
+	if err != nil {
+		call.Send(core.Resp{Op: "capture_screen_png", Success: false, Error: err.Error()})
+		return
+	}
+	bridge.SendFrameToPort(call.Port, bridge.FrameHeader{Op: "capture_screen_png", RequestID: call.RequestID, Kind: bridge.PayloadPNG}, b)
+}
+
+// captures a screen region and encodes it as PNG
+func capturePNG(x, y, w, h int) ([]byte, error) {
+	bit := robotgo.CaptureScreen(x, y, w, h)
+	if bit == nil {
+		return nil, fmt.Errorf("capture returned nil bitmap")
+	}
+	defer robotgo.FreeBitmap(bit)
+
+	img := robotgo.ToImage(bit)
+	if img == nil {
+		return nil, fmt.Errorf("failed to convert bitmap to image")
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 //export DisplaysNum
-func DisplaysNum(port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "displays_num", func() (interface{}, error) {
+func DisplaysNum(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("displays_num", func() (interface{}, error) {
 		n := robotgo.DisplaysNum()
 		return n, nil
 	})
 }
 
 //export GetDisplayBounds
-func GetDisplayBounds(index C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "get_display_bounds", func() (interface{}, error) {
+func GetDisplayBounds(index C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_display_bounds", func() (interface{}, error) {
 		x, y, w, h := robotgo.GetDisplayBounds(int(index))
 		return map[string]int{"index": int(index), "x": x, "y": y, "w": w, "h": h}, nil
 	})
@@ -866,9 +896,9 @@ func GetDisplayBounds(index C.int, port C.longlong) {
 // capture entire display specified by index and save to given path (if path NULL, create temp file)
 //
 //export CaptureDisplaySave
-func CaptureDisplaySave(index C.int, path *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "capture_display_save", func() (interface{}, error) {
+func CaptureDisplaySave(index C.int, path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("capture_display_save", func() (interface{}, error) {
 		robotgo.DisplayID = int(index)
 		img, err := robotgo.CaptureImg()
 		if err != nil || img == nil {
@@ -897,9 +927,9 @@ func CaptureDisplaySave(index C.int, path *C.char, port C.longlong) {
 // capture specific region on current display (or after setting DisplayID) and save
 //
 //export CaptureDisplayRegionSave
-func CaptureDisplayRegionSave(index C.int, x C.int, y C.int, w C.int, h C.int, path *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "capture_display_region_save", func() (interface{}, error) {
+func CaptureDisplayRegionSave(index C.int, x C.int, y C.int, w C.int, h C.int, path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("capture_display_region_save", func() (interface{}, error) {
 		robotgo.DisplayID = int(index)
 		img, err := robotgo.CaptureImg(int(x), int(y), int(w), int(h))
 		if err != nil || img == nil {
@@ -929,9 +959,9 @@ func CaptureDisplayRegionSave(index C.int, x C.int, y C.int, w C.int, h C.int, p
 // Here we accept path and quality; we capture entire display at robotgo.DisplayID.
 //
 //export SaveImageJpeg
-func SaveImageJpeg(path *C.char, quality C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "save_image_jpeg", func() (interface{}, error) {
+func SaveImageJpeg(path *C.char, quality C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("save_image_jpeg", func() (interface{}, error) {
 		goPath := C.GoString(path)
 		if goPath == "" {
 			return nil, fmt.Errorf("path is empty")
@@ -950,9 +980,9 @@ func SaveImageJpeg(path *C.char, quality C.int, port C.longlong) {
 // capture current display and save PNG to path
 //
 //export SaveImagePNGFromCaptureImg
-func SaveImagePNGFromCaptureImg(path *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "save_image_png_from_capture", func() (interface{}, error) {
+func SaveImagePNGFromCaptureImg(path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("save_image_png_from_capture", func() (interface{}, error) {
 		goPath := C.GoString(path)
 		if goPath == "" {
 			return nil, fmt.Errorf("path is empty")
@@ -973,9 +1003,9 @@ var _ = unsafe.Pointer(nil)
 
 
 //export SaveBitmapToFile
-func SaveBitmapToFile(x C.int, y C.int, w C.int, h C.int, path *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "save_bitmap_to_file", func() (interface{}, error) {
+func SaveBitmapToFile(x C.int, y C.int, w C.int, h C.int, path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("save_bitmap_to_file", func() (interface{}, error) {
 		bit := robotgo.CaptureScreen(int(x), int(y), int(w), int(h))
 		if bit == nil {
 			return nil, fmt.Errorf("capture returned nil bitmap")
@@ -1001,9 +1031,9 @@ func SaveBitmapToFile(x C.int, y C.int, w C.int, h C.int, path *C.char, port C.l
 var _ = unsafe.Pointer(nil)
 
 //export SaveCaptureRegion
-func SaveCaptureRegion(path *C.char, x C.int, y C.int, w C.int, h C.int, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "save_capture_region", func() (interface{}, error) {
+func SaveCaptureRegion(path *C.char, x C.int, y C.int, w C.int, h C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("save_capture_region", func() (interface{}, error) {
 		goPath := C.GoString(path)
 		if goPath == "" {
 			return nil, fmt.Errorf("path empty")
@@ -1014,9 +1044,9 @@ func SaveCaptureRegion(path *C.char, x C.int, y C.int, w C.int, h C.int, port C.
 }
 
 //export SaveCaptureFull
-func SaveCaptureFull(path *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
-	safeOp(p, "save_capture_full", func() (interface{}, error) {
+func SaveCaptureFull(path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("save_capture_full", func() (interface{}, error) {
 		goPath := C.GoString(path)
 		if goPath == "" {
 			return nil, fmt.Errorf("path empty")
@@ -1028,10 +1058,10 @@ func SaveCaptureFull(path *C.char, port C.longlong) {
 
 // //export GcvFindImgFile
 // func GcvFindImgFile(templatePath *C.char, targetPath *C.char, port C.longlong) {
-// 	p := getPortOrDefault(port)
+// 	call := core.NewCall(int64(port), int64(reqID))
 // 	tp := C.GoString(templatePath)
 // 	targ := C.GoString(targetPath)
-// 	safeOp(p, "gcv_find_img_file", func() (interface{}, error) {
+// 	call.SafeOp("gcv_find_img_file", func() (interface{}, error) {
 // 		res := gcv.FindImgFile(tp, targ)
 // 		return fmt.Sprintf("%v", res), nil
 // 	})
@@ -1071,8 +1101,8 @@ func hookEventToMap(e hook.Event) map[string]interface{} {
 }
 
 //export HookRegisterCombo
-func HookRegisterCombo(mods *C.char, key *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func HookRegisterCombo(mods *C.char, key *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	modsStr := C.GoString(mods)
 	keyStr := C.GoString(key)
 	// parse mods comma-separated
@@ -1087,10 +1117,10 @@ func HookRegisterCombo(mods *C.char, key *C.char, port C.longlong) {
 	}
 	// register a callback that sends event to port
 	hook.Register(hook.KeyDown, append([]string{keyStr}, modsSlice...), func(e hook.Event) {
-		b, _ := json.Marshal(map[string]interface{}{"type": "hotkey", "key": keyStr, "mods": modsSlice, "event": hookEventToMap(e)})
-		bridge.SendStringToPort(p, string(b))
+		b, _ := json.Marshal(map[string]interface{}{"type": "hotkey", "request_id": call.RequestID, "key": keyStr, "mods": modsSlice, "event": hookEventToMap(e)})
+		core.EmitString(core.TopicSentinelHook, call.Port, core.Key("hotkey", call.RequestID), string(b))
 	})
-	bridge.SendStringToPort(p, fmt.Sprintf("registered-hotkey %s + %v", keyStr, modsSlice))
+	call.Send(core.Resp{Op: "hook_register_combo", Success: true, Data: map[string]interface{}{"key": keyStr, "mods": modsSlice}})
 }
 
 // helper to split comma separated and trim spaces
@@ -1125,12 +1155,12 @@ func trimSpaces(s string) string {
 }
 
 //export HookStart
-func HookStart(port C.longlong) {
-	p := getPortOrDefault(port)
+func HookStart(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	hookStartMu.Lock()
 	if hookStarted {
 		hookStartMu.Unlock()
-		bridge.SendStringToPort(p, "hook already started")
+		call.Send(core.Resp{Op: "hook_start", Success: false, Error: "hook already started"})
 		return
 	}
 	hookStarted = true
@@ -1139,25 +1169,25 @@ func HookStart(port C.longlong) {
 	evChan := hook.Start()
 	hookEventQuit = make(chan struct{})
 	go func() {
-		bridge.SendStringToPort(p, "hook_started")
+		call.Send(core.Resp{Op: "hook_started", Success: true})
 		for {
 			select {
 			case e, ok := <-evChan:
 				if !ok {
-					bridge.SendStringToPort(p, "hook_event_channel_closed")
+					call.Emit(core.TopicSentinelHook, core.Resp{Op: "hook_event_channel_closed", Success: true})
 					hookEndCleanup()
 					return
 				}
-				b, _ := json.Marshal(map[string]interface{}{"type": "event", "event": hookEventToMap(e)})
-				bridge.SendStringToPort(p, string(b))
+				b, _ := json.Marshal(map[string]interface{}{"type": "event", "request_id": call.RequestID, "event": hookEventToMap(e)})
+				core.EmitString(core.TopicSentinelHook, call.Port, core.Key("hook_event", call.RequestID), string(b))
 			case <-hookEventQuit:
-				bridge.SendStringToPort(p, "hook_event_loop_quit")
+				call.Emit(core.TopicSentinelHook, core.Resp{Op: "hook_event_loop_quit", Success: true})
 				return
 			}
 		}
 	}()
 	// let caller know
-	bridge.SendStringToPort(p, "hook_start_request_sent")
+	call.Send(core.Resp{Op: "hook_start", Success: true, Data: "hook_start_request_sent"})
 }
 
 // internal cleanup used by stop
@@ -1179,11 +1209,11 @@ func HookStop() {
 }
 
 //export HookAddEvent
-func HookAddEvent(name *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func HookAddEvent(name *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	n := C.GoString(name)
 	ok := hook.AddEvent(n)
-	bridge.SendStringToPort(p, fmt.Sprintf("add_event %s -> %v", n, ok))
+	call.Send(core.Resp{Op: "hook_add_event", Success: ok, Data: n})
 }
 
 
@@ -1191,10 +1221,10 @@ func HookAddEvent(name *C.char, port C.longlong) {
 // convenience debug helper: decode image file and return width/height
 //
 //export DecodeAndReportImageSize
-func DecodeAndReportImageSize(path *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func DecodeAndReportImageSize(path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	goPath := C.GoString(path)
-	safeOp(p, "decode_image_size", func() (interface{}, error) {
+	call.SafeOp("decode_image_size", func() (interface{}, error) {
 		img, _, err := robotgo.DecodeImg(goPath)
 		if err != nil || img == nil {
 			return nil, fmt.Errorf("decode error: %v", err)
@@ -1271,46 +1301,34 @@ func computeStats(prevNet []net.IOCountersStat) (SystemStats, []net.IOCountersSt
 }
 
 //export StartMonitor
-func StartMonitor(port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
-	ctx, cancel := context.WithCancel(context.Background())
-	id := addTask(cancel)
-
-	go func(tid int64) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "monitor_start", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
-
+func StartMonitor(reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	id := core.Go("monitor_start", call, func(ctx context.Context, tid int64) {
 		var prevNet []net.IOCountersStat
 		for {
 			select {
 			case <-ctx.Done():
-				sendToPort(p, simpleResp{Op: "monitor_stopped", Success: true, Data: tid})
+				call.Emit(core.TopicSentinelMonitor, core.Resp{Op: "monitor_stopped", Success: true, Data: tid})
 				return
 			default:
 				stats, newPrevNet := computeStats(prevNet)
 				prevNet = newPrevNet
-				resp := simpleResp{Op: "monitor_stats", Success: true, Data: stats}
-				b, _ := json.Marshal(resp)
-				bridge.SendStringToPort(p, string(b))
+				call.Emit(core.TopicSentinelMonitor, core.Resp{Op: "monitor_stats", Success: true, Data: stats})
 				time.Sleep(1 * time.Second)
 			}
 		}
-	}(id)
+	})
 
-	sendToPort(p, simpleResp{Op: "start_monitor", Success: true, Data: id})
+	call.Send(core.Resp{Op: "start_monitor", Success: true, Data: id})
 	return C.longlong(id)
 }
 
 //export ControlService
-func ControlService(name *C.char, action *C.char, port C.longlong) {
-	p := getPortOrDefault(port)
+func ControlService(name *C.char, action *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	nameGo := C.GoString(name)
 	actionGo := C.GoString(action)
-	safeOp(p, "control_service", func() (interface{}, error) {
+	call.SafeOp("control_service", func() (interface{}, error) {
 		conn, err := dbus.New()
 		if err != nil {
 			return nil, fmt.Errorf("systemd dbus error: %v", err)
@@ -1340,6 +1358,44 @@ func ControlService(name *C.char, action *C.char, port C.longlong) {
 		}, nil
 	})
 }
+// ---- task registry ----
+
+// StopTask cancels a task and waits up to core.StopTimeout for it. A task
+// that does not stop by then is abandoned and the reply says forced.
+//
+//export StopTask
+func StopTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
+	id := int64(taskID)
+	call := core.NewCall(int64(port), int64(reqID))
+	forced, err := core.StopTask(id, core.StopTimeout)
+	if err != nil {
+		call.Send(core.Resp{Op: "stop", Success: false, Error: err.Error()})
+		return
+	}
+	call.Send(core.Resp{Op: "stop", Success: true, Data: map[string]interface{}{"id": id, "forced": forced}})
+}
+
+//export ListTasks
+func ListTasks(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_tasks", func() (interface{}, error) {
+		return core.ListTasks(), nil
+	})
+}
+
+//export GetTask
+func GetTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
+	id := int64(taskID)
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_task", func() (interface{}, error) {
+		info, ok := core.GetTask(id)
+		if !ok {
+			return nil, fmt.Errorf("task %d not found", id)
+		}
+		return info, nil
+	})
+}
+
 func main(){
 
 }
\ No newline at end of file
@@ -0,0 +1,298 @@
+package main
+
+import "C"
+import (
+	core "core"
+	bridge "core/bridge"
+)
+
+// Invoke runs one export by name, as core.Invoke describes. For example
+//
+//	{"method": "Move", "id": 7, "params": {"x": 10, "y": 20}}
+//
+// moves the pointer to (10, 20) and replies under request id 7.
+//
+//export Invoke
+func Invoke(requestJSON *C.char, port C.longlong) C.longlong {
+	return C.longlong(core.Invoke([]byte(C.GoString(requestJSON)), int64(port)))
+}
+
+//export ListOperations
+func ListOperations(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_operations", func() (interface{}, error) {
+		return core.Operations(), nil
+	})
+}
+
+func init() {
+	core.Register(core.Operation{Method: "ConfigureQueue", Params: []core.Param{core.IntParam("targetPort"), core.IntParam("capacity"), core.StrParam("policy")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ConfigureQueue(C.longlong(a.Int64("targetPort")), C.int(a.Int("capacity")), (*C.char)(cs.Str(a.String("policy"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetQueueStats"}, func(call core.Call, a core.Args) int64 {
+		GetQueueStats(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogPort", Params: []core.Param{core.IntParam("logPort"), core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogPort(C.longlong(a.Int64("logPort")), (*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogLevel", Params: []core.Param{core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogLevel((*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Subscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Subscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Unsubscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Unsubscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListSubscriptions"}, func(call core.Call, a core.Args) int64 {
+		ListSubscriptions(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Shutdown", Params: []core.Param{core.IntParam("timeoutMs")}}, func(call core.Call, a core.Args) int64 {
+		Shutdown(C.int(a.Int("timeoutMs")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Move", Params: []core.Param{core.IntParam("x"), core.IntParam("y")}}, func(call core.Call, a core.Args) int64 {
+		Move(C.int(a.Int("x")), C.int(a.Int("y")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "MoveRelative", Params: []core.Param{core.IntParam("x"), core.IntParam("y")}}, func(call core.Call, a core.Args) int64 {
+		MoveRelative(C.int(a.Int("x")), C.int(a.Int("y")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Click", Params: []core.Param{core.StrParam("btn"), core.IntParam("dbl")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Click((*C.char)(cs.Str(a.String("btn"))), C.int(a.Int("dbl")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Toggle", Params: []core.Param{core.StrParam("btn"), core.StrParam("dir").Opt()}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Toggle((*C.char)(cs.Str(a.String("btn"))), (*C.char)(cs.Str(a.String("dir"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Scroll", Params: []core.Param{core.IntParam("x"), core.IntParam("y")}}, func(call core.Call, a core.Args) int64 {
+		Scroll(C.int(a.Int("x")), C.int(a.Int("y")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ScrollDir", Params: []core.Param{core.IntParam("amount"), core.StrParam("dir")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ScrollDir(C.int(a.Int("amount")), (*C.char)(cs.Str(a.String("dir"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetLocation"}, func(call core.Call, a core.Args) int64 {
+		GetLocation(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetMouseSleep", Params: []core.Param{core.IntParam("ms")}}, func(call core.Call, a core.Args) int64 {
+		SetMouseSleep(C.int(a.Int("ms")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "MilliSleep", Params: []core.Param{core.IntParam("ms")}}, func(call core.Call, a core.Args) int64 {
+		MilliSleep(C.int(a.Int("ms")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "MoveSmoothStart", Params: []core.Param{core.IntParam("x"), core.IntParam("y")}, Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		return int64(MoveSmoothStart(C.int(a.Int("x")), C.int(a.Int("y")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "DragSmoothStart", Params: []core.Param{core.IntParam("x"), core.IntParam("y")}, Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		return int64(DragSmoothStart(C.int(a.Int("x")), C.int(a.Int("y")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "ScrollSmoothStart", Params: []core.Param{core.IntParam("x"), core.IntParam("y")}, Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		return int64(ScrollSmoothStart(C.int(a.Int("x")), C.int(a.Int("y")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "TypeStr", Params: []core.Param{core.StrParam("text")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		TypeStr((*C.char)(cs.Str(a.String("text"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "TypeStrWithInts", Params: []core.Param{core.StrParam("text"), core.IntParam("arg1"), core.IntParam("arg2")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		TypeStrWithInts((*C.char)(cs.Str(a.String("text"))), C.int(a.Int("arg1")), C.int(a.Int("arg2")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GoSleep", Params: []core.Param{core.IntParam("seconds")}}, func(call core.Call, a core.Args) int64 {
+		GoSleep(C.int(a.Int("seconds")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetKeySleep", Params: []core.Param{core.IntParam("ms")}}, func(call core.Call, a core.Args) int64 {
+		SetKeySleep(C.int(a.Int("ms")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "KeyTap", Params: []core.Param{core.StrParam("key"), core.StrParam("mods")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		KeyTap((*C.char)(cs.Str(a.String("key"))), (*C.char)(cs.Str(a.String("mods"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "KeyTapArr", Params: []core.Param{core.StrParam("key"), core.StrsParam("mods")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		KeyTapArr((*C.char)(cs.Str(a.String("key"))), (*C.char)(cs.Str(a.JSON("mods"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "KeyToggle", Params: []core.Param{core.StrParam("key"), core.StrParam("direction")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		KeyToggle((*C.char)(cs.Str(a.String("key"))), (*C.char)(cs.Str(a.String("direction"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "WriteAll", Params: []core.Param{core.StrParam("text")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		WriteAll((*C.char)(cs.Str(a.String("text"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ReadAll"}, func(call core.Call, a core.Args) int64 {
+		ReadAll(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "TypeStrStart", Params: []core.Param{core.StrParam("text")}, Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(TypeStrStart((*C.char)(cs.Str(a.String("text"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "GetPixelColor", Params: []core.Param{core.IntParam("x"), core.IntParam("y")}}, func(call core.Call, a core.Args) int64 {
+		GetPixelColor(C.int(a.Int("x")), C.int(a.Int("y")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetScreenSize"}, func(call core.Call, a core.Args) int64 {
+		GetScreenSize(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CaptureScreenSave", Params: []core.Param{core.IntParam("x"), core.IntParam("y"), core.IntParam("w"), core.IntParam("h"), core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		CaptureScreenSave(C.int(a.Int("x")), C.int(a.Int("y")), C.int(a.Int("w")), C.int(a.Int("h")), (*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CaptureScreenBase64", Params: []core.Param{core.IntParam("x"), core.IntParam("y"), core.IntParam("w"), core.IntParam("h")}}, func(call core.Call, a core.Args) int64 {
+		CaptureScreenBase64(C.int(a.Int("x")), C.int(a.Int("y")), C.int(a.Int("w")), C.int(a.Int("h")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CaptureScreenPNG", Params: []core.Param{core.IntParam("x"), core.IntParam("y"), core.IntParam("w"), core.IntParam("h")}}, func(call core.Call, a core.Args) int64 {
+		CaptureScreenPNG(C.int(a.Int("x")), C.int(a.Int("y")), C.int(a.Int("w")), C.int(a.Int("h")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "DisplaysNum"}, func(call core.Call, a core.Args) int64 {
+		DisplaysNum(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetDisplayBounds", Params: []core.Param{core.IntParam("index")}}, func(call core.Call, a core.Args) int64 {
+		GetDisplayBounds(C.int(a.Int("index")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CaptureDisplaySave", Params: []core.Param{core.IntParam("index"), core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		CaptureDisplaySave(C.int(a.Int("index")), (*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CaptureDisplayRegionSave", Params: []core.Param{core.IntParam("index"), core.IntParam("x"), core.IntParam("y"), core.IntParam("w"), core.IntParam("h"), core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		CaptureDisplayRegionSave(C.int(a.Int("index")), C.int(a.Int("x")), C.int(a.Int("y")), C.int(a.Int("w")), C.int(a.Int("h")), (*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SaveImageJpeg", Params: []core.Param{core.StrParam("path"), core.IntParam("quality")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SaveImageJpeg((*C.char)(cs.Str(a.String("path"))), C.int(a.Int("quality")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SaveImagePNGFromCaptureImg", Params: []core.Param{core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SaveImagePNGFromCaptureImg((*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SaveBitmapToFile", Params: []core.Param{core.IntParam("x"), core.IntParam("y"), core.IntParam("w"), core.IntParam("h"), core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SaveBitmapToFile(C.int(a.Int("x")), C.int(a.Int("y")), C.int(a.Int("w")), C.int(a.Int("h")), (*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SaveCaptureRegion", Params: []core.Param{core.StrParam("path"), core.IntParam("x"), core.IntParam("y"), core.IntParam("w"), core.IntParam("h")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SaveCaptureRegion((*C.char)(cs.Str(a.String("path"))), C.int(a.Int("x")), C.int(a.Int("y")), C.int(a.Int("w")), C.int(a.Int("h")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SaveCaptureFull", Params: []core.Param{core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SaveCaptureFull((*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "HookRegisterCombo", Params: []core.Param{core.StrParam("mods"), core.StrParam("key")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		HookRegisterCombo((*C.char)(cs.Str(a.String("mods"))), (*C.char)(cs.Str(a.String("key"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "HookStart"}, func(call core.Call, a core.Args) int64 {
+		HookStart(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "HookStop"}, func(call core.Call, a core.Args) int64 {
+		HookStop()
+		return 0
+	})
+	core.Register(core.Operation{Method: "HookAddEvent", Params: []core.Param{core.StrParam("name")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		HookAddEvent((*C.char)(cs.Str(a.String("name"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "DecodeAndReportImageSize", Params: []core.Param{core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		DecodeAndReportImageSize((*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "StartMonitor", Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		return int64(StartMonitor(C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "ControlService", Params: []core.Param{core.StrParam("name"), core.StrParam("action")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ControlService((*C.char)(cs.Str(a.String("name"))), (*C.char)(cs.Str(a.String("action"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "StopTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		StopTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListTasks"}, func(call core.Call, a core.Args) int64 {
+		ListTasks(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		GetTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListOperations"}, func(call core.Call, a core.Args) int64 {
+		ListOperations(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+}
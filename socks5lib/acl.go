@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	core "core"
+
+	socks5 "github.com/0990/socks5"
+)
+
+// Rule is one ACL entry, the destination part of socks5lib2's rules.
+// Every condition that is set must hold for the rule to match; an entry
+// with no conditions matches everything.
+//
+// Domains lists exact names, or suffixes written as ".example.com" or
+// "*.example.com" that match the domain and all its subdomains. CIDRs are
+// checked against IP destinations and against what domain destinations
+// resolve to with the system resolver, which is what dialing uses. Ports
+// is a list like "80,443,8000-9000".
+type Rule struct {
+	Action      string   `json:"action"`
+	CIDRs       []string `json:"cidrs,omitempty"`
+	Domains     []string `json:"domains,omitempty"`
+	DomainRegex string   `json:"domain_regex,omitempty"`
+	Ports       string   `json:"ports,omitempty"`
+}
+
+// ACLConfig is the JSON accepted by SetACL. Rules are evaluated in order
+// and the first match decides; Default ("allow" when empty) applies when
+// none matches. The server only shows CONNECT targets to its dial hook, so
+// rules cannot name users or commands, and UDP is not checked.
+type ACLConfig struct {
+	Default string `json:"default,omitempty"`
+	Rules   []Rule `json:"rules"`
+}
+
+const (
+	dialTimeout   = 30 * time.Second
+	lookupTimeout = 10 * time.Second
+)
+
+var (
+	errNotAllowed = errors.New("connection not allowed by ruleset")
+	errStopped    = errors.New("server stopped")
+)
+
+type portRange struct{ lo, hi int }
+
+type aclRule struct {
+	allow    bool
+	nets     []*net.IPNet
+	exact    map[string]bool
+	suffixes []string
+	re       *regexp.Regexp
+	ports    []portRange
+}
+
+// acl is a compiled ACLConfig. A nil *acl allows everything.
+type acl struct {
+	cfg          ACLConfig
+	rules        []aclRule
+	defaultAllow bool
+}
+
+func parseAction(s string, empty bool) (bool, error) {
+	switch strings.ToLower(s) {
+	case "allow":
+		return true, nil
+	case "deny":
+		return false, nil
+	case "":
+		return empty, nil
+	}
+	return false, fmt.Errorf("unknown action %q", s)
+}
+
+func parsePorts(s string) ([]portRange, error) {
+	var out []portRange
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(f, "-")
+		a, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", f)
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("invalid port range %q", f)
+			}
+		}
+		if a < 0 || b > 65535 || a > b {
+			return nil, fmt.Errorf("invalid port range %q", f)
+		}
+		out = append(out, portRange{a, b})
+	}
+	return out, nil
+}
+
+// parseACL decodes and compiles an ACLConfig. Fields only socks5lib2
+// understands, such as users, commands or egress, are refused rather than
+// ignored.
+func parseACL(s string) (*acl, error) {
+	var cfg ACLConfig
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid acl: %v", err)
+	}
+	a := &acl{cfg: cfg}
+	var err error
+	if a.defaultAllow, err = parseAction(cfg.Default, true); err != nil {
+		return nil, fmt.Errorf("default: %v", err)
+	}
+	for i, r := range cfg.Rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i, err)
+		}
+		a.rules = append(a.rules, cr)
+	}
+	return a, nil
+}
+
+func compileRule(r Rule) (aclRule, error) {
+	var cr aclRule
+	var err error
+	if r.Action == "" {
+		return cr, errors.New("action is required")
+	}
+	if cr.allow, err = parseAction(r.Action, false); err != nil {
+		return cr, err
+	}
+	for _, c := range r.CIDRs {
+		if !strings.Contains(c, "/") {
+			if ip := net.ParseIP(c); ip != nil && ip.To4() != nil {
+				c += "/32"
+			} else {
+				c += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return cr, err
+		}
+		cr.nets = append(cr.nets, n)
+	}
+	for _, d := range r.Domains {
+		d = strings.ToLower(strings.TrimSuffix(d, "."))
+		switch {
+		case strings.HasPrefix(d, "*."):
+			cr.suffixes = append(cr.suffixes, d[1:])
+		case strings.HasPrefix(d, "."):
+			cr.suffixes = append(cr.suffixes, d)
+		default:
+			if cr.exact == nil {
+				cr.exact = map[string]bool{}
+			}
+			cr.exact[d] = true
+		}
+	}
+	if r.DomainRegex != "" {
+		if cr.re, err = regexp.Compile(r.DomainRegex); err != nil {
+			return cr, err
+		}
+	}
+	if cr.ports, err = parsePorts(r.Ports); err != nil {
+		return cr, err
+	}
+	return cr, nil
+}
+
+// target is what a rule is matched against. CIDR rules resolve a domain
+// host once, on first use.
+type target struct {
+	host     string
+	port     int
+	ips      []net.IP
+	resolved bool
+}
+
+func (t *target) addrs() []net.IP {
+	if !t.resolved {
+		t.resolved = true
+		if ip := net.ParseIP(t.host); ip != nil {
+			t.ips = []net.IP{ip}
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+			t.ips, _ = net.DefaultResolver.LookupIP(ctx, "ip", t.host)
+			cancel()
+		}
+	}
+	return t.ips
+}
+
+func (r *aclRule) match(t *target) bool {
+	if len(r.ports) > 0 && !r.matchPort(t.port) {
+		return false
+	}
+	hasDomain := r.exact != nil || len(r.suffixes) > 0 || r.re != nil
+	if hasDomain || len(r.nets) > 0 {
+		return (hasDomain && r.matchDomain(t)) || (len(r.nets) > 0 && r.matchIP(t))
+	}
+	return true
+}
+
+func (r *aclRule) matchPort(p int) bool {
+	for _, pr := range r.ports {
+		if p >= pr.lo && p <= pr.hi {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *aclRule) matchDomain(t *target) bool {
+	if net.ParseIP(t.host) != nil {
+		return false
+	}
+	h := strings.ToLower(strings.TrimSuffix(t.host, "."))
+	if r.exact[h] {
+		return true
+	}
+	for _, s := range r.suffixes {
+		if h == s[1:] || strings.HasSuffix(h, s) {
+			return true
+		}
+	}
+	return r.re != nil && r.re.MatchString(h)
+}
+
+func (r *aclRule) matchIP(t *target) bool {
+	for _, ip := range t.addrs() {
+		for _, n := range r.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// check returns whether addr is allowed and the index of the deciding
+// rule, -1 when the default applied.
+func (a *acl) check(addr string) (bool, int) {
+	if a == nil {
+		return true, -1
+	}
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false, -1
+	}
+	t := &target{host: host}
+	t.port, _ = strconv.Atoi(p)
+	for i := range a.rules {
+		if a.rules[i].match(t) {
+			return a.rules[i].allow, i
+		}
+	}
+	return a.defaultAllow, -1
+}
+
+// aclHolder lets a server's rules be swapped while it runs.
+type aclHolder struct {
+	mu  sync.RWMutex
+	acl *acl
+}
+
+func (h *aclHolder) get() *acl {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.acl
+}
+
+func (h *aclHolder) set(a *acl) {
+	h.mu.Lock()
+	h.acl = a
+	h.mu.Unlock()
+}
+
+// dialTarget is the server's dial hook for CONNECT. It refuses every
+// target once the server was stopped, since Run cannot be interrupted,
+// and targets the ACL denies; it dials the rest directly.
+func (w *Socks5ServerWrapper) dialTarget(addr string) (socks5.Stream, byte, string, error) {
+	select {
+	case <-w.StopChan:
+		return nil, 0, "", errStopped
+	default:
+	}
+	if ok, rule := w.acl.get().check(addr); !ok {
+		core.Log(core.LevelDebug, "socks5", errNotAllowed.Error(), map[string]interface{}{
+			"server": w.ID,
+			"target": addr,
+			"rule":   rule,
+		})
+		return nil, 0, "", errNotAllowed
+	}
+	c, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return c, socks5.ATypIPV4, addr, nil
+}
@@ -1,3 +0,0 @@
-module hiddify.com/hiddify/bridge
-
-go 1.20
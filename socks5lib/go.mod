@@ -3,14 +3,15 @@ module socks5lib
 go 1.25.4
 
 require (
-	bridge v0.0.0-00010101000000-000000000000
+	core v0.0.0-00010101000000-000000000000
 	github.com/0990/socks5 v1.0.9
+	github.com/sirupsen/logrus v1.6.0
 )
 
 require (
 	github.com/konsorten/go-windows-terminal-sequences v1.0.3 // indirect
-	github.com/sirupsen/logrus v1.6.0 // indirect
 	golang.org/x/sys v0.0.0-20190924154521-2837fb4f24fe // indirect
 )
 
-replace bridge => ./bridge
+replace core => ../core
+
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+
+	core "core"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logrusHook forwards entries of the standard logrus logger, which
+// github.com/0990/socks5 logs through, into core log records.
+type logrusHook struct{}
+
+func (logrusHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (logrusHook) Fire(e *logrus.Entry) error {
+	var fields map[string]interface{}
+	if len(e.Data) > 0 {
+		fields = make(map[string]interface{}, len(e.Data))
+		for k, v := range e.Data {
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			fields[k] = v
+		}
+	}
+	core.Log(logrusLevel(e.Level), "socks5", e.Message, fields)
+	return nil
+}
+
+func logrusLevel(l logrus.Level) core.Level {
+	switch l {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return core.LevelDebug
+	case logrus.InfoLevel:
+		return core.LevelInfo
+	case logrus.WarnLevel:
+		return core.LevelWarn
+	default:
+		return core.LevelError
+	}
+}
+
+func init() {
+	logrus.AddHook(logrusHook{})
+	logrus.SetOutput(io.Discard)
+}
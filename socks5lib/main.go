@@ -2,18 +2,21 @@ package main
 
 /*
 #include <stdint.h>
+#include <stdlib.h>
 */
 import "C"
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
-	bridge "bridge"
+	core "core"
+	bridge "core/bridge"
 
 	socks5 "github.com/0990/socks5"
 )
@@ -29,136 +32,211 @@ var (
 	nextSrvID     int64 // atomic increment
 )
 
-type taskRec struct {
-	cancel context.CancelFunc
-	done   chan struct{}
-}
-
-var (
-	tasks    = map[int64]*taskRec{}
-	tasksMu  sync.Mutex
-	nextTask int64 // start from 0; atomic increment gives 1,2,...
-)
-var (
-	registeredPort int64
-	portMu         sync.RWMutex
-)
-
-type simpleResp struct {
-	Op      string      `json:"op"`
-	Success bool        `json:"success"`
-	Error   string      `json:"error,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-}
-
+// Socks5ServerWrapper holds a github.com/0990/socks5 server. That server
+// handles requests internally; its only hook is the dial of CONNECT
+// targets, which dialTarget uses for destination ACLs. Per-request
+// features such as BIND live in socks5lib2 only.
 type Socks5ServerWrapper struct {
 	Server   socks5.Server
 	StopChan chan struct{}
 	ID       int64
+	acl      aclHolder
 }
 
-func addTask(cancel context.CancelFunc) int64 {
-	id := atomic.AddInt64(&nextTask, 1)
-	entry := &taskRec{
-		cancel: cancel,
-		done:   make(chan struct{}),
-	}
-	tasksMu.Lock()
-	tasks[id] = entry
-	tasksMu.Unlock()
-	return id
+func init() {
+	core.SetPoster(bridge.SendKeyedStringToPort)
+	bridge.Logger = core.BridgeLogger
+	core.CaptureStdLog()
+	core.OnShutdown("socks5 servers", shutdownServers)
 }
 
-func finishTask(id int64) {
-	tasksMu.Lock()
-	if e, ok := tasks[id]; ok {
-		close(e.done)
-		delete(tasks, id)
+// shutdownServers stops every server the way StopSocks5Server does. Run
+// cannot be interrupted, so running ones still show up as forced
+// start_socks5_server tasks.
+func shutdownServers(ctx context.Context) []string {
+	socks5SrvMu.Lock()
+	ws := make([]*Socks5ServerWrapper, 0, len(socks5Servers))
+	for id, w := range socks5Servers {
+		ws = append(ws, w)
+		delete(socks5Servers, id)
 	}
-	tasksMu.Unlock()
+	socks5SrvMu.Unlock()
+	for _, w := range ws {
+		close(w.StopChan)
+	}
+	return nil
 }
 
 //export RegisterPort
 func RegisterPort(port C.longlong) {
-	portMu.Lock()
-	registeredPort = int64(port)
-	portMu.Unlock()
-	bridge.SendStringToPort(int64(port), "registered")
+	core.RegisterPort(int64(port))
 }
 
 //export UnregisterPort
 func UnregisterPort() {
-	portMu.Lock()
-	p := registeredPort
-	registeredPort = 0
-	portMu.Unlock()
-	if p != 0 {
-		bridge.SendStringToPort(p, "unregistered")
+	if p := core.UnregisterPort(); p != 0 {
+		bridge.CloseQueue(p)
 	}
 }
 
-func getPortOrDefault(p C.longlong) int64 {
-	if int64(p) != 0 {
-		return int64(p)
-	}
-	portMu.RLock()
-	defer portMu.RUnlock()
-	return registeredPort
-}
-
-func sendToPort(port int64, r simpleResp) {
-	b, _ := json.Marshal(r)
-	bridge.SendStringToPort(port, string(b))
-}
-
-// ---- wrapper helpers ----
-func safeOp(port int64, op string, fn func() (interface{}, error)) {
-	defer func() {
-		if r := recover(); r != nil {
-			sendToPort(port, simpleResp{Op: op, Success: false, Error: fmt.Sprintf("panic: %v", r)})
-		}
-	}()
-	res, err := fn()
-	if err != nil {
-		sendToPort(port, simpleResp{Op: op, Success: false, Error: err.Error()})
-		return
-	}
-	sendToPort(port, simpleResp{Op: op, Success: true, Data: res})
-}
-
 //export BridgeInit
 func BridgeInit(api unsafe.Pointer) {
 	// panic-safe init
 	defer func() {
 		if r := recover(); r != nil {
-			port := getPortOrDefault(0)
+			port := core.PortOrDefault(0)
 			if port != 0 {
-				sendToPort(port, simpleResp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
+				core.Send(port, core.Resp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
 			}
 		}
 	}()
 	bridge.InitDartApi(api)
 }
 
+// ---- outbound queue ----
+
+// targetPort 0 configures the queue of port itself; policy is one of
+// "drop-oldest", "drop-newest" or "coalesce".
+//
+//export ConfigureQueue
+func ConfigureQueue(targetPort C.longlong, capacity C.int, policy *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	target := int64(targetPort)
+	if target == 0 {
+		target = call.Port
+	}
+	pol := C.GoString(policy)
+	call.SafeOp("configure_queue", func() (interface{}, error) {
+		dp, err := bridge.ParseDropPolicy(pol)
+		if err != nil {
+			return nil, err
+		}
+		if err := bridge.ConfigureQueue(target, bridge.QueueConfig{Capacity: int(capacity), Policy: dp}); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"port": target, "capacity": int(capacity), "policy": dp.String()}, nil
+	})
+}
+
+//export GetQueueStats
+func GetQueueStats(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("queue_stats", func() (interface{}, error) {
+		return bridge.Stats(), nil
+	})
+}
+
+// ---- logging ----
+
+// logPort 0 sends records to stderr; an empty level keeps the current one.
+//
+//export SetLogPort
+func SetLogPort(logPort C.longlong, level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_port", func() (interface{}, error) {
+		if lvl != "" {
+			l, err := core.ParseLevel(lvl)
+			if err != nil {
+				return nil, err
+			}
+			core.SetLogLevel(l)
+		}
+		core.SetLogPort(int64(logPort))
+		return map[string]interface{}{"port": int64(logPort), "level": core.LogLevel().String()}, nil
+	})
+}
+
+//export SetLogLevel
+func SetLogLevel(level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_level", func() (interface{}, error) {
+		l, err := core.ParseLevel(lvl)
+		if err != nil {
+			return nil, err
+		}
+		core.SetLogLevel(l)
+		return l.String(), nil
+	})
+}
+
+// ---- subscriptions ----
+
+// Subscribe adds subPort to topic. This library publishes "tasks" and
+// "logs"; see core/topic.go. Every subscriber gets its own copy of each
+// event.
+//
+//export Subscribe
+func Subscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("subscribe", func() (interface{}, error) {
+		if err := core.Subscribe(t, int64(subPort)); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"topic": t, "subscribers": core.Subscribers(t)}, nil
+	})
+}
+
+// An empty topic removes subPort from every topic.
+//
+//export Unsubscribe
+func Unsubscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("unsubscribe", func() (interface{}, error) {
+		core.Unsubscribe(t, int64(subPort))
+		return map[string]interface{}{"topic": t, "port": int64(subPort)}, nil
+	})
+}
+
+//export ListSubscriptions
+func ListSubscriptions(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_subscriptions", func() (interface{}, error) {
+		return core.Subscriptions(), nil
+	})
+}
+
+// ---- shutdown ----
+
+// Shutdown cancels every task and stops everything the library started,
+// waiting at most timeoutMs (5s when <= 0). The reply lists what stopped
+// and what had to be abandoned.
+//
+//export Shutdown
+func Shutdown(timeoutMs C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	call.SafeOp("shutdown", func() (interface{}, error) {
+		return core.Shutdown(timeout), nil
+	})
+}
+
 // ---- SOCKS5 Server Exports ----
 
 //export CreateDirectServerTCP
-func CreateDirectServerTCP(listenPort C.int, username *C.char, password *C.char, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func CreateDirectServerTCP(listenPort C.int, username *C.char, password *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	lPort := int(listenPort)
 	uName := C.GoString(username)
 	pwd := C.GoString(password)
 
-	safeOp(p, "create_direct_server_tcp", func() (interface{}, error) {
+	return C.longlong(call.Create("create_direct_server_tcp", func() (int64, error) {
 		cfg := socks5.ServerCfg{
 			ListenPort: lPort,
 			UserName:   uName,
 			Password:   pwd,
-			LogLevel:   "info",
+			// logrus output is forwarded and filtered by core's log level
+			LogLevel: "debug",
 		}
 		server, err := socks5.NewServer(cfg)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
 		id := atomic.AddInt64(&nextSrvID, 1)
 		wrapper := &Socks5ServerWrapper{
@@ -166,107 +244,53 @@ func CreateDirectServerTCP(listenPort C.int, username *C.char, password *C.char,
 			StopChan: make(chan struct{}),
 			ID:       id,
 		}
+		server.SetCustomDialTarget(wrapper.dialTarget)
 		socks5SrvMu.Lock()
 		socks5Servers[id] = wrapper
 		socks5SrvMu.Unlock()
 		return id, nil
-	})
-	return 0
+	}))
 }
 
 //export CreateDirectServerUDP
-func CreateDirectServerUDP(listenPort C.int, username *C.char, password *C.char, port C.longlong) C.longlong {
+func CreateDirectServerUDP(listenPort C.int, username *C.char, password *C.char, reqID C.longlong, port C.longlong) C.longlong {
 	// Note: The library handles UDP in the same server; just create with same config
-	return CreateDirectServerTCP(listenPort, username, password, port)
+	return CreateDirectServerTCP(listenPort, username, password, reqID, port)
 }
 
-// //export CreateProxyToSocks5ServerTCP
-// func CreateProxyToSocks5ServerTCP(listenPort C.int, username *C.char, password *C.char, proxyAddr *C.char, proxyUser *C.char, proxyPass *C.char, port C.longlong) C.longlong {
-// 	p := getPortOrDefault(port)
-// 	lPort := int(listenPort)
-// 	uName := C.GoString(username)
-// 	pwd := C.GoString(password)
-// 	pxAddr := C.GoString(proxyAddr)
-// 	pxUser := C.GoString(proxyUser)
-// 	pxPwd := C.GoString(proxyPass)
-
-// 	safeOp(p, "create_proxy_to_socks5_server_tcp", func() (interface{}, error) {
-// 		cfg := socks5.ServerCfg{
-// 			ListenPort: lPort,
-// 			UserName:   uName,
-// 			Password:   pwd,
-// 			LogLevel:   "info",
-// 		}
-// 		server, err := socks5.NewServer(cfg)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		// Set custom dial target for TCP
-// 		server.SetCustomDialTarget(func(addr string) (socks5.Stream, byte, string, error) {
-// 			client := socks5.NewSocks5Client(socks5.ClientCfg{
-// 				ServerAddr: pxAddr,
-// 				UserName:   pxUser,
-// 				Password:   pxPwd,
-// 			})
-// 			stream, err := client.Connect(addr)
-// 			if err != nil {
-// 				return nil, 0, "", err
-// 			}
-// 			return stream, socks5.ATypIPV4, addr, nil
-// 		})
-// 		id := atomic.AddInt64(&nextSrvID, 1)
-// 		wrapper := &Socks5ServerWrapper{
-// 			Server:   server,
-// 			StopChan: make(chan struct{}),
-// 			ID:       id,
-// 		}
-// 		socks5SrvMu.Lock()
-// 		socks5Servers[id] = wrapper
-// 		socks5SrvMu.Unlock()
-// 		return id, nil
-// 	})
-// 	return 0
-// }
-
 //export CreateProxyToSocks5ServerUDP
-func CreateProxyToSocks5ServerUDP(listenPort C.int, username *C.char, password *C.char, proxyAddr *C.char, proxyUser *C.char, proxyPass *C.char, port C.longlong) C.longlong {
+func CreateProxyToSocks5ServerUDP(listenPort C.int, username *C.char, password *C.char, proxyAddr *C.char, proxyUser *C.char, proxyPass *C.char, reqID C.longlong, port C.longlong) C.longlong {
 	// UDP proxying through another SOCKS5 is complex and not fully supported; placeholder as direct
 	log.Println("UDP proxy through another SOCKS5 not fully implemented.")
-	return CreateDirectServerTCP(listenPort, username, password, port)
+	return CreateDirectServerTCP(listenPort, username, password, reqID, port)
 }
 
 //export CreateWithAuthServer
-func CreateWithAuthServer(listenPort C.int, port C.longlong) C.longlong {
-	return CreateDirectServerTCP(listenPort, C.CString("user"), C.CString("pass"), port)
+func CreateWithAuthServer(listenPort C.int, reqID C.longlong, port C.longlong) C.longlong {
+	return CreateDirectServerTCP(listenPort, C.CString("user"), C.CString("pass"), reqID, port)
 }
 
 //export CreateWithoutAuthServer
-func CreateWithoutAuthServer(listenPort C.int, port C.longlong) C.longlong {
-	return CreateDirectServerTCP(listenPort, C.CString(""), C.CString(""), port)
+func CreateWithoutAuthServer(listenPort C.int, reqID C.longlong, port C.longlong) C.longlong {
+	return CreateDirectServerTCP(listenPort, C.CString(""), C.CString(""), reqID, port)
 }
 
 //export StartSocks5Server
-func StartSocks5Server(srvID C.longlong, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func StartSocks5Server(srvID C.longlong, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	id := int64(srvID)
 	socks5SrvMu.Lock()
 	wrapper, ok := socks5Servers[id]
 	socks5SrvMu.Unlock()
 	if !ok {
-		sendToPort(p, simpleResp{Op: "start_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
+		call.Send(core.Resp{Op: "start_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
 		return 0
 	}
 
-	_, cancel := context.WithCancel(context.Background())
-	taskID := addTask(cancel)
-
-	go func(tid int64, w *Socks5ServerWrapper) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "start_socks5_server", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
+	w := wrapper
+	// Run cannot be interrupted, so StopTask refuses the task; the server
+	// is stopped with StopSocks5Server
+	taskID := core.GoUncancelable("start_socks5_server", call, func(tid int64) {
 		defer func() {
 			socks5SrvMu.Lock()
 			delete(socks5Servers, w.ID)
@@ -275,105 +299,142 @@ func StartSocks5Server(srvID C.longlong, port C.longlong) C.longlong {
 
 		err := w.Server.Run()
 		if err != nil {
-			sendToPort(p, simpleResp{Op: "start_socks5_server", Success: false, Error: err.Error()})
+			call.Send(core.Resp{Op: "start_socks5_server", Success: false, Error: err.Error()})
 			return
 		}
-		sendToPort(p, simpleResp{Op: "start_socks5_server", Success: true, Data: tid})
-	}(taskID, wrapper)
+		call.Send(core.Resp{Op: "start_socks5_server", Success: true, Data: tid})
+	})
 
 	return C.longlong(taskID)
 }
 
 //export StopSocks5Server
-func StopSocks5Server(srvID C.longlong, port C.longlong) {
-	p := getPortOrDefault(port)
+func StopSocks5Server(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	id := int64(srvID)
+	// whoever removes the entry closes StopChan, so it is closed once
 	socks5SrvMu.Lock()
 	wrapper, ok := socks5Servers[id]
+	delete(socks5Servers, id)
 	socks5SrvMu.Unlock()
 	if !ok {
-		sendToPort(p, simpleResp{Op: "stop_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
+		call.Send(core.Resp{Op: "stop_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
 		return
 	}
-	// Assuming the server has a Close method or we can stop via channel; if not, may need to kill goroutine
-	// For simplicity, since Run() likely doesn't have direct stop, we close the stopChan and hope
+	// Run cannot be stopped; once StopChan is closed dialTarget refuses
+	// every new connection
 	close(wrapper.StopChan)
+	call.Send(core.Resp{Op: "stop_socks5_server", Success: true, Data: id})
+}
+
+func lookupServer(id int64) (*Socks5ServerWrapper, error) {
 	socks5SrvMu.Lock()
-	delete(socks5Servers, id)
-	socks5SrvMu.Unlock()
-	sendToPort(p, simpleResp{Op: "stop_socks5_server", Success: true, Data: id})
+	defer socks5SrvMu.Unlock()
+	w, ok := socks5Servers[id]
+	if !ok {
+		return nil, core.WithCode(core.CodeNotFound, fmt.Errorf("server %d not found", id))
+	}
+	return w, nil
 }
 
-// ---- SOCKS5 Client Exports ----
-
-// //export ConnectDirectTCP
-// func ConnectDirectTCP(socksAddr *C.char, username *C.char, password *C.char, targetAddr *C.char, port C.longlong) C.longlong {
-// 	p := getPortOrDefault(port)
-// 	sAddr := C.GoString(socksAddr)
-// 	uName := C.GoString(username)
-// 	pwd := C.GoString(password)
-// 	tAddr := C.GoString(targetAddr)
-
-// 	safeOp(p, "connect_direct_tcp", func() (interface{}, error) {
-// 		client := socks5.NewSocks5Client(socks5.ClientCfg{
-// 			ServerAddr: sAddr,
-// 			UserName:   uName,
-// 			Password:   pwd,
-// 		})
-// 		conn, err := client.Connect(tAddr)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		// Return a dummy ID or handle connection; for simplicity, just confirm success
-// 		return "connected", nil
-// 	})
-// 	return 0
-// }
-
-// // Similar for others; adapt as needed
-// //export ConnectDirectUDP
-// func ConnectDirectUDP(socksAddr *C.char, username *C.char, password *C.char, targetAddr *C.char, port C.longlong) C.longlong {
-// 	p := getPortOrDefault(port)
-// 	sAddr := C.GoString(socksAddr)
-// 	uName := C.GoString(username)
-// 	pwd := C.GoString(password)
-// 	tAddr := C.GoString(targetAddr)
-
-// safeOp(p, "connect_direct_tcp", func() (interface{}, error) {
-// 		client := socks5.NewSocks5Client(socks5.ClientCfg{
-// 			ServerAddr: sAddr,
-// 			UserName:   uName,
-// 			Password:   pwd,
-// 		})
-// 		_, err := client.Connect(tAddr)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		// Return a dummy ID or handle connection; for simplicity, just confirm success
-// 		return "connected", nil
-// 	})
-// 	return 0
-// }
-
-// Add other client functions similarly if needed...
+// ---- access control ----
+
+// SetACL replaces a server's destination rules; see ACLConfig for the
+// format. An empty string or null removes them, allowing everything.
+//
+//export SetACL
+func SetACL(srvID C.longlong, aclJSON *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	js := strings.TrimSpace(C.GoString(aclJSON))
+	call.SafeOp("set_acl", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if js == "" || js == "null" {
+			w.acl.set(nil)
+			return nil, nil
+		}
+		a, err := parseACL(js)
+		if err != nil {
+			return nil, core.WithCode(core.CodeInvalidArg, err)
+		}
+		w.acl.set(a)
+		return len(a.rules), nil
+	})
+}
+
+//export GetACL
+func GetACL(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_acl", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if a := w.acl.get(); a != nil {
+			return a.cfg, nil
+		}
+		return nil, nil
+	})
+}
+
+// ---- errors ----
+
+// GetLastError returns the message behind a negative code returned by a
+// Create* call made with reqID, or NULL. Free it with FreeString. reqID 0
+// reads the latest failure among all calls made without a request id.
+//
+//export GetLastError
+func GetLastError(reqID C.longlong) *C.char {
+	msg, ok := core.LastError(int64(reqID))
+	if !ok {
+		return nil
+	}
+	return C.CString(msg)
+}
 
+//export FreeString
+func FreeString(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// ---- task registry ----
+
+// StopTask cancels a task and waits up to core.StopTimeout for it. A task
+// that does not stop by then is abandoned and the reply says forced.
+//
 //export StopTask
-func StopTask(taskID C.longlong, port C.longlong) {
+func StopTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
 	id := int64(taskID)
-	p := getPortOrDefault(port)
-	tasksMu.Lock()
-	entry, ok := tasks[id]
-	if ok {
-		delete(tasks, id)
-	}
-	tasksMu.Unlock()
-	if !ok {
-		sendToPort(p, simpleResp{Op: "stop", Success: false, Error: fmt.Sprintf("task %d not found", id)})
+	call := core.NewCall(int64(port), int64(reqID))
+	forced, err := core.StopTask(id, core.StopTimeout)
+	if err != nil {
+		call.Send(core.Resp{Op: "stop", Success: false, Error: err.Error()})
 		return
 	}
-	entry.cancel()
-	<-entry.done
-	sendToPort(p, simpleResp{Op: "stop", Success: true, Data: id})
+	call.Send(core.Resp{Op: "stop", Success: true, Data: map[string]interface{}{"id": id, "forced": forced}})
+}
+
+//export ListTasks
+func ListTasks(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_tasks", func() (interface{}, error) {
+		return core.ListTasks(), nil
+	})
+}
+
+//export GetTask
+func GetTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
+	id := int64(taskID)
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_task", func() (interface{}, error) {
+		info, ok := core.GetTask(id)
+		if !ok {
+			return nil, fmt.Errorf("task %d not found", id)
+		}
+		return info, nil
+	})
 }
 
-func main() {}
\ No newline at end of file
+func main() {}
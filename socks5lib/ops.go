@@ -0,0 +1,125 @@
+package main
+
+import "C"
+import (
+	core "core"
+	bridge "core/bridge"
+)
+
+// Invoke runs one export by name, as core.Invoke describes. For example
+//
+//	{"method": "StartSocks5Server", "id": 7, "params": {"srvID": 1}}
+//
+// starts server 1 and returns its task id; replies carry request id 7.
+//
+//export Invoke
+func Invoke(requestJSON *C.char, port C.longlong) C.longlong {
+	return C.longlong(core.Invoke([]byte(C.GoString(requestJSON)), int64(port)))
+}
+
+//export ListOperations
+func ListOperations(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_operations", func() (interface{}, error) {
+		return core.Operations(), nil
+	})
+}
+
+func init() {
+	core.Register(core.Operation{Method: "ConfigureQueue", Params: []core.Param{core.IntParam("targetPort"), core.IntParam("capacity"), core.StrParam("policy")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ConfigureQueue(C.longlong(a.Int64("targetPort")), C.int(a.Int("capacity")), (*C.char)(cs.Str(a.String("policy"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetQueueStats"}, func(call core.Call, a core.Args) int64 {
+		GetQueueStats(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogPort", Params: []core.Param{core.IntParam("logPort"), core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogPort(C.longlong(a.Int64("logPort")), (*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogLevel", Params: []core.Param{core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogLevel((*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Subscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Subscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Unsubscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Unsubscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListSubscriptions"}, func(call core.Call, a core.Args) int64 {
+		ListSubscriptions(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Shutdown", Params: []core.Param{core.IntParam("timeoutMs")}}, func(call core.Call, a core.Args) int64 {
+		Shutdown(C.int(a.Int("timeoutMs")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CreateDirectServerTCP", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateDirectServerTCP(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateDirectServerUDP", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateDirectServerUDP(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateProxyToSocks5ServerUDP", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password"), core.StrParam("proxyAddr"), core.StrParam("proxyUser"), core.StrParam("proxyPass")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateProxyToSocks5ServerUDP(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), (*C.char)(cs.Str(a.String("proxyAddr"))), (*C.char)(cs.Str(a.String("proxyUser"))), (*C.char)(cs.Str(a.String("proxyPass"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateWithAuthServer", Params: []core.Param{core.IntParam("listenPort")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		return int64(CreateWithAuthServer(C.int(a.Int("listenPort")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateWithoutAuthServer", Params: []core.Param{core.IntParam("listenPort")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		return int64(CreateWithoutAuthServer(C.int(a.Int("listenPort")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "StartSocks5Server", Params: []core.Param{core.IntParam("srvID")}, Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		return int64(StartSocks5Server(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "StopSocks5Server", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		StopSocks5Server(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetACL", Params: []core.Param{core.IntParam("srvID"), core.ObjectParam("acl")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetACL(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.JSON("acl"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetACL", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		GetACL(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "StopTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		StopTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListTasks"}, func(call core.Call, a core.Args) int64 {
+		ListTasks(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		GetTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListOperations"}, func(call core.Call, a core.Args) int64 {
+		ListOperations(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	core "core"
+)
+
+// Rule is one ACL entry. Every condition that is set must hold for the
+// rule to match; an entry with no conditions matches everything.
+//
+// Domains lists exact names, or suffixes written as ".example.com" or
+// "*.example.com" that match the domain and all its subdomains. CIDRs are
+// checked against IP destinations and against what domain destinations
+// resolve to, resolved the way the server would resolve them to connect;
+// on a server that leaves names to its upstream proxy, domain destinations
+// never match CIDRs. Ports is a list like "80,443,8000-9000". Commands take
+// "connect", "udp" and "bind". Egress, on an allow rule, overrides the
+// server's egress for the requests it matches.
+type Rule struct {
+	Action      string   `json:"action"`
+	CIDRs       []string `json:"cidrs,omitempty"`
+	Domains     []string `json:"domains,omitempty"`
+	DomainRegex string   `json:"domain_regex,omitempty"`
+	Ports       string   `json:"ports,omitempty"`
+	Commands    []string `json:"commands,omitempty"`
+	Users       []string `json:"users,omitempty"`
+	Egress      *Egress  `json:"egress,omitempty"`
+}
+
+// ACLConfig is the JSON accepted by SetACL. Rules are evaluated in order
+// and the first match decides; Default ("allow" when empty) applies when
+// none matches.
+type ACLConfig struct {
+	Default string `json:"default,omitempty"`
+	Rules   []Rule `json:"rules"`
+}
+
+type portRange struct{ lo, hi int }
+
+type aclRule struct {
+	allow    bool
+	nets     []*net.IPNet
+	exact    map[string]bool
+	suffixes []string
+	re       *regexp.Regexp
+	ports    []portRange
+	commands map[string]bool
+	users    map[string]bool
+	egress   *egress
+}
+
+// acl is a compiled ACLConfig. A nil *acl allows everything.
+type acl struct {
+	cfg          ACLConfig
+	rules        []aclRule
+	defaultAllow bool
+}
+
+// aclRequest is what a rule is matched against. Host and port are empty
+// for a UDP ASSOCIATE, whose datagram destinations are checked one by one.
+type aclRequest struct {
+	user    string
+	command string
+	host    string
+	port    int
+
+	lookup   func(host string) ([]net.IP, error) // nil leaves domains unresolved
+	ips      []net.IP
+	resolved bool
+}
+
+// aclDecision is emitted for every evaluated request. Rule is the index
+// of the matching rule, or -1 when the default applied.
+type aclDecision struct {
+	Server  int64  `json:"server"`
+	Client  string `json:"client"`
+	User    string `json:"user,omitempty"`
+	Command string `json:"command"`
+	Target  string `json:"target,omitempty"`
+	Allow   bool   `json:"allow"`
+	Rule    int    `json:"rule"`
+}
+
+var errNotAllowed = errors.New("connection not allowed by ruleset")
+
+func parseAction(s string, empty bool) (bool, error) {
+	switch strings.ToLower(s) {
+	case "allow":
+		return true, nil
+	case "deny":
+		return false, nil
+	case "":
+		return empty, nil
+	}
+	return false, fmt.Errorf("unknown action %q", s)
+}
+
+func parsePorts(s string) ([]portRange, error) {
+	var out []portRange
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(f, "-")
+		a, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", f)
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("invalid port range %q", f)
+			}
+		}
+		if a < 0 || b > 65535 || a > b {
+			return nil, fmt.Errorf("invalid port range %q", f)
+		}
+		out = append(out, portRange{a, b})
+	}
+	return out, nil
+}
+
+func set(ss []string) map[string]bool {
+	if len(ss) == 0 {
+		return nil
+	}
+	m := make(map[string]bool, len(ss))
+	for _, s := range ss {
+		m[strings.ToLower(s)] = true
+	}
+	return m
+}
+
+// parseACL decodes and compiles an ACLConfig.
+func parseACL(s string) (*acl, error) {
+	var cfg ACLConfig
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid acl: %v", err)
+	}
+	a := &acl{cfg: cfg}
+	var err error
+	if a.defaultAllow, err = parseAction(cfg.Default, true); err != nil {
+		return nil, fmt.Errorf("default: %v", err)
+	}
+	for i, r := range cfg.Rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i, err)
+		}
+		a.rules = append(a.rules, cr)
+	}
+	return a, nil
+}
+
+func compileRule(r Rule) (aclRule, error) {
+	var cr aclRule
+	var err error
+	if r.Action == "" {
+		return cr, errors.New("action is required")
+	}
+	if cr.allow, err = parseAction(r.Action, false); err != nil {
+		return cr, err
+	}
+	for _, c := range r.CIDRs {
+		if !strings.Contains(c, "/") {
+			if ip := net.ParseIP(c); ip != nil && ip.To4() != nil {
+				c += "/32"
+			} else {
+				c += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return cr, err
+		}
+		cr.nets = append(cr.nets, n)
+	}
+	for _, d := range r.Domains {
+		d = strings.ToLower(strings.TrimSuffix(d, "."))
+		switch {
+		case strings.HasPrefix(d, "*."):
+			cr.suffixes = append(cr.suffixes, d[1:])
+		case strings.HasPrefix(d, "."):
+			cr.suffixes = append(cr.suffixes, d)
+		default:
+			if cr.exact == nil {
+				cr.exact = map[string]bool{}
+			}
+			cr.exact[d] = true
+		}
+	}
+	if r.DomainRegex != "" {
+		if cr.re, err = regexp.Compile(r.DomainRegex); err != nil {
+			return cr, err
+		}
+	}
+	if cr.ports, err = parsePorts(r.Ports); err != nil {
+		return cr, err
+	}
+	for _, c := range r.Commands {
+		switch strings.ToLower(c) {
+		case "connect", "udp", "bind":
+		default:
+			return cr, fmt.Errorf("unknown command %q", c)
+		}
+	}
+	if r.Egress != nil {
+		if cr.egress, err = newEgress(*r.Egress); err != nil {
+			return cr, fmt.Errorf("egress: %v", err)
+		}
+	}
+	cr.commands = set(r.Commands)
+	// user names are case sensitive
+	if len(r.Users) > 0 {
+		cr.users = make(map[string]bool, len(r.Users))
+		for _, u := range r.Users {
+			cr.users[u] = true
+		}
+	}
+	return cr, nil
+}
+
+func (r *aclRule) hasDestination() bool {
+	return len(r.nets) > 0 || r.exact != nil || len(r.suffixes) > 0 || r.re != nil || len(r.ports) > 0
+}
+
+func (r *aclRule) match(q *aclRequest) bool {
+	if r.commands != nil && !r.commands[q.command] {
+		return false
+	}
+	if r.users != nil && !r.users[q.user] {
+		return false
+	}
+	if !r.hasDestination() {
+		return true
+	}
+	if q.host == "" {
+		return false
+	}
+	if len(r.ports) > 0 && !r.matchPort(q.port) {
+		return false
+	}
+	hasDomain := r.exact != nil || len(r.suffixes) > 0 || r.re != nil
+	if hasDomain || len(r.nets) > 0 {
+		return (hasDomain && r.matchDomain(q)) || (len(r.nets) > 0 && r.matchIP(q))
+	}
+	return true
+}
+
+func (r *aclRule) matchPort(p int) bool {
+	for _, pr := range r.ports {
+		if p >= pr.lo && p <= pr.hi {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *aclRule) matchDomain(q *aclRequest) bool {
+	if net.ParseIP(q.host) != nil {
+		return false
+	}
+	h := strings.ToLower(strings.TrimSuffix(q.host, "."))
+	if r.exact[h] {
+		return true
+	}
+	for _, s := range r.suffixes {
+		if h == s[1:] || strings.HasSuffix(h, s) {
+			return true
+		}
+	}
+	return r.re != nil && r.re.MatchString(h)
+}
+
+func (r *aclRule) matchIP(q *aclRequest) bool {
+	for _, ip := range q.addrs() {
+		for _, n := range r.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addrs returns the destination IPs, resolving a domain once per request.
+func (q *aclRequest) addrs() []net.IP {
+	if !q.resolved {
+		q.resolved = true
+		if ip := net.ParseIP(q.host); ip != nil {
+			q.ips = []net.IP{ip}
+		} else if q.lookup != nil {
+			q.ips, _ = q.lookup(q.host)
+		}
+	}
+	return q.ips
+}
+
+// check returns whether q is allowed and the index of the deciding rule.
+func (a *acl) check(q *aclRequest) (bool, int) {
+	if a == nil {
+		return true, -1
+	}
+	for i := range a.rules {
+		if a.rules[i].match(q) {
+			return a.rules[i].allow, i
+		}
+	}
+	return a.defaultAllow, -1
+}
+
+// aclHolder lets a server's rules be swapped while it runs.
+type aclHolder struct {
+	mu  sync.RWMutex
+	acl *acl
+}
+
+func (h *aclHolder) get() *acl {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.acl
+}
+
+func (h *aclHolder) set(a *acl) {
+	h.mu.Lock()
+	h.acl = a
+	h.mu.Unlock()
+}
+
+// admit is allowed for the request of s, which also takes the egress of
+// the deciding rule.
+func (w *Socks5ServerWrapper) admit(s *session, target string) bool {
+	ok, r := w.decide(s.ctx, s.conn.RemoteAddr(), s.info.User, s.info.Command, target)
+	if ok && r != nil {
+		s.egress = r.egress
+	}
+	return ok
+}
+
+// allowed evaluates the server's ACL for a request to target, which is
+// empty for a UDP ASSOCIATE, and emits the decision. Lookups it needs end
+// with ctx, normally the session's.
+func (w *Socks5ServerWrapper) allowed(ctx context.Context, client net.Addr, user, command, target string) bool {
+	ok, _ := w.decide(ctx, client, user, command, target)
+	return ok
+}
+
+// aclLookup returns how CIDR rules resolve domain targets: with the
+// server's resolver when names are resolved here, with the system one on a
+// direct server without a resolver, which is what dialing would use, and
+// not at all when the upstream proxy resolves names, so that rules never
+// leak a hostname to a resolver the connection itself would not ask.
+// Lookups end with ctx.
+func (w *Socks5ServerWrapper) aclLookup(ctx context.Context) func(host string) ([]net.IP, error) {
+	up, e := w.currentUpstream(), w.serverEgress()
+	if r, local := w.resolveLocally(up); local {
+		return func(host string) ([]net.IP, error) {
+			return r.LookupIP(ctx, host, e)
+		}
+	}
+	if _, direct := up.(directUpstream); direct {
+		return func(host string) ([]net.IP, error) {
+			return systemLookup(ctx, host, e)
+		}
+	}
+	return nil
+}
+
+// decide is allowed, also returning the deciding rule if one matched.
+func (w *Socks5ServerWrapper) decide(ctx context.Context, client net.Addr, user, command, target string) (bool, *aclRule) {
+	a := w.acl.get()
+	if a == nil {
+		return true, nil
+	}
+	q := &aclRequest{user: user, command: command, lookup: w.aclLookup(ctx)}
+	if target != "" {
+		host, p, err := net.SplitHostPort(target)
+		if err != nil {
+			return false, nil
+		}
+		q.host = host
+		q.port, _ = strconv.Atoi(p)
+	}
+	ok, rule := a.check(q)
+	d := aclDecision{
+		Server:  w.ID,
+		Client:  client.String(),
+		User:    user,
+		Command: command,
+		Target:  target,
+		Allow:   ok,
+		Rule:    rule,
+	}
+	w.emit(core.TopicSocks5Connections, core.Resp{Op: "acl_decision", Success: true, Data: d})
+	if rule < 0 {
+		return ok, nil
+	}
+	return ok, &a.rules[rule]
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func mustACL(t *testing.T, s string) *acl {
+	t.Helper()
+	a, err := parseACL(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// lookups maps hosts to the single address a stub resolver returns.
+func lookups(t *testing.T, m map[string]string) func(string) ([]net.IP, error) {
+	return func(host string) ([]net.IP, error) {
+		ip, ok := m[host]
+		if !ok {
+			t.Errorf("unexpected lookup of %q", host)
+			return nil, nil
+		}
+		return []net.IP{net.ParseIP(ip)}, nil
+	}
+}
+
+func TestACLFirstMatchDecides(t *testing.T) {
+	a := mustACL(t, `{
+		"default": "deny",
+		"rules": [
+			{"action": "deny",  "domains": ["bad.example.com"]},
+			{"action": "allow", "domains": [".example.com"]},
+			{"action": "deny",  "cidrs": ["10.0.0.0/8"], "ports": "22"},
+			{"action": "allow", "cidrs": ["10.0.0.0/8"]},
+			{"action": "allow", "commands": ["udp"]},
+			{"action": "allow", "users": ["alice"], "ports": "8000-9000"}
+		]
+	}`)
+	tests := []struct {
+		name  string
+		q     aclRequest
+		allow bool
+		rule  int
+	}{
+		{"earlier deny wins over later allow", aclRequest{command: "connect", host: "bad.example.com", port: 443}, false, 0},
+		{"suffix allow", aclRequest{command: "connect", host: "www.example.com", port: 443}, true, 1},
+		{"suffix matches the bare domain", aclRequest{command: "connect", host: "example.com", port: 80}, true, 1},
+		{"port narrows a deny", aclRequest{command: "connect", host: "10.1.2.3", port: 22}, false, 2},
+		{"next rule after a port miss", aclRequest{command: "connect", host: "10.1.2.3", port: 80}, true, 3},
+		{"command-only rule", aclRequest{command: "udp"}, true, 4},
+		{"user and port rule", aclRequest{user: "alice", command: "connect", host: "192.0.2.1", port: 8080}, true, 5},
+		{"other user falls through", aclRequest{user: "bob", command: "connect", host: "192.0.2.1", port: 8080}, false, -1},
+		{"default", aclRequest{command: "connect", host: "192.0.2.1", port: 80}, false, -1},
+	}
+	for _, tt := range tests {
+		q := tt.q
+		allow, rule := a.check(&q)
+		if allow != tt.allow || rule != tt.rule {
+			t.Errorf("%s: got (%v, %d), want (%v, %d)", tt.name, allow, rule, tt.allow, tt.rule)
+		}
+	}
+}
+
+func TestACLDefaultAllow(t *testing.T) {
+	a := mustACL(t, `{"rules": [{"action": "deny", "ports": "25"}]}`)
+	if ok, rule := a.check(&aclRequest{command: "connect", host: "192.0.2.1", port: 80}); !ok || rule != -1 {
+		t.Fatalf("got (%v, %d), want default allow", ok, rule)
+	}
+	if ok, _ := (*acl)(nil).check(&aclRequest{command: "connect", host: "192.0.2.1", port: 25}); !ok {
+		t.Fatal("nil acl refused a request")
+	}
+}
+
+func TestACLDomainAgainstCIDR(t *testing.T) {
+	a := mustACL(t, `{"rules": [{"action": "deny", "cidrs": ["10.0.0.0/8"]}]}`)
+	q := &aclRequest{command: "connect", host: "intranet.test", port: 80,
+		lookup: lookups(t, map[string]string{"intranet.test": "10.9.9.9"})}
+	if ok, rule := a.check(q); ok || rule != 0 {
+		t.Fatalf("resolved domain: got (%v, %d), want deny by rule 0", ok, rule)
+	}
+	// without a lookup, as on a server whose upstream resolves names
+	q = &aclRequest{command: "connect", host: "intranet.test", port: 80}
+	if ok, rule := a.check(q); !ok || rule != -1 {
+		t.Fatalf("unresolved domain: got (%v, %d), want default allow", ok, rule)
+	}
+}
+
+func TestACLResolvesOncePerRequest(t *testing.T) {
+	a := mustACL(t, `{"rules": [
+		{"action": "deny", "cidrs": ["10.0.0.0/8"]},
+		{"action": "deny", "cidrs": ["172.16.0.0/12"]}
+	]}`)
+	n := 0
+	q := &aclRequest{command: "connect", host: "example.test", port: 80, lookup: func(string) ([]net.IP, error) {
+		n++
+		return []net.IP{net.ParseIP("192.0.2.1")}, nil
+	}}
+	a.check(q)
+	if n != 1 {
+		t.Fatalf("resolved %d times, want 1", n)
+	}
+}
+
+func TestACLLookupFollowsUpstream(t *testing.T) {
+	w := newTestServer(t, "127.0.0.1:0")
+	if w.aclLookup(context.Background()) == nil {
+		t.Fatal("direct server without a resolver does not resolve")
+	}
+	w.mu.Lock()
+	w.up = &chainUpstream{hops: []Hop{{Addr: "127.0.0.1:1"}}}
+	w.mu.Unlock()
+	if w.aclLookup(context.Background()) != nil {
+		t.Fatal("proxied server resolves domains for CIDR rules")
+	}
+}
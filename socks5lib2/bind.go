@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	socks5 "github.com/txthinking/socks5"
+)
+
+// bind serves a SOCKS5 BIND: the first reply tells the client where the
+// peer should connect, the second who connected, then both are relayed.
+func (w *Socks5ServerWrapper) bind(s *session, c net.Conn, r *socks5.Request) error {
+	b, err := w.currentUpstream().bind(r.Address(), w.egressFor(s))
+	if err != nil {
+		writeReply(c, socks5.RepServerFailure)
+		return err
+	}
+	s.attach(b)
+	defer b.Close()
+	addr, err := bindReplyAddr(c, b)
+	if err != nil {
+		writeReply(c, socks5.RepServerFailure)
+		return err
+	}
+	if err := writeBoundReply(c, addr.String()); err != nil {
+		return err
+	}
+	pc, from, err := w.acceptBind(s, c, b, r.Address())
+	if err != nil {
+		rep := socks5.RepNotAllowed
+		var ne net.Error
+		if errors.As(err, &ne) && ne.Timeout() {
+			rep = socks5.RepTTLExpired
+		}
+		writeReply(c, rep)
+		return err
+	}
+	defer pc.Close()
+	if err := writeBoundReply(c, from); err != nil {
+		return err
+	}
+	relay(s, c, pc)
+	return nil
+}
+
+// acceptBind waits for the peer of a BIND requested for want. When want
+// names an IP the peer must come from it, and the peer must pass the ACL
+// as a "bind" to its own address.
+func (w *Socks5ServerWrapper) acceptBind(s *session, c net.Conn, b binding, want string) (net.Conn, string, error) {
+	pc, from, err := b.Accept()
+	if err != nil {
+		return nil, "", err
+	}
+	if host, _, err := net.SplitHostPort(want); err == nil {
+		if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() && !sameHost(from, ip) {
+			pc.Close()
+			return nil, "", fmt.Errorf("bind peer %s is not %s", from, host)
+		}
+	}
+	if !w.allowed(s.ctx, c.RemoteAddr(), s.info.User, "bind", from) {
+		pc.Close()
+		return nil, "", errNotAllowed
+	}
+	return pc, from, nil
+}
+
+// bindReplyAddr is the binding's address with an unspecified IP replaced
+// by the one the client reached us at.
+func bindReplyAddr(c net.Conn, b binding) (*net.TCPAddr, error) {
+	a, err := b.Addr()
+	if err != nil {
+		return nil, err
+	}
+	out := *a
+	if out.IP == nil || out.IP.IsUnspecified() {
+		out.IP = c.LocalAddr().(*net.TCPAddr).IP
+	}
+	return &out, nil
+}
+
+func sameHost(addr string, ip net.IP) bool {
+	host, _, err := net.SplitHostPort(addr)
+	return err == nil && net.ParseIP(host).Equal(ip)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	socks5 "github.com/txthinking/socks5"
+)
+
+// Hop is one upstream SOCKS5 proxy of a chain.
+type Hop struct {
+	Addr string `json:"addr"`
+	User string `json:"user,omitempty"`
+	Pass string `json:"pass,omitempty"`
+}
+
+// HopError tells which hop of a chain failed. Hop is 1-based.
+type HopError struct {
+	Hop  int
+	Addr string
+	Err  error
+}
+
+func (e *HopError) Error() string {
+	return fmt.Sprintf("hop %d (%s): %v", e.Hop, e.Addr, e.Err)
+}
+
+func (e *HopError) Unwrap() error { return e.Err }
+
+// parseHops decodes a JSON array of {"addr", "user", "pass"} objects.
+func parseHops(s string) ([]Hop, error) {
+	var hops []Hop
+	if err := json.Unmarshal([]byte(s), &hops); err != nil {
+		return nil, fmt.Errorf("invalid hops: %v", err)
+	}
+	if len(hops) == 0 {
+		return nil, errors.New("at least one hop is required")
+	}
+	for i, h := range hops {
+		if _, _, err := net.SplitHostPort(h.Addr); err != nil {
+			return nil, fmt.Errorf("hop %d: %v", i+1, err)
+		}
+	}
+	return hops, nil
+}
+
+// dialChain opens a TCP connection to the first hop and tunnels through
+// every following hop with CONNECT. The last hop gets cmd for dst; its
+// reply is returned along with the connection.
+func dialChain(hops []Hop, cmd byte, dst string, e *egress) (net.Conn, *socks5.Reply, error) {
+	conn, err := e.dial("tcp", hops[0].Addr)
+	if err != nil {
+		return nil, nil, &HopError{Hop: 1, Addr: hops[0].Addr, Err: err}
+	}
+	for i, hop := range hops {
+		c, d := socks5.CmdConnect, dst
+		if i+1 < len(hops) {
+			d = hops[i+1].Addr
+		} else {
+			c = cmd
+		}
+		rp, err := hopRequest(conn, hop, c, d)
+		if err != nil {
+			conn.Close()
+			return nil, nil, &HopError{Hop: i + 1, Addr: hop.Addr, Err: err}
+		}
+		if i+1 == len(hops) {
+			return conn, rp, nil
+		}
+	}
+	return conn, nil, nil
+}
+
+// replyError is a request the proxy understood but refused, as opposed to
+// a proxy that could not be reached or talked to.
+type replyError struct {
+	dst string
+	rep byte
+}
+
+func (e *replyError) Error() string {
+	return fmt.Sprintf("request to %s refused with reply %#x", e.dst, e.rep)
+}
+
+// hopRequest authenticates with hop over conn and sends one request.
+func hopRequest(conn net.Conn, hop Hop, cmd byte, dst string) (*socks5.Reply, error) {
+	if err := hopAuth(conn, hop); err != nil {
+		return nil, err
+	}
+	a, h, p, err := socks5.ParseAddress(dst)
+	if err != nil {
+		return nil, err
+	}
+	if a == socks5.ATYPDomain {
+		h = h[1:]
+	}
+	if _, err := socks5.NewRequest(cmd, a, h, p).WriteTo(conn); err != nil {
+		return nil, err
+	}
+	rp, err := socks5.NewReplyFrom(conn)
+	if err != nil {
+		return nil, err
+	}
+	if rp.Rep != socks5.RepSuccess {
+		return nil, &replyError{dst: dst, rep: rp.Rep}
+	}
+	return rp, nil
+}
+
+// hopAuth runs method negotiation and authentication with hop.
+func hopAuth(conn net.Conn, hop Hop) error {
+	m := socks5.MethodNone
+	if hop.User != "" && hop.Pass != "" {
+		m = socks5.MethodUsernamePassword
+	}
+	if _, err := socks5.NewNegotiationRequest([]byte{m}).WriteTo(conn); err != nil {
+		return err
+	}
+	nrp, err := socks5.NewNegotiationReplyFrom(conn)
+	if err != nil {
+		return err
+	}
+	if nrp.Method != m {
+		return fmt.Errorf("method %#x not accepted", m)
+	}
+	if m == socks5.MethodUsernamePassword {
+		urq := socks5.NewUserPassNegotiationRequest([]byte(hop.User), []byte(hop.Pass))
+		if _, err := urq.WriteTo(conn); err != nil {
+			return err
+		}
+		urp, err := socks5.NewUserPassNegotiationReplyFrom(conn)
+		if err != nil {
+			return err
+		}
+		if urp.Status != socks5.UserPassStatusSuccess {
+			return socks5.ErrUserPassAuth
+		}
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	core "core"
+	bridge "core/bridge"
+	socks5 "github.com/txthinking/socks5"
+)
+
+// ClientSessionInfo describes one open client session.
+type ClientSessionInfo struct {
+	ID       int64     `json:"id"`
+	Network  string    `json:"network"`
+	Proxy    string    `json:"proxy"`
+	Target   string    `json:"target"`
+	BytesIn  int64     `json:"bytes_in"`
+	BytesOut int64     `json:"bytes_out"`
+	Opened   time.Time `json:"opened"`
+}
+
+// clientSession is a TCP connection or UDP association to one target,
+// opened through a SOCKS5 proxy on Dart's behalf. What the target sends
+// is posted to the opening call's port as client_data stream frames that
+// carry the session id in place of a request id; writes are
+// queued and sent in order by one goroutine, so ClientWrite never waits
+// on the network.
+type clientSession struct {
+	info    ClientSessionInfo
+	call    core.Call
+	conn    io.Closer
+	read    func(b []byte) ([]byte, error) // next chunk, which aliases b
+	write   func(b []byte) error
+	sends   chan []byte // a nil entry ends the session once sent
+	done    chan struct{}
+	mu      sync.Mutex
+	ending  bool
+	senders sync.WaitGroup // sends waiting for room in sends
+	once    sync.Once
+	in, out int64 // atomic
+}
+
+const (
+	clientReadSize = 64 << 10
+	// writes queued beyond this make ClientWrite wait
+	clientSendQueue = 256
+)
+
+var (
+	errClientClosed  = errors.New("client session closed")
+	errPortQueueFull = errors.New("port queue full, received data dropped")
+)
+
+var (
+	clientsMu  sync.Mutex
+	clients    = map[int64]*clientSession{}
+	nextClient int64
+)
+
+// checkClientTarget is what openClient would refuse without dialing.
+func checkClientTarget(network, target string) error {
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return core.WithCode(core.CodeInvalidArg, fmt.Errorf("target: %v", err))
+	}
+	switch network {
+	case "tcp":
+	case "udp":
+		if _, _, _, err := socks5.ParseAddress(target); err != nil {
+			return core.WithCode(core.CodeInvalidArg, fmt.Errorf("target: %v", err))
+		}
+	default:
+		return core.WithCode(core.CodeInvalidArg, fmt.Errorf("network must be tcp or udp, got %q", network))
+	}
+	return nil
+}
+
+// connectUntil runs connect, giving up when ctx ends first; a connection
+// it still makes after that is closed.
+func connectUntil(ctx context.Context, connect func() (io.Closer, error)) (io.Closer, error) {
+	type result struct {
+		c   io.Closer
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		c, err := connect()
+		ch <- result{c, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.c, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-ch; r.err == nil {
+				r.c.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// openClient connects to target through hops, a single proxy being a
+// one-hop chain, and registers the session. network is "tcp" or "udp".
+// Connecting stops when ctx ends. Nothing is streamed before start.
+func openClient(ctx context.Context, call core.Call, hops []Hop, network, target string) (*clientSession, error) {
+	if err := checkClientTarget(network, target); err != nil {
+		return nil, err
+	}
+	up := &chainUpstream{hops: hops}
+	s := &clientSession{
+		info:  ClientSessionInfo{Network: network, Proxy: hops[0].Addr, Target: target, Opened: time.Now()},
+		call:  call,
+		sends: make(chan []byte, clientSendQueue),
+		done:  make(chan struct{}),
+	}
+	switch network {
+	case "tcp":
+		cl, err := connectUntil(ctx, func() (io.Closer, error) { return up.dial(target, nil) })
+		if err != nil {
+			return nil, err
+		}
+		c := cl.(net.Conn)
+		s.conn = c
+		s.read = func(b []byte) ([]byte, error) {
+			n, err := c.Read(b)
+			return b[:n], err
+		}
+		s.write = func(b []byte) error {
+			_, err := c.Write(b)
+			return err
+		}
+	case "udp":
+		a, h, p, _ := socks5.ParseAddress(target)
+		if a == socks5.ATYPDomain {
+			h = h[1:]
+		}
+		cl, err := connectUntil(ctx, func() (io.Closer, error) { return up.associate(nil) })
+		if err != nil {
+			return nil, err
+		}
+		r := cl.(datagramRelay)
+		s.conn = r
+		s.read = func(b []byte) ([]byte, error) {
+			for {
+				pkt, n, err := r.recv(b)
+				if err == nil {
+					return pkt[len(pkt)-n:], nil
+				}
+				// a malformed datagram is skipped, a dead socket ends it all
+				var ne net.Error
+				if errors.As(err, &ne) || errors.Is(err, net.ErrClosed) {
+					return nil, err
+				}
+			}
+		}
+		s.write = func(b []byte) error {
+			_, err := r.send(socks5.NewDatagram(a, h, p, b))
+			return err
+		}
+	}
+
+	clientsMu.Lock()
+	nextClient++
+	s.info.ID = nextClient
+	clients[s.info.ID] = s
+	clientsMu.Unlock()
+	return s, nil
+}
+
+func (s *clientSession) start() {
+	go s.readLoop()
+	go s.writeLoop()
+}
+
+func lookupClient(id int64) (*clientSession, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	s, ok := clients[id]
+	if !ok {
+		return nil, core.WithCode(core.CodeNotFound, fmt.Errorf("client session %d not found", id))
+	}
+	return s, nil
+}
+
+func listClients() []ClientSessionInfo {
+	clientsMu.Lock()
+	out := make([]ClientSessionInfo, 0, len(clients))
+	for _, s := range clients {
+		out = append(out, s.snapshot())
+	}
+	clientsMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// closeClients drops every session, for shutdown.
+func closeClients(ctx context.Context) []string {
+	clientsMu.Lock()
+	all := make([]*clientSession, 0, len(clients))
+	for _, s := range clients {
+		all = append(all, s)
+	}
+	clientsMu.Unlock()
+	for _, s := range all {
+		s.close(errClientClosed)
+	}
+	return nil
+}
+
+func (s *clientSession) snapshot() ClientSessionInfo {
+	info := s.info
+	info.BytesIn = atomic.LoadInt64(&s.in)
+	info.BytesOut = atomic.LoadInt64(&s.out)
+	return info
+}
+
+// readLoop posts what the target sends until it stops. A port queue that
+// refuses a frame ends the session rather than leave a gap in the stream.
+func (s *clientSession) readLoop() {
+	buf := make([]byte, clientReadSize)
+	h := bridge.FrameHeader{Op: "client_data", RequestID: s.info.ID, Kind: bridge.PayloadSocketData}
+	for {
+		chunk, err := s.read(buf)
+		if len(chunk) > 0 {
+			atomic.AddInt64(&s.in, int64(len(chunk)))
+			if !bridge.SendStreamFrameToPort(s.call.Port, h, append([]byte(nil), chunk...)) {
+				s.close(errPortQueueFull)
+				return
+			}
+		}
+		if err != nil {
+			s.close(err)
+			return
+		}
+	}
+}
+
+func (s *clientSession) writeLoop() {
+	for {
+		select {
+		case b := <-s.sends:
+			if b == nil {
+				s.close(nil)
+				return
+			}
+			if err := s.write(b); err != nil {
+				s.close(err)
+				return
+			}
+			atomic.AddInt64(&s.out, int64(len(b)))
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// send queues b to be written after everything queued before it. It
+// waits for room without holding s.mu, so a stalled session never blocks
+// end or the checks of other writes.
+func (s *clientSession) send(b []byte) error {
+	s.mu.Lock()
+	if s.ending {
+		s.mu.Unlock()
+		return errClientClosed
+	}
+	s.senders.Add(1)
+	s.mu.Unlock()
+	defer s.senders.Done()
+	select {
+	case s.sends <- b:
+		return nil
+	case <-s.done:
+		return errClientClosed
+	}
+}
+
+// end closes the session once the queued writes are sent, those still
+// waiting for room included. It does not wait for any of them.
+func (s *clientSession) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ending {
+		return
+	}
+	s.ending = true
+	go func() {
+		s.senders.Wait()
+		select {
+		case s.sends <- nil:
+		case <-s.done:
+		}
+	}()
+}
+
+// close tears the session down and posts client_closed. A nil err or EOF
+// is a normal end.
+func (s *clientSession) close(err error) {
+	s.once.Do(func() {
+		close(s.done)
+		s.conn.Close()
+		clientsMu.Lock()
+		delete(clients, s.info.ID)
+		clientsMu.Unlock()
+		info := s.snapshot()
+		r := core.Resp{Op: "client_closed", Success: true, Data: map[string]interface{}{
+			"session":   info.ID,
+			"bytes_in":  info.BytesIn,
+			"bytes_out": info.BytesOut,
+		}}
+		if err != nil && err != io.EOF {
+			r.Success, r.Error = false, err.Error()
+		}
+		s.call.Send(r)
+	})
+}
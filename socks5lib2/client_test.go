@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestEndDoesNotWaitForStalledSend(t *testing.T) {
+	s := &clientSession{sends: make(chan []byte, 1), done: make(chan struct{})}
+	s.sends <- []byte("queued")
+	blocked := make(chan error, 1)
+	go func() { blocked <- s.send([]byte("waiting")) }()
+	time.Sleep(20 * time.Millisecond)
+
+	ended := make(chan struct{})
+	go func() {
+		s.end()
+		close(ended)
+	}()
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("end waited for a send stuck on a full queue")
+	}
+	if err := s.send([]byte("late")); err != errClientClosed {
+		t.Fatalf("send after end: %v", err)
+	}
+
+	// the waiting write still goes out before the end of the session
+	for _, want := range []string{"queued", "waiting"} {
+		if b := <-s.sends; string(b) != want {
+			t.Fatalf("got %q, want %q", b, want)
+		}
+	}
+	if err := <-blocked; err != nil {
+		t.Fatal(err)
+	}
+	if b := <-s.sends; b != nil {
+		t.Fatalf("got %q, want the end of the session", b)
+	}
+}
+
+type closeSignal struct{ closed chan struct{} }
+
+func (c closeSignal) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestConnectUntilGivesUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	c := closeSignal{closed: make(chan struct{})}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err := connectUntil(ctx, func() (io.Closer, error) {
+		<-release
+		return c, nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	select {
+	case <-c.closed:
+	case <-time.After(time.Second):
+		t.Fatal("connection made after giving up was not closed")
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	socks5 "github.com/txthinking/socks5"
+)
+
+// ServerConfig is the JSON accepted by CreateServerFromConfig. Only Port
+// is required.
+//
+// BindIP is the IPv4 or IPv6 address to listen on, all interfaces when
+// empty. PublicIP is the address announced to UDP ASSOCIATE clients,
+// for servers behind NAT. Timeouts close relays that moved no data for
+// that long. Chain and Pool are mutually exclusive; with neither the
+// server connects to targets itself. ACL, Limits, DNS, Egress and Guard
+// take the same JSON as SetACL, SetBandwidthLimits, SetDNSConfig,
+// SetEgress and SetGuard.
+type ServerConfig struct {
+	BindIP       string          `json:"bind_ip,omitempty"`
+	Port         int             `json:"port"`
+	PublicIP     string          `json:"public_ip,omitempty"`
+	TCPTimeoutMs int             `json:"tcp_timeout_ms,omitempty"`
+	UDPTimeoutMs int             `json:"udp_timeout_ms,omitempty"`
+	Auth         AuthConfig      `json:"auth"`
+	Handler      HandlerConfig   `json:"handler"`
+	Chain        []Hop           `json:"chain,omitempty"`
+	Pool         json.RawMessage `json:"pool,omitempty"`
+	ACL          json.RawMessage `json:"acl,omitempty"`
+	Limits       json.RawMessage `json:"limits,omitempty"`
+	DNS          json.RawMessage `json:"dns,omitempty"`
+	Egress       json.RawMessage `json:"egress,omitempty"`
+	Guard        json.RawMessage `json:"guard,omitempty"`
+}
+
+// AuthConfig selects how clients authenticate. Mode is "none" or
+// "password"; when empty it is "password" if any users are given. Users
+// from UsersFile (see LoadUsersFile) are loaded first, then Users.
+type AuthConfig struct {
+	Mode      string            `json:"mode,omitempty"`
+	Users     map[string]string `json:"users,omitempty"`
+	UsersFile string            `json:"users_file,omitempty"`
+}
+
+// HandlerConfig picks what the listener serves. Protocols take "socks5",
+// "socks4" and "http", Commands "connect", "udp" and "bind". An empty
+// Commands means all of them; an empty Protocols means "socks5" only, so
+// "socks4" and "http" must be listed to be served.
+type HandlerConfig struct {
+	Protocols []string `json:"protocols,omitempty"`
+	Commands  []string `json:"commands,omitempty"`
+}
+
+// FieldError is one problem in a ServerConfig. Field is a path like
+// "chain[2].addr".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string { return e.Field + ": " + e.Message }
+
+// ConfigError lists every problem found in a ServerConfig.
+type ConfigError []FieldError
+
+func (e ConfigError) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+var (
+	allProtocols = []string{"socks5", "socks4", "http"}
+	allCommands  = []string{"connect", "udp", "bind"}
+)
+
+// serverSetup is a validated ServerConfig, ready to be applied.
+type serverSetup struct {
+	cfg      ServerConfig
+	fields   map[string]bool // top-level keys present in the JSON
+	addr     string
+	publicIP net.IP
+	tcpIdle  time.Duration
+	udpIdle  time.Duration
+	users    *userStore
+	off      map[string]bool
+	hops     []Hop
+	pool     *PoolConfig
+	acl      *acl
+	limits   *ShapingConfig
+	dns      *resolver
+	egress   *egress
+	guard    GuardConfig
+}
+
+// parseServerConfig decodes and checks s, reporting every bad field at
+// once as a ConfigError. A partial config, as taken by UpdateServerConfig,
+// may leave out the port.
+func parseServerConfig(s string, partial bool) (*serverSetup, error) {
+	var cfg ServerConfig
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, ConfigError{decodeFieldError(err)}
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &keys); err != nil {
+		return nil, ConfigError{decodeFieldError(err)}
+	}
+	var errs ConfigError
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{field, fmt.Sprintf(format, args...)})
+	}
+	st := &serverSetup{cfg: cfg, fields: make(map[string]bool, len(keys))}
+	for k := range keys {
+		st.fields[k] = true
+	}
+
+	if (!partial || st.fields["port"]) && (cfg.Port < 1 || cfg.Port > 65535) {
+		fail("port", "must be between 1 and 65535")
+	}
+	if cfg.BindIP != "" && net.ParseIP(cfg.BindIP) == nil {
+		fail("bind_ip", "%q is not an IP address", cfg.BindIP)
+	}
+	st.addr = net.JoinHostPort(cfg.BindIP, strconv.Itoa(cfg.Port))
+	if cfg.PublicIP != "" {
+		if st.publicIP = net.ParseIP(cfg.PublicIP); st.publicIP == nil || st.publicIP.IsUnspecified() {
+			fail("public_ip", "%q is not a usable IP address", cfg.PublicIP)
+		}
+	}
+	if cfg.TCPTimeoutMs < 0 {
+		fail("tcp_timeout_ms", "must not be negative")
+	}
+	if cfg.UDPTimeoutMs < 0 {
+		fail("udp_timeout_ms", "must not be negative")
+	}
+	st.tcpIdle = time.Duration(cfg.TCPTimeoutMs) * time.Millisecond
+	st.udpIdle = time.Duration(cfg.UDPTimeoutMs) * time.Millisecond
+
+	st.users = newUserStore()
+	if cfg.Auth.UsersFile != "" {
+		users, err := loadUsersFile(cfg.Auth.UsersFile)
+		if err != nil {
+			fail("auth.users_file", "%v", err)
+		} else {
+			st.users = users
+		}
+	}
+	for user, pass := range cfg.Auth.Users {
+		if err := st.users.add(user, pass); err != nil {
+			fail("auth.users."+user, "%v", err)
+		}
+	}
+	switch cfg.Auth.Mode {
+	case "":
+	case "none":
+		if len(cfg.Auth.Users) > 0 || cfg.Auth.UsersFile != "" {
+			fail("auth.mode", `"none" takes no users`)
+		}
+	case "password":
+		if st.users.empty() {
+			fail("auth.users", "password auth needs at least one user")
+		}
+	default:
+		fail("auth.mode", "unknown mode %q", cfg.Auth.Mode)
+	}
+
+	st.off = defaultOff()
+	offFor := func(field string, names, all []string) {
+		if len(names) == 0 {
+			return
+		}
+		on := set(names)
+		for _, n := range names {
+			if !contains(all, strings.ToLower(n)) {
+				fail(field, "unknown value %q", n)
+			}
+		}
+		for _, n := range all {
+			st.off[n] = !on[n]
+		}
+	}
+	offFor("handler.protocols", cfg.Handler.Protocols, allProtocols)
+	offFor("handler.commands", cfg.Handler.Commands, allCommands)
+
+	if len(cfg.Chain) > 0 && isSet(cfg.Pool) {
+		fail("pool", "chain and pool cannot both be set")
+	}
+	for i, h := range cfg.Chain {
+		if _, _, err := net.SplitHostPort(h.Addr); err != nil {
+			fail(fmt.Sprintf("chain[%d].addr", i), "%v", err)
+		}
+	}
+	st.hops = cfg.Chain
+	if isSet(cfg.Pool) {
+		if p, err := parsePool(string(cfg.Pool)); err != nil {
+			fail("pool", "%v", err)
+		} else {
+			st.pool = &p
+		}
+	}
+	if isSet(cfg.ACL) {
+		if a, err := parseACL(string(cfg.ACL)); err != nil {
+			fail("acl", "%v", err)
+		} else {
+			st.acl = a
+		}
+	}
+	if isSet(cfg.Limits) {
+		if l, err := parseShaping(string(cfg.Limits)); err != nil {
+			fail("limits", "%v", err)
+		} else {
+			st.limits = &l
+		}
+	}
+	if isSet(cfg.DNS) {
+		if r, err := parseDNS(string(cfg.DNS)); err != nil {
+			fail("dns", "%v", err)
+		} else {
+			st.dns = r
+		}
+	}
+	if isSet(cfg.Egress) {
+		if e, err := parseEgress(string(cfg.Egress)); err != nil {
+			fail("egress", "%v", err)
+		} else {
+			st.egress = e
+		}
+	}
+	if isSet(cfg.Guard) {
+		if g, err := parseGuard(string(cfg.Guard)); err != nil {
+			fail("guard", "%v", err)
+		} else {
+			st.guard = g
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return st, nil
+}
+
+// decodeFieldError names the field a JSON decoding error is about, when
+// encoding/json says.
+func decodeFieldError(err error) FieldError {
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) && te.Field != "" {
+		return FieldError{te.Field, fmt.Sprintf("expected %s, got %s", te.Type, te.Value)}
+	}
+	if f, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return FieldError{strings.Trim(f, `"`), "unknown field"}
+	}
+	return FieldError{"", err.Error()}
+}
+
+func isSet(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && !bytes.Equal(raw, []byte("null"))
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// upstream returns the upstream the setup asks for.
+func (st *serverSetup) upstream() upstream {
+	switch {
+	case st.pool != nil:
+		return newPoolUpstream(*st.pool)
+	case len(st.hops) > 0:
+		return &chainUpstream{hops: st.hops}
+	}
+	return directUpstream{}
+}
+
+// newServerFromSetup builds a server from st; it is not registered yet.
+func newServerFromSetup(st *serverSetup) (*Socks5ServerWrapper, error) {
+	server, err := socks5.NewClassicServer(st.addr, "", "", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	up := st.upstream()
+	w, err := newServerWrapper(server, up)
+	if err != nil {
+		return nil, err
+	}
+	w.users.replace(st.users)
+	w.mu.Lock()
+	w.off = st.off
+	w.tcpIdle, w.udpIdle = st.tcpIdle, st.udpIdle
+	w.publicIP = st.publicIP
+	w.egress = st.egress
+	w.mu.Unlock()
+	w.acl.set(st.acl)
+	w.dns.set(st.dns)
+	w.guard.set(st.guard)
+	if st.limits != nil {
+		w.SetShaping(*st.limits)
+	}
+	if p, ok := up.(*poolUpstream); ok {
+		p.start(w.upstreamEvent, w.serverEgress)
+	}
+	return w, nil
+}
+
+// ConfigUpdate is the reply to UpdateServerConfig. Applied lists the
+// fields now in effect for new connections, RestartRequired those that
+// only take effect when the server is created again.
+type ConfigUpdate struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+// update puts the fields present in st into effect. Live sessions keep
+// the users, upstream and timeouts they started with.
+func (w *Socks5ServerWrapper) update(st *serverSetup) ConfigUpdate {
+	u := ConfigUpdate{Applied: []string{}}
+	has := st.fields
+	applied := func(field string) { u.Applied = append(u.Applied, field) }
+
+	// the listening sockets are bound once, by serve
+	host, port, _ := net.SplitHostPort(w.Server.Addr)
+	if has["bind_ip"] && !sameIP(st.cfg.BindIP, host) {
+		u.RestartRequired = append(u.RestartRequired, "bind_ip")
+	}
+	if has["port"] && strconv.Itoa(st.cfg.Port) != port {
+		u.RestartRequired = append(u.RestartRequired, "port")
+	}
+
+	var newUp, oldUp upstream
+	if has["chain"] || has["pool"] {
+		newUp = st.upstream()
+	}
+	w.mu.Lock()
+	if has["public_ip"] {
+		w.publicIP = st.publicIP
+		applied("public_ip")
+	}
+	if has["tcp_timeout_ms"] {
+		w.tcpIdle = st.tcpIdle
+		applied("tcp_timeout_ms")
+	}
+	if has["udp_timeout_ms"] {
+		w.udpIdle = st.udpIdle
+		applied("udp_timeout_ms")
+	}
+	if has["handler"] {
+		w.off = st.off
+		applied("handler")
+	}
+	if has["egress"] {
+		w.egress = st.egress
+		applied("egress")
+	}
+	closed := w.closed
+	if newUp != nil && !closed {
+		oldUp, w.up = w.up, newUp
+	}
+	w.mu.Unlock()
+
+	if has["auth"] {
+		w.users.replace(st.users)
+		applied("auth")
+	}
+	if newUp != nil {
+		if closed {
+			// nothing will use it, and a pool would keep probing
+			oldUp = newUp
+		} else if p, ok := newUp.(*poolUpstream); ok {
+			p.start(w.upstreamEvent, w.serverEgress)
+		}
+		// connections already made through the old upstream stay up
+		if c, ok := oldUp.(io.Closer); ok {
+			c.Close()
+		}
+		for _, f := range []string{"chain", "pool"} {
+			if has[f] {
+				applied(f)
+			}
+		}
+	}
+	if has["acl"] {
+		w.acl.set(st.acl)
+		applied("acl")
+	}
+	if has["limits"] {
+		var cfg ShapingConfig
+		if st.limits != nil {
+			cfg = *st.limits
+		}
+		w.SetShaping(cfg)
+		applied("limits")
+	}
+	if has["dns"] {
+		w.dns.set(st.dns)
+		applied("dns")
+	}
+	if has["guard"] {
+		w.guard.set(st.guard)
+		applied("guard")
+	}
+	return u
+}
+
+// sameIP compares two addresses as written in a config, "" being any.
+func sameIP(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ia, ib := net.ParseIP(a), net.ParseIP(b)
+	return ia != nil && ib != nil && ia.Equal(ib)
+}
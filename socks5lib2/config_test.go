@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func configFields(t *testing.T, err error) []string {
+	t.Helper()
+	var ce ConfigError
+	if !errors.As(err, &ce) {
+		t.Fatalf("got %v, want a ConfigError", err)
+	}
+	out := make([]string, len(ce))
+	for i, fe := range ce {
+		out[i] = fe.Field
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestParseServerConfigFieldErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		partial bool
+		fields  []string
+	}{
+		{"missing port", `{}`, false, []string{"port"}},
+		{"port out of range", `{"port": 70000}`, false, []string{"port"}},
+		{"partial may leave out port", `{"tcp_timeout_ms": 1000}`, true, nil},
+		{"partial checks a given port", `{"port": 0}`, true, []string{"port"}},
+		{"bad bind ip", `{"port": 1080, "bind_ip": "nope"}`, false, []string{"bind_ip"}},
+		{"unspecified public ip", `{"port": 1080, "public_ip": "0.0.0.0"}`, false, []string{"public_ip"}},
+		{"negative timeouts", `{"port": 1080, "tcp_timeout_ms": -1, "udp_timeout_ms": -1}`, false, []string{"tcp_timeout_ms", "udp_timeout_ms"}},
+		{"unknown auth mode", `{"port": 1080, "auth": {"mode": "token"}}`, false, []string{"auth.mode"}},
+		{"none with users", `{"port": 1080, "auth": {"mode": "none", "users": {"a": "b"}}}`, false, []string{"auth.mode"}},
+		{"password without users", `{"port": 1080, "auth": {"mode": "password"}}`, false, []string{"auth.users"}},
+		{"unknown protocol and command", `{"port": 1080, "handler": {"protocols": ["ftp"], "commands": ["listen"]}}`, false, []string{"handler.commands", "handler.protocols"}},
+		{"chain and pool", `{"port": 1080, "chain": [{"addr": "127.0.0.1:1"}], "pool": {"upstreams": [{"addr": "127.0.0.1:2"}]}}`, false, []string{"pool"}},
+		{"bad hop", `{"port": 1080, "chain": [{"addr": "127.0.0.1:1"}, {"addr": "nohost"}]}`, false, []string{"chain[1].addr"}},
+		{"bad acl", `{"port": 1080, "acl": {"rules": [{"action": "maybe"}]}}`, false, []string{"acl"}},
+		{"negative limits", `{"port": 1080, "limits": {"server": {"upload_bps": -1}}}`, false, []string{"limits"}},
+		{"bad dns mode", `{"port": 1080, "dns": {"mode": "carrier-pigeon"}}`, false, []string{"dns"}},
+		{"bad egress", `{"port": 1080, "egress": {"ip": "nope"}}`, false, []string{"egress"}},
+		{"negative guard", `{"port": 1080, "guard": {"max_failures": -1}}`, false, []string{"guard"}},
+		{"every error at once", `{"port": 0, "bind_ip": "x", "tcp_timeout_ms": -5}`, false, []string{"bind_ip", "port", "tcp_timeout_ms"}},
+		{"unknown field", `{"port": 1080, "colour": "blue"}`, false, []string{"colour"}},
+		{"wrong type", `{"port": "1080"}`, false, []string{"port"}},
+	}
+	for _, tt := range tests {
+		_, err := parseServerConfig(tt.json, tt.partial)
+		if tt.fields == nil {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if got := configFields(t, err); !reflect.DeepEqual(got, tt.fields) {
+			t.Errorf("%s: fields %v, want %v", tt.name, got, tt.fields)
+		}
+	}
+}
+
+func TestUpdateReportsRestartRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		applied []string
+		restart []string
+	}{
+		{"same address", `{"bind_ip": "127.0.0.1", "port": 1080}`, []string{}, nil},
+		{"same address written differently", `{"bind_ip": "::ffff:127.0.0.1"}`, []string{}, nil},
+		{"new port", `{"port": 1081}`, []string{}, []string{"port"}},
+		{"new bind ip", `{"bind_ip": "127.0.0.2"}`, []string{}, []string{"bind_ip"}},
+		{"both", `{"bind_ip": "", "port": 2000}`, []string{}, []string{"bind_ip", "port"}},
+		{"live fields beside a restart", `{"port": 1081, "tcp_timeout_ms": 500, "guard": {"max_conns": 3}}`, []string{"tcp_timeout_ms", "guard"}, []string{"port"}},
+	}
+	for _, tt := range tests {
+		w := newTestServer(t, "127.0.0.1:1080")
+		st, err := parseServerConfig(tt.json, true)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		u := w.update(st)
+		if !reflect.DeepEqual(u.RestartRequired, tt.restart) {
+			t.Errorf("%s: restart_required %v, want %v", tt.name, u.RestartRequired, tt.restart)
+		}
+		if !reflect.DeepEqual(u.Applied, tt.applied) {
+			t.Errorf("%s: applied %v, want %v", tt.name, u.Applied, tt.applied)
+		}
+	}
+}
+
+func TestHandlerProtocolsAreOptIn(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		on   []string
+	}{
+		{"no handler", `{"port": 1080}`, []string{"socks5"}},
+		{"commands only", `{"port": 1080, "handler": {"commands": ["connect"]}}`, []string{"socks5"}},
+		{"http listed", `{"port": 1080, "handler": {"protocols": ["socks5", "http"]}}`, []string{"socks5", "http"}},
+		{"socks4 listed", `{"port": 1080, "handler": {"protocols": ["socks4", "socks5"]}}`, []string{"socks5", "socks4"}},
+	}
+	for _, tt := range tests {
+		st, err := parseServerConfig(tt.json, false)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var on []string
+		for _, p := range allProtocols {
+			if !st.off[p] {
+				on = append(on, p)
+			}
+		}
+		if !reflect.DeepEqual(on, tt.on) {
+			t.Errorf("%s: serves %v, want %v", tt.name, on, tt.on)
+		}
+	}
+	if w := newTestServer(t, "127.0.0.1:0"); w.serves("http") || w.serves("socks4") {
+		t.Error("a new server serves HTTP or SOCKS4")
+	}
+}
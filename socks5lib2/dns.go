@@ -0,0 +1,527 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	socks5 "github.com/txthinking/socks5"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSConfig is the JSON accepted by SetDNSConfig.
+//
+// Mode is "system" (the default), "udp", "tcp", "dot" or "doh". Server is
+// host:port for udp, tcp and dot (port 53 or 853 when left out) and an
+// https URL for doh. Hosts maps names to fixed addresses and is checked
+// first. Answers are cached for their TTL, or 60s in system mode, in up
+// to CacheSize entries; a negative CacheSize turns the cache off. With
+// Remote set, servers with an upstream proxy leave hostnames for it to
+// resolve.
+type DNSConfig struct {
+	Mode      string              `json:"mode,omitempty"`
+	Server    string              `json:"server,omitempty"`
+	Hosts     map[string][]string `json:"hosts,omitempty"`
+	CacheSize int                 `json:"cache_size,omitempty"`
+	TimeoutMs int                 `json:"timeout_ms,omitempty"`
+	Remote    bool                `json:"remote,omitempty"`
+}
+
+const (
+	defaultDNSCacheSize = 1024
+	defaultDNSTimeout   = 5 * time.Second
+	systemDNSTTL        = 60 * time.Second
+)
+
+// resolver is a compiled DNSConfig.
+type resolver struct {
+	cfg     DNSConfig
+	hosts   map[string][]net.IP
+	timeout time.Duration
+	cache   *dnsCache
+	// exchange sends one wire-format query out through e and returns the
+	// answer
+	exchange func(ctx context.Context, q []byte, e *egress) ([]byte, error)
+}
+
+func parseDNS(s string) (*resolver, error) {
+	var cfg DNSConfig
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid dns config: %v", err)
+	}
+	return newResolver(cfg)
+}
+
+func newResolver(cfg DNSConfig) (*resolver, error) {
+	r := &resolver{cfg: cfg, hosts: map[string][]net.IP{}, timeout: defaultDNSTimeout}
+	if cfg.TimeoutMs > 0 {
+		r.timeout = time.Duration(cfg.TimeoutMs) * time.Millisecond
+	}
+	for name, addrs := range cfg.Hosts {
+		for _, a := range addrs {
+			ip := net.ParseIP(a)
+			if ip == nil {
+				return nil, fmt.Errorf("hosts: %q is not an IP address", a)
+			}
+			r.hosts[canonicalName(name)] = append(r.hosts[canonicalName(name)], ip)
+		}
+	}
+	switch size := cfg.CacheSize; {
+	case size == 0:
+		r.cache = newDNSCache(defaultDNSCacheSize)
+	case size > 0:
+		r.cache = newDNSCache(size)
+	}
+	switch cfg.Mode {
+	case "", "system":
+	case "udp":
+		addr, err := withDefaultPort(cfg.Server, "53")
+		if err != nil {
+			return nil, err
+		}
+		r.exchange = func(ctx context.Context, q []byte, e *egress) ([]byte, error) { return exchangeUDP(ctx, addr, q, e) }
+	case "tcp":
+		addr, err := withDefaultPort(cfg.Server, "53")
+		if err != nil {
+			return nil, err
+		}
+		r.exchange = func(ctx context.Context, q []byte, e *egress) ([]byte, error) {
+			return exchangeTCP(ctx, addr, q, nil, e)
+		}
+	case "dot":
+		addr, err := withDefaultPort(cfg.Server, "853")
+		if err != nil {
+			return nil, err
+		}
+		host, _, _ := net.SplitHostPort(addr)
+		conf := &tls.Config{ServerName: host}
+		r.exchange = func(ctx context.Context, q []byte, e *egress) ([]byte, error) {
+			return exchangeTCP(ctx, addr, q, conf, e)
+		}
+	case "doh":
+		u, err := url.Parse(cfg.Server)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("doh server must be an https URL, got %q", cfg.Server)
+		}
+		clients := newDoHClients(r.timeout)
+		r.exchange = func(ctx context.Context, q []byte, e *egress) ([]byte, error) {
+			return exchangeDoH(ctx, clients.get(e), u.String(), q)
+		}
+	default:
+		return nil, fmt.Errorf("unknown dns mode %q", cfg.Mode)
+	}
+	return r, nil
+}
+
+func withDefaultPort(server, port string) (string, error) {
+	if server == "" {
+		return "", errors.New("dns server is required")
+	}
+	return withPort(server, port), nil
+}
+
+func canonicalName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// LookupIP resolves host through hosts, the cache and the configured
+// server, in that order. Queries go out the way e says.
+func (r *resolver) LookupIP(ctx context.Context, host string, e *egress) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	name := canonicalName(host)
+	if ips, ok := r.hosts[name]; ok {
+		return ips, nil
+	}
+	if ips, ok := r.cache.get(name); ok {
+		return ips, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	var ips []net.IP
+	var ttl time.Duration
+	var err error
+	if r.exchange == nil {
+		ips, err = e.resolver().LookupIP(ctx, "ip", name)
+		ttl = systemDNSTTL
+	} else {
+		ips, ttl, err = r.query(ctx, name, e)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+	r.cache.put(name, ips, ttl)
+	return ips, nil
+}
+
+// query asks for A and AAAA records at once. IPv4 answers come first and
+// the TTL is the smallest seen.
+func (r *resolver) query(ctx context.Context, name string, e *egress) ([]net.IP, time.Duration, error) {
+	type result struct {
+		ips []net.IP
+		ttl uint32
+		err error
+	}
+	types := []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA}
+	results := make([]result, len(types))
+	var wg sync.WaitGroup
+	for i, t := range types {
+		wg.Add(1)
+		go func(i int, t dnsmessage.Type) {
+			defer wg.Done()
+			ips, ttl, err := r.queryType(ctx, name, t, e)
+			results[i] = result{ips, ttl, err}
+		}(i, t)
+	}
+	wg.Wait()
+	var ips []net.IP
+	var errs []error
+	ttl := uint32(0)
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		if len(res.ips) > 0 && (ttl == 0 || res.ttl < ttl) {
+			ttl = res.ttl
+		}
+		ips = append(ips, res.ips...)
+	}
+	// both queries usually fail the same way, so one error is enough
+	if len(ips) == 0 && len(errs) > 0 {
+		return nil, 0, errs[0]
+	}
+	return ips, time.Duration(ttl) * time.Second, nil
+}
+
+func (r *resolver) queryType(ctx context.Context, name string, t dnsmessage.Type, e *egress) ([]net.IP, uint32, error) {
+	qn, err := dnsmessage.NewName(name + ".")
+	if err != nil {
+		return nil, 0, err
+	}
+	id := uint16(rand.Uint32())
+	q := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: qn, Type: t, Class: dnsmessage.ClassINET}},
+	}
+	b, err := q.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+	ans, err := r.exchange(ctx, b, e)
+	if err != nil {
+		return nil, 0, err
+	}
+	var m dnsmessage.Message
+	if err := m.Unpack(ans); err != nil {
+		return nil, 0, err
+	}
+	// DoH queries may use ID 0, anything else must echo ours
+	if m.Header.ID != id && m.Header.ID != 0 {
+		return nil, 0, errors.New("dns: answer id mismatch")
+	}
+	switch m.Header.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, 0, fmt.Errorf("no such host %s", name)
+	default:
+		return nil, 0, fmt.Errorf("dns: %s for %s", m.Header.RCode, name)
+	}
+	var ips []net.IP
+	var ttl uint32
+	for _, a := range m.Answers {
+		var ip net.IP
+		switch body := a.Body.(type) {
+		case *dnsmessage.AResource:
+			ip = net.IP(body.A[:])
+		case *dnsmessage.AAAAResource:
+			ip = net.IP(body.AAAA[:])
+		default:
+			continue
+		}
+		if len(ips) == 0 || a.Header.TTL < ttl {
+			ttl = a.Header.TTL
+		}
+		ips = append(ips, ip)
+	}
+	return ips, ttl, nil
+}
+
+func exchangeUDP(ctx context.Context, addr string, q []byte, e *egress) ([]byte, error) {
+	c, err := e.dialer("udp", 0).DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		c.SetDeadline(dl)
+	}
+	if _, err := c.Write(q); err != nil {
+		return nil, err
+	}
+	b := make([]byte, 65535)
+	n, err := c.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	var h dnsmessage.Parser
+	if hdr, err := h.Start(b[:n]); err == nil && hdr.Truncated {
+		return exchangeTCP(ctx, addr, q, nil, e)
+	}
+	return b[:n], nil
+}
+
+// exchangeTCP sends q with the two-byte length prefix of DNS over TCP,
+// over TLS when conf is set.
+func exchangeTCP(ctx context.Context, addr string, q []byte, conf *tls.Config, e *egress) ([]byte, error) {
+	var c net.Conn
+	var err error
+	if conf != nil {
+		d := tls.Dialer{NetDialer: e.dialer("tcp", 0), Config: conf}
+		c, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		c, err = e.dialer("tcp", 0).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		c.SetDeadline(dl)
+	}
+	msg := make([]byte, 2+len(q))
+	binary.BigEndian.PutUint16(msg, uint16(len(q)))
+	copy(msg[2:], q)
+	if _, err := c.Write(msg); err != nil {
+		return nil, err
+	}
+	var l [2]byte
+	if _, err := io.ReadFull(c, l[:]); err != nil {
+		return nil, err
+	}
+	b := make([]byte, binary.BigEndian.Uint16(l[:]))
+	if _, err := io.ReadFull(c, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// exchangeDoH posts q as application/dns-message (RFC 8484).
+func exchangeDoH(ctx context.Context, client *http.Client, u string, q []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(q))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("doh: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 65535))
+}
+
+// dohClients keeps an HTTP client per egress so that each reuses its
+// connections to the DoH server.
+type dohClients struct {
+	timeout time.Duration
+	mu      sync.Mutex
+	byCfg   map[Egress]*http.Client
+}
+
+func newDoHClients(timeout time.Duration) *dohClients {
+	return &dohClients{timeout: timeout, byCfg: make(map[Egress]*http.Client)}
+}
+
+func (d *dohClients) get(e *egress) *http.Client {
+	var key Egress
+	if e != nil {
+		key = e.cfg
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, ok := d.byCfg[key]
+	if !ok {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.DialContext = e.dialer("tcp", dialTimeout).DialContext
+		c = &http.Client{Timeout: d.timeout, Transport: t}
+		d.byCfg[key] = c
+	}
+	return c
+}
+
+// dnsCache keeps answers until their TTL runs out. A nil cache stores
+// nothing.
+type dnsCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+func newDNSCache(size int) *dnsCache {
+	return &dnsCache{size: size, entries: make(map[string]dnsEntry)}
+}
+
+func (c *dnsCache) get(name string) ([]net.IP, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[name]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, name)
+		return nil, false
+	}
+	return e.ips, true
+}
+
+func (c *dnsCache) put(name string, ips []net.IP, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[name]; !ok && len(c.entries) >= c.size {
+		// drop expired entries, or any one if none has expired
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[name] = dnsEntry{ips: ips, expires: time.Now().Add(ttl)}
+}
+
+// dnsHolder lets a server's resolver be swapped while it runs. A nil
+// resolver keeps the default: the system resolver for direct servers and
+// the upstream proxy for the others.
+type dnsHolder struct {
+	mu sync.RWMutex
+	r  *resolver
+}
+
+func (h *dnsHolder) get() *resolver {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.r
+}
+
+func (h *dnsHolder) set(r *resolver) {
+	h.mu.Lock()
+	h.r = r
+	h.mu.Unlock()
+}
+
+// resolveLocally reports whether hostnames are resolved here rather than
+// handed to up.
+func (w *Socks5ServerWrapper) resolveLocally(up upstream) (*resolver, bool) {
+	r := w.dns.get()
+	if r == nil {
+		return nil, false
+	}
+	_, direct := up.(directUpstream)
+	return r, direct || !r.cfg.Remote
+}
+
+// systemLookup resolves host with the system resolver through e, giving
+// up after defaultDNSTimeout as the server's own resolver would.
+func systemLookup(ctx context.Context, host string, e *egress) ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultDNSTimeout)
+	defer cancel()
+	return e.resolver().LookupIP(ctx, "ip", host)
+}
+
+// lookupIP resolves host with the server's resolver, or the system one,
+// sending queries out through the server's egress.
+func (w *Socks5ServerWrapper) lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	e := w.serverEgress()
+	if r := w.dns.get(); r != nil {
+		return r.LookupIP(ctx, host, e)
+	}
+	return systemLookup(ctx, host, e)
+}
+
+// dial connects to target through the upstream for s. Hostnames are
+// resolved first when the server's resolver says so, trying a few
+// addresses.
+func (w *Socks5ServerWrapper) dial(s *session, target string) (net.Conn, error) {
+	up, e := w.currentUpstream(), w.egressFor(s)
+	r, local := w.resolveLocally(up)
+	host, port, err := net.SplitHostPort(target)
+	if !local || err != nil || net.ParseIP(host) != nil {
+		return up.dial(target, e)
+	}
+	ips, err := r.LookupIP(s.ctx, host, e)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) > 4 {
+		ips = ips[:4]
+	}
+	for _, ip := range ips {
+		var c net.Conn
+		if c, err = up.dial(net.JoinHostPort(ip.String(), port), e); err == nil {
+			return c, nil
+		}
+	}
+	return nil, err
+}
+
+// resolveDatagram rewrites a hostname destination to an address when the
+// server resolves locally, querying through e until ctx ends.
+func (w *Socks5ServerWrapper) resolveDatagram(ctx context.Context, d *socks5.Datagram, e *egress) error {
+	r, local := w.resolveLocally(w.currentUpstream())
+	if !local || d.Atyp != socks5.ATYPDomain {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(d.Address())
+	if err != nil {
+		return err
+	}
+	ips, err := r.LookupIP(ctx, host, e)
+	if err != nil {
+		return err
+	}
+	a, h, p, err := socks5.ParseAddress(net.JoinHostPort(ips[0].String(), port))
+	if err != nil {
+		return err
+	}
+	d.Atyp, d.DstAddr, d.DstPort = a, h, p
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestResolverQueriesLeaveThroughEgress(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	from := make(chan net.Addr, 1)
+	go func() {
+		b := make([]byte, 512)
+		if _, a, err := pc.ReadFrom(b); err == nil {
+			from <- a
+		}
+	}()
+	r, err := newResolver(DNSConfig{Mode: "udp", Server: pc.LocalAddr().String(), TimeoutMs: 200, CacheSize: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := newEgress(Egress{IP: "127.0.0.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.LookupIP(context.Background(), "example.test", e)
+	select {
+	case a := <-from:
+		if ip := a.(*net.UDPAddr).IP; !ip.Equal(net.ParseIP("127.0.0.2")) {
+			t.Fatalf("query came from %v, want 127.0.0.2", ip)
+		}
+	default:
+		t.Fatal("no query reached the server")
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Egress picks how outbound traffic leaves a multi-homed host. IP is the
+// local source address. Interface binds sockets to a device with
+// SO_BINDTODEVICE and Mark sets SO_MARK for policy routing; both are
+// Linux only and need CAP_NET_RAW and CAP_NET_ADMIN respectively.
+//
+// A server's egress applies to its direct connections, to its dials to
+// upstream proxies and to the DNS queries it makes; an ACL rule's egress
+// replaces it for the requests the rule allows.
+type Egress struct {
+	IP        string `json:"ip,omitempty"`
+	Interface string `json:"interface,omitempty"`
+	Mark      int    `json:"mark,omitempty"`
+}
+
+// egress is a checked Egress. A nil *egress uses the system defaults.
+type egress struct {
+	cfg Egress
+	ip  net.IP
+}
+
+const dialTimeout = 30 * time.Second
+
+func parseEgress(s string) (*egress, error) {
+	var cfg Egress
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid egress: %v", err)
+	}
+	return newEgress(cfg)
+}
+
+func newEgress(cfg Egress) (*egress, error) {
+	e := &egress{cfg: cfg}
+	if cfg.IP != "" {
+		if e.ip = net.ParseIP(cfg.IP); e.ip == nil {
+			return nil, fmt.Errorf("ip: %q is not an IP address", cfg.IP)
+		}
+	}
+	if cfg.Interface != "" {
+		if _, err := net.InterfaceByName(cfg.Interface); err != nil {
+			return nil, fmt.Errorf("interface: no interface named %q", cfg.Interface)
+		}
+	}
+	if cfg.Mark < 0 {
+		return nil, errors.New("mark: must not be negative")
+	}
+	if (cfg.Interface != "" || cfg.Mark != 0) && !socketOptsSupported {
+		return nil, errors.New("interface and mark are only supported on Linux")
+	}
+	return e, nil
+}
+
+// control applies the socket options before a socket connects or binds.
+func (e *egress) control(network, address string, c syscall.RawConn) error {
+	if e == nil || (e.cfg.Interface == "" && e.cfg.Mark == 0) {
+		return nil
+	}
+	var serr error
+	err := c.Control(func(fd uintptr) {
+		serr = setSocketOpts(fd, e.cfg.Interface, e.cfg.Mark)
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
+
+// localAddr is the source address for network, nil when none is set.
+func (e *egress) localAddr(network string) net.Addr {
+	if e == nil || e.ip == nil {
+		return nil
+	}
+	if strings.HasPrefix(network, "udp") {
+		return &net.UDPAddr{IP: e.ip}
+	}
+	return &net.TCPAddr{IP: e.ip}
+}
+
+// dialer dials out the way e says. Hostnames are looked up with
+// e.resolver, so the queries leave the same way.
+func (e *egress) dialer(network string, timeout time.Duration) *net.Dialer {
+	return &net.Dialer{Timeout: timeout, LocalAddr: e.localAddr(network), Control: e.control, Resolver: e.resolver()}
+}
+
+// resolver is the system resolver with its queries sent out the way e
+// says. That takes the Go resolver, as the cgo one cannot be told.
+func (e *egress) resolver() *net.Resolver {
+	if e == nil {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		d := net.Dialer{LocalAddr: e.localAddr(network), Control: e.control}
+		return d.DialContext(ctx, network, addr)
+	}}
+}
+
+func (e *egress) dial(network, addr string) (net.Conn, error) {
+	return e.dialer(network, dialTimeout).Dial(network, addr)
+}
+
+// listenTCP listens on a free port of the source address.
+func (e *egress) listenTCP() (*net.TCPListener, error) {
+	lc := net.ListenConfig{Control: e.control}
+	ln, err := lc.Listen(context.Background(), "tcp", e.listenAddr())
+	if err != nil {
+		return nil, err
+	}
+	return ln.(*net.TCPListener), nil
+}
+
+// listenUDP opens a socket for sending datagrams out.
+func (e *egress) listenUDP() (*net.UDPConn, error) {
+	lc := net.ListenConfig{Control: e.control}
+	pc, err := lc.ListenPacket(context.Background(), "udp", e.listenAddr())
+	if err != nil {
+		return nil, err
+	}
+	return pc.(*net.UDPConn), nil
+}
+
+func (e *egress) listenAddr() string {
+	if e == nil || e.ip == nil {
+		return ":0"
+	}
+	return net.JoinHostPort(e.ip.String(), "0")
+}
+
+// egressFor returns the egress of s: its ACL rule's, else the server's.
+func (w *Socks5ServerWrapper) egressFor(s *session) *egress {
+	if s != nil && s.egress != nil {
+		return s.egress
+	}
+	return w.serverEgress()
+}
+
+func (w *Socks5ServerWrapper) serverEgress() *egress {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.egress
+}
@@ -0,0 +1,24 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+const socketOptsSupported = true
+
+func setSocketOpts(fd uintptr, iface string, mark int) error {
+	if iface != "" {
+		if err := syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, iface); err != nil {
+			return fmt.Errorf("SO_BINDTODEVICE %s: %w", iface, err)
+		}
+	}
+	if mark != 0 {
+		if err := syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, mark); err != nil {
+			return fmt.Errorf("SO_MARK %d: %w", mark, err)
+		}
+	}
+	return nil
+}
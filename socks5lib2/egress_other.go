@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+// SO_BINDTODEVICE and SO_MARK are Linux only; newEgress refuses them
+// elsewhere, so this is never reached with either set.
+const socketOptsSupported = false
+
+func setSocketOpts(fd uintptr, iface string, mark int) error { return nil }
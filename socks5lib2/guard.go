@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	core "core"
+	"golang.org/x/time/rate"
+)
+
+// GuardConfig is the JSON accepted by SetGuard. Zero fields are off.
+//
+// After MaxFailures failed logins from one IP within FailureWindowMs
+// (default 10 minutes) the IP is banned for BanMs (default 1 minute).
+// Every further ban of that IP lasts twice as long as the one before, up
+// to MaxBanMs (default 24 hours); the count starts over once the IP has
+// gone MaxBanMs without a ban. MaxConns, MaxConnsPerIP and MaxConnsPerUser
+// cap concurrent connections. AcceptRate caps new connections per second,
+// with bursts of AcceptBurst (at least 1).
+type GuardConfig struct {
+	MaxFailures     int     `json:"max_failures,omitempty"`
+	FailureWindowMs int     `json:"failure_window_ms,omitempty"`
+	BanMs           int     `json:"ban_ms,omitempty"`
+	MaxBanMs        int     `json:"max_ban_ms,omitempty"`
+	MaxConns        int     `json:"max_conns,omitempty"`
+	MaxConnsPerIP   int     `json:"max_conns_per_ip,omitempty"`
+	MaxConnsPerUser int     `json:"max_conns_per_user,omitempty"`
+	AcceptRate      float64 `json:"accept_rate,omitempty"`
+	AcceptBurst     int     `json:"accept_burst,omitempty"`
+}
+
+// Ban is one banned source IP. Offense is 1 for its first ban.
+type Ban struct {
+	IP      string    `json:"ip"`
+	Until   time.Time `json:"until"`
+	Offense int       `json:"offense"`
+}
+
+const (
+	defaultFailureWindow = 10 * time.Minute
+	defaultBan           = time.Minute
+	defaultMaxBan        = 24 * time.Hour
+	// records are swept once there are this many, and then again once
+	// there are twice as many as the sweep kept
+	guardSweepAt = 4096
+)
+
+var (
+	errBanned     = errors.New("source address is banned")
+	errAcceptRate = errors.New("accept rate exceeded")
+	errServerFull = errors.New("too many connections")
+	errIPFull     = errors.New("too many connections from this address")
+	errUserFull   = errors.New("too many connections for this user")
+)
+
+func parseGuard(s string) (GuardConfig, error) {
+	var cfg GuardConfig
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid guard: %v", err)
+	}
+	if cfg.MaxFailures < 0 || cfg.FailureWindowMs < 0 || cfg.BanMs < 0 || cfg.MaxBanMs < 0 ||
+		cfg.MaxConns < 0 || cfg.MaxConnsPerIP < 0 || cfg.MaxConnsPerUser < 0 ||
+		cfg.AcceptRate < 0 || cfg.AcceptBurst < 0 {
+		return cfg, errors.New("guard values must not be negative")
+	}
+	return cfg, nil
+}
+
+func msOr(ms int, def time.Duration) time.Duration {
+	if ms <= 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// ipRecord is what the guard knows about one source IP.
+type ipRecord struct {
+	conns    int
+	failures int
+	since    time.Time // start of the failure window
+	until    time.Time // banned until
+	offense  int
+	lastBan  time.Time
+}
+
+// guard enforces a server's GuardConfig. Its state outlives config
+// changes. Source IPs are only recorded while a limit needs them: per-IP
+// connection counts with MaxConnsPerIP, failures and bans with
+// MaxFailures.
+type guard struct {
+	mu      sync.Mutex
+	cfg     GuardConfig
+	accept  *rate.Limiter // nil when unlimited
+	ips     map[string]*ipRecord
+	sweepAt int
+	users   map[string]int
+	conns   int
+}
+
+func newGuard() *guard {
+	return &guard{ips: make(map[string]*ipRecord), sweepAt: guardSweepAt, users: make(map[string]int)}
+}
+
+func (g *guard) config() GuardConfig {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.cfg
+}
+
+func (g *guard) set(cfg GuardConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg = cfg
+	g.accept = nil
+	if cfg.AcceptRate > 0 {
+		burst := cfg.AcceptBurst
+		if burst < 1 {
+			burst = int(math.Ceil(cfg.AcceptRate))
+		}
+		g.accept = rate.NewLimiter(rate.Limit(cfg.AcceptRate), burst)
+	}
+}
+
+func (g *guard) record(ip string, now time.Time) *ipRecord {
+	r, ok := g.ips[ip]
+	if !ok {
+		if len(g.ips) >= g.sweepAt {
+			g.sweep(now)
+			g.sweepAt = 2 * len(g.ips)
+			if g.sweepAt < guardSweepAt {
+				g.sweepAt = guardSweepAt
+			}
+		}
+		r = &ipRecord{}
+		g.ips[ip] = r
+	}
+	return r
+}
+
+// admit counts a new connection from ip, or says why it is refused. The
+// returned func undoes the count once the connection ends.
+func (g *guard) admit(ip string) (func(), error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	if r, ok := g.ips[ip]; ok && now.Before(r.until) {
+		return nil, errBanned
+	}
+	if g.accept != nil && !g.accept.AllowN(now, 1) {
+		return nil, errAcceptRate
+	}
+	if g.cfg.MaxConns > 0 && g.conns >= g.cfg.MaxConns {
+		return nil, errServerFull
+	}
+	var r *ipRecord
+	if g.cfg.MaxConnsPerIP > 0 {
+		r = g.record(ip, now)
+		if r.conns >= g.cfg.MaxConnsPerIP {
+			return nil, errIPFull
+		}
+		r.conns++
+	}
+	g.conns++
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.conns--
+		if r != nil {
+			r.conns--
+		}
+	}, nil
+}
+
+// holdUser counts a connection against user's cap. The anonymous user
+// has none.
+func (g *guard) holdUser(user string) bool {
+	if user == "" {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cfg.MaxConnsPerUser > 0 && g.users[user] >= g.cfg.MaxConnsPerUser {
+		return false
+	}
+	g.users[user]++
+	return true
+}
+
+func (g *guard) releaseUser(user string) {
+	if user == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.users[user] <= 1 {
+		delete(g.users, user)
+	} else {
+		g.users[user]--
+	}
+}
+
+// failed records a failed login from ip and returns the ban it caused,
+// if any.
+func (g *guard) failed(ip string) *Ban {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cfg.MaxFailures <= 0 {
+		return nil
+	}
+	now := time.Now()
+	r := g.record(ip, now)
+	if now.Before(r.until) {
+		return nil
+	}
+	if now.Sub(r.since) > msOr(g.cfg.FailureWindowMs, defaultFailureWindow) {
+		r.failures, r.since = 0, now
+	}
+	r.failures++
+	if r.failures < g.cfg.MaxFailures {
+		return nil
+	}
+	maxBan := msOr(g.cfg.MaxBanMs, defaultMaxBan)
+	if !r.lastBan.IsZero() && now.Sub(r.lastBan) > maxBan {
+		r.offense = 0
+	}
+	r.offense++
+	d := msOr(g.cfg.BanMs, defaultBan)
+	for i := 1; i < r.offense && d < maxBan; i++ {
+		d *= 2
+	}
+	if d > maxBan {
+		d = maxBan
+	}
+	r.failures = 0
+	r.until, r.lastBan = now.Add(d), now
+	return &Ban{IP: ip, Until: r.until, Offense: r.offense}
+}
+
+// succeeded forgives the failures of ip, though not its earlier bans.
+func (g *guard) succeeded(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if r, ok := g.ips[ip]; ok {
+		r.failures = 0
+	}
+}
+
+// sweep drops records that hold nothing worth remembering.
+func (g *guard) sweep(now time.Time) {
+	window := msOr(g.cfg.FailureWindowMs, defaultFailureWindow)
+	maxBan := msOr(g.cfg.MaxBanMs, defaultMaxBan)
+	for ip, r := range g.ips {
+		if r.conns == 0 && now.After(r.until) && now.Sub(r.since) > window &&
+			(r.lastBan.IsZero() || now.Sub(r.lastBan) > maxBan) {
+			delete(g.ips, ip)
+		}
+	}
+}
+
+// bans lists the bans in force, by IP.
+func (g *guard) bans() []Ban {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	out := []Ban{}
+	for ip, r := range g.ips {
+		if now.Before(r.until) {
+			out = append(out, Ban{IP: ip, Until: r.until, Offense: r.offense})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].IP < out[j].IP })
+	return out
+}
+
+// clear lifts the ban on ip, or on every IP when ip is empty, and forgets
+// their history. It returns the IPs whose ban was lifted.
+func (g *guard) clear(ip string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	lifted := []string{}
+	for k, r := range g.ips {
+		if ip != "" && k != ip {
+			continue
+		}
+		if now.Before(r.until) {
+			lifted = append(lifted, k)
+		}
+		r.failures, r.offense = 0, 0
+		r.until, r.lastBan = time.Time{}, time.Time{}
+	}
+	sort.Strings(lifted)
+	return lifted
+}
+
+func hostOf(a net.Addr) string {
+	if ta, ok := a.(*net.TCPAddr); ok {
+		return ta.IP.String()
+	}
+	host, _, err := net.SplitHostPort(a.String())
+	if err != nil {
+		return a.String()
+	}
+	return host
+}
+
+// noteAuth feeds a login attempt to the guard, reporting any ban.
+func (w *Socks5ServerWrapper) noteAuth(c net.Conn, ok bool) {
+	ip := hostOf(c.RemoteAddr())
+	if ok {
+		w.guard.succeeded(ip)
+		return
+	}
+	if b := w.guard.failed(ip); b != nil {
+		w.emit(core.TopicSocks5Connections, core.Resp{Op: "ip_banned", Success: true, Data: map[string]interface{}{
+			"server": w.ID,
+			"ban":    b,
+		}})
+	}
+}
+
+// ClearBans lifts bans as guard.clear does and reports the lifted ones.
+func (w *Socks5ServerWrapper) ClearBans(ip string) []string {
+	lifted := w.guard.clear(ip)
+	if len(lifted) > 0 {
+		w.emit(core.TopicSocks5Connections, core.Resp{Op: "ip_unbanned", Success: true, Data: map[string]interface{}{
+			"server": w.ID,
+			"ips":    lifted,
+		}})
+	}
+	return lifted
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func newTestGuard(cfg GuardConfig) *guard {
+	g := newGuard()
+	g.set(cfg)
+	return g
+}
+
+func TestGuardBansAfterFailures(t *testing.T) {
+	g := newTestGuard(GuardConfig{MaxFailures: 3, BanMs: 1000, MaxBanMs: 3000})
+	for i := 0; i < 2; i++ {
+		if b := g.failed("192.0.2.1"); b != nil {
+			t.Fatalf("banned after %d failures", i+1)
+		}
+	}
+	b := g.failed("192.0.2.1")
+	if b == nil || b.Offense != 1 {
+		t.Fatalf("third failure: got ban %+v, want the first", b)
+	}
+	if d := time.Until(b.Until); d <= 0 || d > time.Second {
+		t.Fatalf("first ban lasts %v, want ban_ms", d)
+	}
+	if _, err := g.admit("192.0.2.1"); err != errBanned {
+		t.Fatalf("banned IP admitted: %v", err)
+	}
+	if _, err := g.admit("192.0.2.2"); err != nil {
+		t.Fatalf("other IP refused: %v", err)
+	}
+	if got := g.bans(); len(got) != 1 || got[0].IP != "192.0.2.1" {
+		t.Fatalf("bans %+v", got)
+	}
+
+	// each further ban doubles, up to max_ban_ms
+	for _, want := range []time.Duration{2 * time.Second, 3 * time.Second} {
+		g.ips["192.0.2.1"].until = time.Now().Add(-time.Millisecond)
+		var b *Ban
+		for i := 0; i < 3; i++ {
+			b = g.failed("192.0.2.1")
+		}
+		if b == nil {
+			t.Fatal("no ban after the window was over")
+		}
+		if d := time.Until(b.Until); d <= want-time.Second || d > want {
+			t.Fatalf("offense %d lasts %v, want %v", b.Offense, d, want)
+		}
+	}
+}
+
+func TestGuardFailureWindow(t *testing.T) {
+	g := newTestGuard(GuardConfig{MaxFailures: 2, FailureWindowMs: 60000})
+	g.failed("192.0.2.1")
+	g.ips["192.0.2.1"].since = time.Now().Add(-2 * time.Minute)
+	if b := g.failed("192.0.2.1"); b != nil {
+		t.Fatal("a failure outside the window counted towards a ban")
+	}
+	g.succeeded("192.0.2.1")
+	if b := g.failed("192.0.2.1"); b != nil {
+		t.Fatal("a failure forgiven by a login counted towards a ban")
+	}
+	if b := g.failed("192.0.2.1"); b == nil {
+		t.Fatal("no ban after two failures within the window")
+	}
+	if lifted := g.clear(""); len(lifted) != 1 || lifted[0] != "192.0.2.1" {
+		t.Fatalf("lifted %v", lifted)
+	}
+	if _, err := g.admit("192.0.2.1"); err != nil {
+		t.Fatalf("cleared IP refused: %v", err)
+	}
+}
+
+func TestGuardConnectionLimits(t *testing.T) {
+	g := newTestGuard(GuardConfig{MaxConns: 3, MaxConnsPerIP: 2})
+	r1, err := g.admit("192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.admit("192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.admit("192.0.2.1"); err != errIPFull {
+		t.Fatalf("third connection from one IP: %v, want %v", err, errIPFull)
+	}
+	if _, err := g.admit("192.0.2.2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.admit("192.0.2.3"); err != errServerFull {
+		t.Fatalf("fourth connection: %v, want %v", err, errServerFull)
+	}
+	r1()
+	if _, err := g.admit("192.0.2.1"); err != nil {
+		t.Fatalf("closing a connection did not free its slots: %v", err)
+	}
+}
+
+func TestGuardUserLimit(t *testing.T) {
+	g := newTestGuard(GuardConfig{MaxConnsPerUser: 1})
+	if !g.holdUser("alice") || g.holdUser("alice") {
+		t.Fatal("per-user cap of 1 not enforced")
+	}
+	if !g.holdUser("bob") {
+		t.Fatal("one user's connection counted against another")
+	}
+	if !g.holdUser("") || !g.holdUser("") {
+		t.Fatal("anonymous connections capped")
+	}
+	g.releaseUser("alice")
+	if !g.holdUser("alice") {
+		t.Fatal("released user slot not reusable")
+	}
+	g.releaseUser("alice")
+	g.releaseUser("bob")
+	if len(g.users) != 0 {
+		t.Fatalf("%d users counted after every release", len(g.users))
+	}
+}
+
+func TestGuardAcceptRate(t *testing.T) {
+	g := newTestGuard(GuardConfig{AcceptRate: 0.001, AcceptBurst: 2})
+	for i := 0; i < 2; i++ {
+		if _, err := g.admit("192.0.2.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := g.admit("192.0.2.2"); err != errAcceptRate {
+		t.Fatalf("connection past the burst: %v, want %v", err, errAcceptRate)
+	}
+}
+
+func TestGuardTracksIPsOnlyForLimits(t *testing.T) {
+	g := newGuard()
+	for i := 0; i < 100; i++ {
+		release, err := g.admit(fmt.Sprintf("10.0.0.%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer release()
+		g.failed(fmt.Sprintf("10.0.1.%d", i))
+	}
+	if len(g.ips) != 0 {
+		t.Fatalf("%d IPs recorded with no limits set", len(g.ips))
+	}
+	if g.conns != 100 {
+		t.Fatalf("counted %d connections, want 100", g.conns)
+	}
+}
+
+func TestGuardSweepsPastCap(t *testing.T) {
+	g := newTestGuard(GuardConfig{MaxConnsPerIP: 1})
+	var open []func()
+	for i := 0; i < guardSweepAt+10; i++ {
+		release, err := g.admit(fmt.Sprintf("10.%d.%d.1", i>>8, i&0xff))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 10 {
+			open = append(open, release)
+		} else {
+			release()
+		}
+	}
+	if len(g.ips) > 20 {
+		t.Fatalf("%d records kept, want the open ones and those since the sweep", len(g.ips))
+	}
+	if g.sweepAt != guardSweepAt {
+		t.Fatalf("next sweep at %d", g.sweepAt)
+	}
+	for _, release := range open {
+		release()
+	}
+	if g.conns != 0 {
+		t.Fatalf("%d connections counted after every release", g.conns)
+	}
+
+	// records that must stay push the next sweep out
+	g = newTestGuard(GuardConfig{MaxConnsPerIP: 1})
+	for i := 0; i <= guardSweepAt; i++ {
+		if _, err := g.admit(fmt.Sprintf("10.%d.%d.1", i>>8, i&0xff)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.sweepAt != 2*guardSweepAt {
+		t.Fatalf("next sweep at %d, want %d", g.sweepAt, 2*guardSweepAt)
+	}
+}
+
+// greet opens a SOCKS5 connection to addr and reports whether the server
+// answered the greeting.
+func greet(t *testing.T, addr string) (net.Conn, bool) {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetDeadline(time.Now().Add(time.Second))
+	if _, err := c.Write([]byte{5, 1, 0}); err != nil {
+		return c, false
+	}
+	_, err = io.ReadFull(c, make([]byte, 2))
+	return c, err == nil
+}
+
+func TestGuardReleasesOnClose(t *testing.T) {
+	w := newTestServer(t, "127.0.0.1:0")
+	w.guard.set(GuardConfig{MaxConnsPerIP: 1})
+	if err := w.listen(); err != nil {
+		t.Fatal(err)
+	}
+	go w.serve()
+	addr := w.ln.Addr().String()
+
+	c, ok := greet(t, addr)
+	if !ok {
+		t.Fatal("first connection refused")
+	}
+	c2, ok := greet(t, addr)
+	c2.Close()
+	if ok {
+		t.Fatal("second connection from one IP answered")
+	}
+	c.Close()
+	for i := 0; ; i++ {
+		c, ok := greet(t, addr)
+		c.Close()
+		if ok {
+			break
+		}
+		if i == 50 {
+			t.Fatal("slot not released after the connection closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
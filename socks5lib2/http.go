@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	socks5 "github.com/txthinking/socks5"
+)
+
+// hopHeaders only concern one HTTP hop and are not forwarded.
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// serveHTTP is the HTTP proxy side of the listener. CONNECT opens a
+// tunnel; absolute-URI requests are forwarded one per upstream
+// connection, keeping the client connection alive between them. The
+// connection holds a slot of the user of its latest request.
+func (w *Socks5ServerWrapper) serveHTTP(c *bufferedConn) {
+	var held string
+	defer func() { w.guard.releaseUser(held) }()
+	for {
+		c.SetDeadline(time.Now().Add(handshakeTimeout))
+		req, err := http.ReadRequest(c.r)
+		if err != nil {
+			return
+		}
+		user, ok := w.httpAuth(c, req)
+		if !ok {
+			return
+		}
+		if user != held {
+			if !w.guard.holdUser(user) {
+				httpError(c, http.StatusTooManyRequests)
+				return
+			}
+			w.guard.releaseUser(held)
+			held = user
+		}
+		if req.Method == http.MethodConnect {
+			w.httpConnect(c, req, user)
+			return
+		}
+		if !req.URL.IsAbs() || req.URL.Scheme != "http" {
+			httpError(c, http.StatusBadRequest)
+			return
+		}
+		if !w.httpForward(c, req, user) {
+			return
+		}
+	}
+}
+
+// httpAuth checks Basic proxy credentials against the server's users.
+// A request without credentials is challenged and not reported as a
+// failed attempt.
+func (w *Socks5ServerWrapper) httpAuth(c net.Conn, req *http.Request) (string, bool) {
+	if w.users.empty() {
+		return "", true
+	}
+	user, pass, ok := parseProxyAuth(req.Header.Get("Proxy-Authorization"))
+	if ok && w.users.verify(user, pass) {
+		w.authEvent(c, user, true)
+		return user, true
+	}
+	if ok {
+		w.authEvent(c, user, false)
+	}
+	httpError(c, http.StatusProxyAuthRequired, "Proxy-Authenticate", `Basic realm="proxy"`)
+	return "", false
+}
+
+func (w *Socks5ServerWrapper) httpConnect(c net.Conn, req *http.Request, user string) {
+	target := withPort(req.Host, "443")
+	s := w.track(c, "http", user, target, socks5.CmdConnect)
+	if err := w.admitHTTP(c, s, target); err != nil {
+		w.untrack(s, err)
+		return
+	}
+	rc, err := w.dial(s, target)
+	if err != nil {
+		httpError(c, http.StatusBadGateway)
+		w.untrack(s, err)
+		return
+	}
+	s.attach(rc)
+	if _, err = io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n"); err == nil {
+		relay(s, c, rc)
+	}
+	rc.Close()
+	w.untrack(s, err)
+}
+
+// httpForward sends one request upstream and its response back. It
+// reports whether the client connection can take another request.
+func (w *Socks5ServerWrapper) httpForward(c net.Conn, req *http.Request, user string) bool {
+	target := withPort(req.URL.Host, "80")
+	s := w.track(c, "http", user, target, socks5.CmdConnect)
+	if err := w.admitHTTP(c, s, target); err != nil {
+		w.untrack(s, err)
+		return false
+	}
+	rc, err := w.dial(s, target)
+	if err != nil {
+		httpError(c, http.StatusBadGateway)
+		w.untrack(s, err)
+		return false
+	}
+	s.attach(rc)
+	defer rc.Close()
+
+	clientClose := req.Close
+	removeHopHeaders(req.Header)
+	req.Close = true
+	if err := req.Write(countingWriter{shapedWriter{s.ctx, rc, s.limiters(true)}, s.addOut}); err != nil {
+		httpError(c, http.StatusBadGateway)
+		w.untrack(s, err)
+		return false
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(rc), req)
+	if err != nil {
+		httpError(c, http.StatusBadGateway)
+		w.untrack(s, err)
+		return false
+	}
+	defer resp.Body.Close()
+	removeHopHeaders(resp.Header)
+	// without a length the body ends when the connection does
+	keep := !clientClose && (resp.ContentLength >= 0 || len(resp.TransferEncoding) > 0)
+	resp.Close = !keep
+	err = resp.Write(countingWriter{shapedWriter{s.ctx, c, s.limiters(false)}, s.addIn})
+	w.untrack(s, err)
+	return keep && err == nil
+}
+
+// admitHTTP answers 403 and returns why when the server does not take
+// the request.
+func (w *Socks5ServerWrapper) admitHTTP(c net.Conn, s *session, target string) error {
+	err := errCommandDisabled
+	if w.serves(s.info.Command) {
+		if w.admit(s, target) {
+			return nil
+		}
+		err = errNotAllowed
+	}
+	httpError(c, http.StatusForbidden)
+	return err
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, f := range strings.Split(h.Get("Connection"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			h.Del(f)
+		}
+	}
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+// httpError answers with an empty response and closes the exchange;
+// kv are extra header pairs.
+func httpError(c net.Conn, code int, kv ...string) {
+	resp := &http.Response{
+		StatusCode: code,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Close:      true,
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		resp.Header.Set(kv[i], kv[i+1])
+	}
+	resp.Write(c)
+}
+
+func parseProxyAuth(h string) (user, pass string, ok bool) {
+	const prefix = "Basic "
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	b, err := base64.StdEncoding.DecodeString(h[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(b), ":")
+}
+
+// withPort adds port to host when it has none.
+func withPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
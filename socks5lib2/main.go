@@ -2,21 +2,22 @@ package main
 
 /*
 #include <stdint.h>
+#include <stdlib.h>
 */
 import "C"
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"log"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
-	bridge "bridge"
+	core "core"
+	bridge "core/bridge"
 
 	socks5 "github.com/txthinking/socks5"
 )
@@ -32,190 +33,272 @@ var (
 	nextSrvID     int64 // atomic increment
 )
 
-type taskRec struct {
-	cancel context.CancelFunc
-	done   chan struct{}
-}
-
-var (
-	tasks    = map[int64]*taskRec{}
-	tasksMu  sync.Mutex
-	nextTask int64 // start from 0; atomic increment gives 1,2,...
-)
-var (
-	registeredPort int64
-	portMu         sync.RWMutex
-)
-
-type simpleResp struct {
-	Op      string      `json:"op"`
-	Success bool        `json:"success"`
-	Error   string      `json:"error,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-}
-
 type Socks5ServerWrapper struct {
 	Server *socks5.Server
 	ID     int64
-}
 
-type ProxyHandler struct {
-	ProxyAddr string
-	ProxyUser string
-	ProxyPass string
+	users  *userStore
+	acl    aclHolder
+	dns    dnsHolder
+	shaper *shaper
+	guard  *guard
+	call   core.Call // the StartSocks5Server call, for connection events
+
+	mu       sync.Mutex
+	up       upstream // swapped by UpdateServerConfig, see currentUpstream
+	ln       *net.TCPListener
+	uc       *net.UDPConn
+	bound    bool // set by the one StartSocks5Server that binds
+	closed   bool
+	off      map[string]bool // protocols and commands turned off, see serves
+	tcpIdle  time.Duration   // relays idle this long are closed; 0 never
+	udpIdle  time.Duration   // likewise for UDP associations
+	publicIP net.IP          // advertised for UDP ASSOCIATE; nil for the local address
+	egress   *egress         // how outbound connections leave; nil for the defaults
+	sessions map[int64]*session
+	nextSess int64 // atomic increment
+	udp      udpRelay
 }
 
-func (h *ProxyHandler) TCPHandle(s *socks5.Server, c *net.TCPConn, r *socks5.Request) error {
-	client, err := socks5.NewClient(h.ProxyAddr, h.ProxyUser, h.ProxyPass, 0, 0)
-	if err != nil {
-		return err
+// newServerWrapper wraps server, seeding its user store with the
+// server's own credentials when it has any.
+func newServerWrapper(server *socks5.Server, up upstream) (*Socks5ServerWrapper, error) {
+	users := newUserStore()
+	if server.UserName != "" && server.Password != "" {
+		if err := users.add(server.UserName, server.Password); err != nil {
+			return nil, err
+		}
 	}
-	defer client.Close()
-
-	conn, err := client.Dial("tcp", r.Address())
-	if err != nil {
-		return err
+	w := &Socks5ServerWrapper{
+		Server:   server,
+		ID:       atomic.AddInt64(&nextSrvID, 1),
+		up:       up,
+		users:    users,
+		shaper:   newShaper(),
+		guard:    newGuard(),
+		off:      defaultOff(),
+		tcpIdle:  time.Duration(server.TCPTimeout) * time.Second,
+		udpIdle:  time.Duration(server.UDPTimeout) * time.Second,
+		sessions: make(map[int64]*session),
 	}
-	defer conn.Close()
-
-	// Reply success
-	rep := socks5.NewReply(socks5.RepSuccess, socks5.ATYPIPv4, []byte{0, 0, 0, 0}, []byte{0, 0})
-	if _, err := rep.WriteTo(c); err != nil {
-		return err
+	if ua, ok := server.ServerAddr.(*net.UDPAddr); ok && ua.IP != nil && !ua.IP.IsUnspecified() {
+		w.publicIP = ua.IP
 	}
-
-	// Forward data
-	go io.Copy(conn, c)
-	io.Copy(c, conn)
-	return nil
+	return w, nil
 }
 
-func (h *ProxyHandler) UDPHandle(s *socks5.Server, addr *net.UDPAddr, d *socks5.Datagram) error {
-	// For simplicity, handle as direct; full UDP proxy through another SOCKS5 requires additional forwarding logic
-	dh := &socks5.DefaultHandle{}
-	return dh.UDPHandle(s, addr, d)
+func init() {
+	core.SetPoster(bridge.SendKeyedStringToPort)
+	bridge.Logger = core.BridgeLogger
+	core.CaptureStdLog()
+	core.OnShutdown("socks5 servers", shutdownServers)
+	core.OnShutdown("socks5 client sessions", closeClients)
 }
 
-func addTask(cancel context.CancelFunc) int64 {
-	id := atomic.AddInt64(&nextTask, 1)
-	entry := &taskRec{
-		cancel: cancel,
-		done:   make(chan struct{}),
+// shutdownServers closes every server; running ones are normally already
+// closing because their task was canceled.
+func shutdownServers(ctx context.Context) []string {
+	socks5SrvMu.Lock()
+	ws := make([]*Socks5ServerWrapper, 0, len(socks5Servers))
+	for id, w := range socks5Servers {
+		ws = append(ws, w)
+		delete(socks5Servers, id)
 	}
-	tasksMu.Lock()
-	tasks[id] = entry
-	tasksMu.Unlock()
-	return id
-}
-
-func finishTask(id int64) {
-	tasksMu.Lock()
-	if e, ok := tasks[id]; ok {
-		close(e.done)
-		delete(tasks, id)
+	socks5SrvMu.Unlock()
+	for _, w := range ws {
+		w.close()
 	}
-	tasksMu.Unlock()
+	return nil
 }
 
 //export RegisterPort
 func RegisterPort(port C.longlong) {
-	portMu.Lock()
-	registeredPort = int64(port)
-	portMu.Unlock()
-	bridge.SendStringToPort(int64(port), "registered")
+	core.RegisterPort(int64(port))
 }
 
 //export UnregisterPort
 func UnregisterPort() {
-	portMu.Lock()
-	p := registeredPort
-	registeredPort = 0
-	portMu.Unlock()
-	if p != 0 {
-		bridge.SendStringToPort(p, "unregistered")
+	if p := core.UnregisterPort(); p != 0 {
+		bridge.CloseQueue(p)
 	}
 }
 
-func getPortOrDefault(p C.longlong) int64 {
-	if int64(p) != 0 {
-		return int64(p)
-	}
-	portMu.RLock()
-	defer portMu.RUnlock()
-	return registeredPort
-}
-
-func sendToPort(port int64, r simpleResp) {
-	b, _ := json.Marshal(r)
-	bridge.SendStringToPort(port, string(b))
-}
-
-// ---- wrapper helpers ----
-func safeOp(port int64, op string, fn func() (interface{}, error)) {
-	defer func() {
-		if r := recover(); r != nil {
-			sendToPort(port, simpleResp{Op: op, Success: false, Error: fmt.Sprintf("panic: %v", r)})
-		}
-	}()
-	res, err := fn()
-	if err != nil {
-		sendToPort(port, simpleResp{Op: op, Success: false, Error: err.Error()})
-		return
-	}
-	sendToPort(port, simpleResp{Op: op, Success: true, Data: res})
-}
-
 //export BridgeInit
 func BridgeInit(api unsafe.Pointer) {
 	// panic-safe init
 	defer func() {
 		if r := recover(); r != nil {
-			port := getPortOrDefault(0)
+			port := core.PortOrDefault(0)
 			if port != 0 {
-				sendToPort(port, simpleResp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
+				core.Send(port, core.Resp{Op: "bridge_init", Success: false, Error: fmt.Sprintf("%v", r)})
 			}
 		}
 	}()
 	bridge.InitDartApi(api)
 }
 
+// ---- outbound queue ----
+
+// targetPort 0 configures the queue of port itself; policy is one of
+// "drop-oldest", "drop-newest" or "coalesce".
+//
+//export ConfigureQueue
+func ConfigureQueue(targetPort C.longlong, capacity C.int, policy *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	target := int64(targetPort)
+	if target == 0 {
+		target = call.Port
+	}
+	pol := C.GoString(policy)
+	call.SafeOp("configure_queue", func() (interface{}, error) {
+		dp, err := bridge.ParseDropPolicy(pol)
+		if err != nil {
+			return nil, err
+		}
+		if err := bridge.ConfigureQueue(target, bridge.QueueConfig{Capacity: int(capacity), Policy: dp}); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"port": target, "capacity": int(capacity), "policy": dp.String()}, nil
+	})
+}
+
+//export GetQueueStats
+func GetQueueStats(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("queue_stats", func() (interface{}, error) {
+		return bridge.Stats(), nil
+	})
+}
+
+// ---- logging ----
+
+// logPort 0 sends records to stderr; an empty level keeps the current one.
+//
+//export SetLogPort
+func SetLogPort(logPort C.longlong, level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_port", func() (interface{}, error) {
+		if lvl != "" {
+			l, err := core.ParseLevel(lvl)
+			if err != nil {
+				return nil, err
+			}
+			core.SetLogLevel(l)
+		}
+		core.SetLogPort(int64(logPort))
+		return map[string]interface{}{"port": int64(logPort), "level": core.LogLevel().String()}, nil
+	})
+}
+
+//export SetLogLevel
+func SetLogLevel(level *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	lvl := C.GoString(level)
+	call.SafeOp("set_log_level", func() (interface{}, error) {
+		l, err := core.ParseLevel(lvl)
+		if err != nil {
+			return nil, err
+		}
+		core.SetLogLevel(l)
+		return l.String(), nil
+	})
+}
+
+// ---- subscriptions ----
+
+// Subscribe adds subPort to topic. This library publishes "tasks", "logs",
+// "socks5.connections" and "socks5.upstreams"; see core/topic.go. Every
+// subscriber gets its own copy of each event.
+//
+//export Subscribe
+func Subscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("subscribe", func() (interface{}, error) {
+		if err := core.Subscribe(t, int64(subPort)); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"topic": t, "subscribers": core.Subscribers(t)}, nil
+	})
+}
+
+// An empty topic removes subPort from every topic.
+//
+//export Unsubscribe
+func Unsubscribe(topic *C.char, subPort C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	t := C.GoString(topic)
+	call.SafeOp("unsubscribe", func() (interface{}, error) {
+		core.Unsubscribe(t, int64(subPort))
+		return map[string]interface{}{"topic": t, "port": int64(subPort)}, nil
+	})
+}
+
+//export ListSubscriptions
+func ListSubscriptions(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_subscriptions", func() (interface{}, error) {
+		return core.Subscriptions(), nil
+	})
+}
+
+// ---- shutdown ----
+
+// Shutdown cancels every task and stops everything the library started,
+// waiting at most timeoutMs (5s when <= 0). The reply lists what stopped
+// and what had to be abandoned.
+//
+//export Shutdown
+func Shutdown(timeoutMs C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	call.SafeOp("shutdown", func() (interface{}, error) {
+		return core.Shutdown(timeout), nil
+	})
+}
+
 // ---- SOCKS5 Server Exports ----
+//
+// A server can also speak HTTP proxy (CONNECT and absolute-URI requests)
+// on its port, with Basic auth against the same users, once SetHTTPProxy
+// or its config's handler.protocols turns it on.
 
 //export CreateDirectServerTCP
-func CreateDirectServerTCP(listenPort C.int, username *C.char, password *C.char, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func CreateDirectServerTCP(listenPort C.int, username *C.char, password *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	lPort := int(listenPort)
 	uName := C.GoString(username)
 	pwd := C.GoString(password)
 
-	safeOp(p, "create_direct_server_tcp", func() (interface{}, error) {
+	return C.longlong(call.Create("create_direct_server_tcp", func() (int64, error) {
 		server, err := socks5.NewClassicServer(":"+strconv.Itoa(lPort), "", uName, pwd, 0, 0)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		server.Handle = &socks5.DefaultHandle{}
-		id := atomic.AddInt64(&nextSrvID, 1)
-		wrapper := &Socks5ServerWrapper{
-			Server: server,
-			ID:     id,
+		wrapper, err := newServerWrapper(server, directUpstream{})
+		if err != nil {
+			return 0, err
 		}
 		socks5SrvMu.Lock()
-		socks5Servers[id] = wrapper
+		socks5Servers[wrapper.ID] = wrapper
 		socks5SrvMu.Unlock()
-		return id, nil
-	})
-	return 0
+		return wrapper.ID, nil
+	}))
 }
 
 //export CreateDirectServerUDP
-func CreateDirectServerUDP(listenPort C.int, username *C.char, password *C.char, port C.longlong) C.longlong {
+func CreateDirectServerUDP(listenPort C.int, username *C.char, password *C.char, reqID C.longlong, port C.longlong) C.longlong {
 	// Same as TCP since the library handles both
-	return CreateDirectServerTCP(listenPort, username, password, port)
+	return CreateDirectServerTCP(listenPort, username, password, reqID, port)
 }
 
 //export CreateProxyToSocks5ServerTCP
-func CreateProxyToSocks5ServerTCP(listenPort C.int, username *C.char, password *C.char, proxyAddr *C.char, proxyUser *C.char, proxyPass *C.char, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func CreateProxyToSocks5ServerTCP(listenPort C.int, username *C.char, password *C.char, proxyAddr *C.char, proxyUser *C.char, proxyPass *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	lPort := int(listenPort)
 	uName := C.GoString(username)
 	pwd := C.GoString(password)
@@ -223,119 +306,681 @@ func CreateProxyToSocks5ServerTCP(listenPort C.int, username *C.char, password *
 	pxUser := C.GoString(proxyUser)
 	pxPwd := C.GoString(proxyPass)
 
-	safeOp(p, "create_proxy_to_socks5_server_tcp", func() (interface{}, error) {
+	return C.longlong(call.Create("create_proxy_to_socks5_server_tcp", func() (int64, error) {
 		server, err := socks5.NewClassicServer(":"+strconv.Itoa(lPort), "", uName, pwd, 0, 0)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		server.Handle = &ProxyHandler{
-			ProxyAddr: pxAddr,
-			ProxyUser: pxUser,
-			ProxyPass: pxPwd,
-		}
-		id := atomic.AddInt64(&nextSrvID, 1)
-		wrapper := &Socks5ServerWrapper{
-			Server: server,
-			ID:     id,
+		wrapper, err := newServerWrapper(server, &chainUpstream{hops: []Hop{{Addr: pxAddr, User: pxUser, Pass: pxPwd}}})
+		if err != nil {
+			return 0, err
 		}
 		socks5SrvMu.Lock()
-		socks5Servers[id] = wrapper
+		socks5Servers[wrapper.ID] = wrapper
 		socks5SrvMu.Unlock()
-		return id, nil
-	})
-	return 0
+		return wrapper.ID, nil
+	}))
 }
 
 //export CreateProxyToSocks5ServerUDP
-func CreateProxyToSocks5ServerUDP(listenPort C.int, username *C.char, password *C.char, proxyAddr *C.char, proxyUser *C.char, proxyPass *C.char, port C.longlong) C.longlong {
-	// Placeholder as direct; full UDP proxy not implemented
-	log.Println("UDP proxy through another SOCKS5 not fully implemented; using direct.")
-	return CreateDirectServerTCP(listenPort, username, password, port)
+func CreateProxyToSocks5ServerUDP(listenPort C.int, username *C.char, password *C.char, proxyAddr *C.char, proxyUser *C.char, proxyPass *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	// the proxy server relays UDP ASSOCIATE through the upstream as well
+	return CreateProxyToSocks5ServerTCP(listenPort, username, password, proxyAddr, proxyUser, proxyPass, reqID, port)
+}
+
+// CreateProxyChainServer is CreateProxyToSocks5ServerTCP with several
+// upstream hops, given as a JSON array of {"addr", "user", "pass"} in
+// dialing order. Errors while dialing name the hop that failed.
+//
+//export CreateProxyChainServer
+func CreateProxyChainServer(listenPort C.int, username *C.char, password *C.char, hopsJSON *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	lPort := int(listenPort)
+	uName := C.GoString(username)
+	pwd := C.GoString(password)
+	hopsStr := C.GoString(hopsJSON)
+
+	return C.longlong(call.Create("create_proxy_chain_server", func() (int64, error) {
+		hops, err := parseHops(hopsStr)
+		if err != nil {
+			return 0, core.WithCode(core.CodeInvalidArg, err)
+		}
+		server, err := socks5.NewClassicServer(":"+strconv.Itoa(lPort), "", uName, pwd, 0, 0)
+		if err != nil {
+			return 0, err
+		}
+		wrapper, err := newServerWrapper(server, &chainUpstream{hops: hops})
+		if err != nil {
+			return 0, err
+		}
+		socks5SrvMu.Lock()
+		socks5Servers[wrapper.ID] = wrapper
+		socks5SrvMu.Unlock()
+		return wrapper.ID, nil
+	}))
+}
+
+// CreateProxyPoolServer balances clients over a pool of upstream SOCKS5
+// proxies with health checks and failover; see PoolConfig for poolJSON.
+// Health changes are published on "socks5.upstreams".
+//
+//export CreateProxyPoolServer
+func CreateProxyPoolServer(listenPort C.int, username *C.char, password *C.char, poolJSON *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	lPort := int(listenPort)
+	uName := C.GoString(username)
+	pwd := C.GoString(password)
+	poolStr := C.GoString(poolJSON)
+
+	return C.longlong(call.Create("create_proxy_pool_server", func() (int64, error) {
+		cfg, err := parsePool(poolStr)
+		if err != nil {
+			return 0, core.WithCode(core.CodeInvalidArg, err)
+		}
+		server, err := socks5.NewClassicServer(":"+strconv.Itoa(lPort), "", uName, pwd, 0, 0)
+		if err != nil {
+			return 0, err
+		}
+		pool := newPoolUpstream(cfg)
+		wrapper, err := newServerWrapper(server, pool)
+		if err != nil {
+			return 0, err
+		}
+		pool.start(wrapper.upstreamEvent, wrapper.serverEgress)
+		socks5SrvMu.Lock()
+		socks5Servers[wrapper.ID] = wrapper
+		socks5SrvMu.Unlock()
+		return wrapper.ID, nil
+	}))
+}
+
+// CreateServerFromConfig creates a server from a ServerConfig, which
+// covers everything the other constructors fix: listen address, public
+// UDP address, timeouts, auth, protocols, upstreams, ACL, limits, DNS,
+// egress and guard.
+// An invalid config fails with CodeInvalidArg and a message listing every
+// bad field.
+//
+//export CreateServerFromConfig
+func CreateServerFromConfig(configJSON *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	cfgStr := C.GoString(configJSON)
+
+	return C.longlong(call.Create("create_server_from_config", func() (int64, error) {
+		st, err := parseServerConfig(cfgStr, false)
+		if err != nil {
+			return 0, core.WithCode(core.CodeInvalidArg, err)
+		}
+		wrapper, err := newServerFromSetup(st)
+		if err != nil {
+			return 0, err
+		}
+		socks5SrvMu.Lock()
+		socks5Servers[wrapper.ID] = wrapper
+		socks5SrvMu.Unlock()
+		return wrapper.ID, nil
+	}))
+}
+
+// UpdateServerConfig changes a server, running or not, without dropping
+// its clients. configJSON is a ServerConfig holding only the fields to
+// change. Users, handler, timeouts, upstreams, ACL, limits, DNS, egress
+// and guard apply to new connections; the reply (a ConfigUpdate) lists
+// them, and lists bind_ip and port as needing a restart when they differ.
+//
+//export UpdateServerConfig
+func UpdateServerConfig(srvID C.longlong, configJSON *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	cfgStr := C.GoString(configJSON)
+	call.SafeOp("update_server_config", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		st, err := parseServerConfig(cfgStr, true)
+		if err != nil {
+			return nil, core.WithCode(core.CodeInvalidArg, err)
+		}
+		return w.update(st), nil
+	})
 }
 
 //export CreateWithAuthServer
-func CreateWithAuthServer(listenPort C.int, port C.longlong) C.longlong {
-	return CreateDirectServerTCP(listenPort, C.CString("user"), C.CString("pass"), port)
+func CreateWithAuthServer(listenPort C.int, reqID C.longlong, port C.longlong) C.longlong {
+	return CreateDirectServerTCP(listenPort, C.CString("user"), C.CString("pass"), reqID, port)
 }
 
 //export CreateWithoutAuthServer
-func CreateWithoutAuthServer(listenPort C.int, port C.longlong) C.longlong {
-	return CreateDirectServerTCP(listenPort, C.CString(""), C.CString(""), port)
+func CreateWithoutAuthServer(listenPort C.int, reqID C.longlong, port C.longlong) C.longlong {
+	return CreateDirectServerTCP(listenPort, C.CString(""), C.CString(""), reqID, port)
 }
 
 //export StartSocks5Server
-func StartSocks5Server(srvID C.longlong, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func StartSocks5Server(srvID C.longlong, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	id := int64(srvID)
 	socks5SrvMu.Lock()
 	wrapper, ok := socks5Servers[id]
 	socks5SrvMu.Unlock()
 	if !ok {
-		sendToPort(p, simpleResp{Op: "start_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
+		call.Send(core.Resp{Op: "start_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
 		return 0
 	}
 
-	_, cancel := context.WithCancel(context.Background())
-	taskID := addTask(cancel)
-
-	go func(tid int64, w *Socks5ServerWrapper) {
-		defer finishTask(tid)
-		defer func() {
-			if r := recover(); r != nil {
-				sendToPort(p, simpleResp{Op: "start_socks5_server", Success: false, Error: fmt.Sprintf("%v", r)})
-			}
-		}()
+	// binding here keeps a failed or repeated start from touching a server
+	// that another call is running
+	w := wrapper
+	if err := w.listen(); err != nil {
+		call.Send(core.Resp{Op: "start_socks5_server", Success: false, Error: err.Error()})
+		return 0
+	}
+	w.mu.Lock()
+	w.call = call
+	w.mu.Unlock()
+	taskID := core.Go("start_socks5_server", call, func(ctx context.Context, tid int64) {
+		// the server is this run's from here on
 		defer func() {
 			socks5SrvMu.Lock()
 			delete(socks5Servers, w.ID)
 			socks5SrvMu.Unlock()
-			w.Server.Shutdown()
+			w.close()
 		}()
+		// StopTask cancels ctx; closing the listeners unblocks serve
+		stop := context.AfterFunc(ctx, w.close)
+		defer stop()
 
-		err := w.Server.ListenAndServe(w.Server.Handle)
+		err := w.serve()
 		if err != nil {
-			sendToPort(p, simpleResp{Op: "start_socks5_server", Success: false, Error: err.Error()})
+			call.Send(core.Resp{Op: "start_socks5_server", Success: false, Error: err.Error()})
 			return
 		}
-		sendToPort(p, simpleResp{Op: "start_socks5_server", Success: true, Data: tid})
-	}(taskID, wrapper)
+		call.Send(core.Resp{Op: "start_socks5_server", Success: true, Data: tid})
+	})
 
 	return C.longlong(taskID)
 }
 
 //export StopSocks5Server
-func StopSocks5Server(srvID C.longlong, port C.longlong) {
-	p := getPortOrDefault(port)
+func StopSocks5Server(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
 	id := int64(srvID)
 	socks5SrvMu.Lock()
 	wrapper, ok := socks5Servers[id]
+	delete(socks5Servers, id)
 	socks5SrvMu.Unlock()
 	if !ok {
-		sendToPort(p, simpleResp{Op: "stop_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
-		return
-	}
-	err := wrapper.Server.Shutdown()
-	if err != nil {
-		sendToPort(p, simpleResp{Op: "stop_socks5_server", Success: false, Error: fmt.Sprintf("shutdown error: %v", err)})
+		call.Send(core.Resp{Op: "stop_socks5_server", Success: false, Error: fmt.Sprintf("server %d not found", id)})
 		return
 	}
+	wrapper.close()
+	call.Send(core.Resp{Op: "stop_socks5_server", Success: true, Data: id})
+}
+
+// ---- connections ----
+
+func lookupServer(id int64) (*Socks5ServerWrapper, error) {
 	socks5SrvMu.Lock()
-	delete(socks5Servers, id)
-	socks5SrvMu.Unlock()
-	sendToPort(p, simpleResp{Op: "stop_socks5_server", Success: true, Data: id})
+	defer socks5SrvMu.Unlock()
+	w, ok := socks5Servers[id]
+	if !ok {
+		return nil, core.WithCode(core.CodeNotFound, fmt.Errorf("server %d not found", id))
+	}
+	return w, nil
+}
+
+//export ListConnections
+func ListConnections(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_connections", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return w.Sessions(), nil
+	})
+}
+
+//export KillConnection
+func KillConnection(srvID C.longlong, connID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("kill_connection", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if err := w.Kill(int64(connID)); err != nil {
+			return nil, core.WithCode(core.CodeNotFound, err)
+		}
+		return int64(connID), nil
+	})
+}
+
+//export GetUpstreamHealth
+func GetUpstreamHealth(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_upstream_health", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		p, ok := w.currentUpstream().(*poolUpstream)
+		if !ok {
+			return nil, core.WithCode(core.CodeInvalidArg, fmt.Errorf("server %d has no upstream pool", w.ID))
+		}
+		return p.Health(), nil
+	})
+}
+
+// SetLegacySocks turns SOCKS4 and SOCKS4a support on a server on
+// (enabled != 0) or off, the default. SOCKS4 carries its user id, which
+// this server checks as a password, in the clear. New connections only.
+//
+//export SetLegacySocks
+func SetLegacySocks(srvID C.longlong, enabled C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("set_legacy_socks", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		w.mu.Lock()
+		w.off["socks4"] = enabled == 0
+		w.mu.Unlock()
+		return enabled != 0, nil
+	})
+}
+
+// SetHTTPProxy turns the HTTP proxy on a server on (enabled != 0) or off,
+// the default. New connections only.
+//
+//export SetHTTPProxy
+func SetHTTPProxy(srvID C.longlong, enabled C.int, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("set_http_proxy", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		w.mu.Lock()
+		w.off["http"] = enabled == 0
+		w.mu.Unlock()
+		return enabled != 0, nil
+	})
+}
+
+// ---- users ----
+//
+// A server with no users accepts clients without authentication; adding
+// the first user turns authentication on for new connections.
+
+//export AddUser
+func AddUser(srvID C.longlong, username *C.char, password *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	user, pass := C.GoString(username), C.GoString(password)
+	call.SafeOp("add_user", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return user, w.users.add(user, pass)
+	})
+}
+
+//export RemoveUser
+func RemoveUser(srvID C.longlong, username *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	user := C.GoString(username)
+	call.SafeOp("remove_user", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return user, w.users.remove(user)
+	})
+}
+
+//export SetUserPassword
+func SetUserPassword(srvID C.longlong, username *C.char, password *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	user, pass := C.GoString(username), C.GoString(password)
+	call.SafeOp("set_user_password", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return user, w.users.setPassword(user, pass)
+	})
+}
+
+//export ListUsers
+func ListUsers(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_users", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return w.users.list(), nil
+	})
+}
+
+// LoadUsersFile replaces a server's users with those in a JSON
+// {"user": "password"} file or a bcrypt htpasswd file.
+//
+//export LoadUsersFile
+func LoadUsersFile(srvID C.longlong, path *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	p := C.GoString(path)
+	call.SafeOp("load_users_file", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		users, err := loadUsersFile(p)
+		if err != nil {
+			return nil, err
+		}
+		w.users.replace(users)
+		return w.users.list(), nil
+	})
+}
+
+// ---- bandwidth ----
+
+// SetBandwidthLimits replaces a server's shaping config; see
+// ShapingConfig for the format. It applies to running transfers too.
+//
+//export SetBandwidthLimits
+func SetBandwidthLimits(srvID C.longlong, limitsJSON *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	js := C.GoString(limitsJSON)
+	call.SafeOp("set_bandwidth_limits", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := parseShaping(js)
+		if err != nil {
+			return nil, core.WithCode(core.CodeInvalidArg, err)
+		}
+		w.SetShaping(cfg)
+		return cfg, nil
+	})
+}
+
+//export GetBandwidthLimits
+func GetBandwidthLimits(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_bandwidth_limits", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return w.shaper.config(), nil
+	})
+}
+
+// SetConnectionLimits overrides the per-connection limits of one live
+// connection, in bytes per second with 0 for unlimited. Later
+// SetBandwidthLimits calls leave it alone.
+//
+//export SetConnectionLimits
+func SetConnectionLimits(srvID C.longlong, connID C.longlong, uploadBps C.longlong, downloadBps C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	l := Limits{UploadBps: int64(uploadBps), DownloadBps: int64(downloadBps)}
+	call.SafeOp("set_connection_limits", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if l.UploadBps < 0 || l.DownloadBps < 0 {
+			return nil, core.WithCode(core.CodeInvalidArg, fmt.Errorf("limits must not be negative"))
+		}
+		if err := w.SetSessionLimits(int64(connID), l); err != nil {
+			return nil, core.WithCode(core.CodeNotFound, err)
+		}
+		return l, nil
+	})
+}
+
+// ---- access control ----
+
+// SetACL replaces a server's destination rules; see ACLConfig for the
+// format. An empty string or null removes them, allowing everything.
+//
+//export SetACL
+func SetACL(srvID C.longlong, aclJSON *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	js := strings.TrimSpace(C.GoString(aclJSON))
+	call.SafeOp("set_acl", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if js == "" || js == "null" {
+			w.acl.set(nil)
+			return nil, nil
+		}
+		a, err := parseACL(js)
+		if err != nil {
+			return nil, core.WithCode(core.CodeInvalidArg, err)
+		}
+		w.acl.set(a)
+		return len(a.rules), nil
+	})
+}
+
+//export GetACL
+func GetACL(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_acl", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if a := w.acl.get(); a != nil {
+			return a.cfg, nil
+		}
+		return nil, nil
+	})
+}
+
+// SetDNSConfig sets how a server resolves destination hostnames; see
+// DNSConfig for the format. An empty string or null restores the default,
+// which resolves with the system for direct servers and leaves names to
+// the upstream proxy otherwise.
+//
+//export SetDNSConfig
+func SetDNSConfig(srvID C.longlong, dnsJSON *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	js := strings.TrimSpace(C.GoString(dnsJSON))
+	call.SafeOp("set_dns_config", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if js == "" || js == "null" {
+			w.dns.set(nil)
+			return nil, nil
+		}
+		r, err := parseDNS(js)
+		if err != nil {
+			return nil, core.WithCode(core.CodeInvalidArg, err)
+		}
+		w.dns.set(r)
+		return nil, nil
+	})
+}
+
+//export GetDNSConfig
+func GetDNSConfig(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_dns_config", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if r := w.dns.get(); r != nil {
+			return r.cfg, nil
+		}
+		return nil, nil
+	})
+}
+
+// SetEgress sets how a server's outbound connections leave the host; see
+// Egress for the format. An empty string or null restores the system
+// defaults. ACL rules can override it per request.
+//
+//export SetEgress
+func SetEgress(srvID C.longlong, egressJSON *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	js := strings.TrimSpace(C.GoString(egressJSON))
+	call.SafeOp("set_egress", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		var e *egress
+		if js != "" && js != "null" {
+			if e, err = parseEgress(js); err != nil {
+				return nil, core.WithCode(core.CodeInvalidArg, err)
+			}
+		}
+		w.mu.Lock()
+		w.egress = e
+		w.mu.Unlock()
+		return nil, nil
+	})
+}
+
+//export GetEgress
+func GetEgress(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_egress", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if e := w.serverEgress(); e != nil {
+			return e.cfg, nil
+		}
+		return nil, nil
+	})
+}
+
+// ResolveHost resolves host the way the server would for a client, which
+// is handy for checking a resolver configuration.
+//
+//export ResolveHost
+func ResolveHost(srvID C.longlong, host *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	h := C.GoString(host)
+	call.SafeOp("resolve_host", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		ips, err := w.lookupIP(context.Background(), h)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]string, len(ips))
+		for i, ip := range ips {
+			out[i] = ip.String()
+		}
+		return out, nil
+	})
+}
+
+// SetGuard sets a server's brute-force and connection limits; see
+// GuardConfig for the format. An empty string or null turns them all off.
+// Bans in force and live connection counts are kept.
+//
+//export SetGuard
+func SetGuard(srvID C.longlong, guardJSON *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	js := strings.TrimSpace(C.GoString(guardJSON))
+	call.SafeOp("set_guard", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		var cfg GuardConfig
+		if js != "" && js != "null" {
+			if cfg, err = parseGuard(js); err != nil {
+				return nil, core.WithCode(core.CodeInvalidArg, err)
+			}
+		}
+		w.guard.set(cfg)
+		return nil, nil
+	})
+}
+
+//export GetGuard
+func GetGuard(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_guard", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return w.guard.config(), nil
+	})
+}
+
+// ListBans returns the source IPs a server currently bans, as a list of
+// Ban.
+//
+//export ListBans
+func ListBans(srvID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_bans", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		return w.guard.bans(), nil
+	})
+}
+
+// ClearBans lifts the ban on ip, or every ban when ip is empty, and
+// forgets the earlier offenses so the next ban starts short again. It
+// returns the IPs that were banned.
+//
+//export ClearBans
+func ClearBans(srvID C.longlong, ip *C.char, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	addr := strings.TrimSpace(C.GoString(ip))
+	call.SafeOp("clear_bans", func() (interface{}, error) {
+		w, err := lookupServer(int64(srvID))
+		if err != nil {
+			return nil, err
+		}
+		if addr != "" {
+			parsed := net.ParseIP(addr)
+			if parsed == nil {
+				return nil, core.WithCode(core.CodeInvalidArg, fmt.Errorf("%q is not an IP address", addr))
+			}
+			addr = parsed.String()
+		}
+		return w.ClearBans(addr), nil
+	})
 }
 
 // ---- SOCKS5 Client Exports ----
 
+// ConnectDirectTCP only checks that targetAddr can be reached through the
+// proxy; ClientOpen keeps the connection.
+//
 //export ConnectDirectTCP
-func ConnectDirectTCP(socksAddr *C.char, username *C.char, password *C.char, targetAddr *C.char, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func ConnectDirectTCP(socksAddr *C.char, username *C.char, password *C.char, targetAddr *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	sAddr := C.GoString(socksAddr)
 	uName := C.GoString(username)
 	pwd := C.GoString(password)
 	tAddr := C.GoString(targetAddr)
 
-	safeOp(p, "connect_direct_tcp", func() (interface{}, error) {
+	call.SafeOp("connect_direct_tcp", func() (interface{}, error) {
 		client, err := socks5.NewClient(sAddr, uName, pwd, 0, 0)
 		if err != nil {
 			return nil, err
@@ -344,21 +989,23 @@ func ConnectDirectTCP(socksAddr *C.char, username *C.char, password *C.char, tar
 		if err != nil {
 			return nil, err
 		}
-		// In a real scenario, you might want to return the connection or handle it; here just confirm
-		conn.Close() // Close immediately for simplicity
+		conn.Close()
 		return "connected", nil
 	})
 	return 0
 }
 
+// ConnectDirectUDP only checks that the proxy grants a UDP association;
+// ClientOpen keeps it.
+//
 //export ConnectDirectUDP
-func ConnectDirectUDP(socksAddr *C.char, username *C.char, password *C.char, port C.longlong) C.longlong {
-	p := getPortOrDefault(port)
+func ConnectDirectUDP(socksAddr *C.char, username *C.char, password *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
 	sAddr := C.GoString(socksAddr)
 	uName := C.GoString(username)
 	pwd := C.GoString(password)
 
-	safeOp(p, "connect_direct_udp", func() (interface{}, error) {
+	call.SafeOp("connect_direct_udp", func() (interface{}, error) {
 		client, err := socks5.NewClient(sAddr, uName, pwd, 0, 0)
 		if err != nil {
 			return nil, err
@@ -373,23 +1020,160 @@ func ConnectDirectUDP(socksAddr *C.char, username *C.char, password *C.char, por
 	return 0
 }
 
-//export StopTask
-func StopTask(taskID C.longlong, port C.longlong) {
-	id := int64(taskID)
-	p := getPortOrDefault(port)
-	tasksMu.Lock()
-	entry, ok := tasks[id]
-	if ok {
-		delete(tasks, id)
+// ClientOpen connects to targetAddr through the SOCKS5 proxy at socksAddr
+// in the background and returns the task id, or a negative error code for
+// invalid arguments. network is "tcp", or "udp" for a UDP association
+// whose datagrams all go to targetAddr. A client_open reply then carries
+// the session id for ClientWrite and ClientClose, or the error; StopTask
+// gives up on a connect still under way.
+//
+// Data from the target arrives on port as binary frames (see
+// bridge.FrameHeader) with op "client_data", kind PayloadSocketData and
+// the session id in the request id field; each UDP datagram is one frame.
+// When the session ends a client_closed event with this call's reqID
+// follows with the byte counts, and an error unless the session was
+// closed or the target hung up. Frames share the port's queue with other
+// messages but are never dropped or coalesced to make room; a queue too
+// full to take one ends the session with an error instead, so size it for
+// the traffic.
+//
+//export ClientOpen
+func ClientOpen(socksAddr *C.char, username *C.char, password *C.char, network *C.char, targetAddr *C.char, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	hop := Hop{Addr: C.GoString(socksAddr), User: C.GoString(username), Pass: C.GoString(password)}
+	nw := strings.ToLower(strings.TrimSpace(C.GoString(network)))
+	tAddr := C.GoString(targetAddr)
+
+	return C.longlong(call.CreateQuiet("client_open", func() (int64, error) {
+		if err := checkClientTarget(nw, tAddr); err != nil {
+			return 0, err
+		}
+		return core.Go("client_open", call, func(ctx context.Context, _ int64) {
+			s, err := openClient(ctx, call, []Hop{hop}, nw, tAddr)
+			if err != nil {
+				call.Send(core.Resp{Op: "client_open", Success: false, Error: err.Error()})
+				return
+			}
+			call.Send(core.Resp{Op: "client_open", Success: true, Data: s.info.ID})
+			// only now, so the id reaches Dart before the first frame
+			s.start()
+		}), nil
+	}))
+}
+
+// ClientWrite queues length bytes at data to be sent on a client session
+// and returns length, or a negative error code. Only failures are posted
+// to port. Writes go out in order; one that fails ends the session. It
+// only blocks when many writes are already waiting.
+//
+//export ClientWrite
+func ClientWrite(sessionID C.longlong, data *C.char, length C.int, reqID C.longlong, port C.longlong) C.longlong {
+	call := core.NewCall(int64(port), int64(reqID))
+	id := int64(sessionID)
+	var b []byte
+	if length > 0 {
+		b = C.GoBytes(unsafe.Pointer(data), length)
 	}
-	tasksMu.Unlock()
+
+	return C.longlong(call.CreateQuiet("client_write", func() (int64, error) {
+		if length < 0 {
+			return 0, core.WithCode(core.CodeInvalidArg, fmt.Errorf("negative length %d", length))
+		}
+		s, err := lookupClient(id)
+		if err != nil {
+			return 0, err
+		}
+		if len(b) == 0 {
+			return 0, nil
+		}
+		if err := s.send(b); err != nil {
+			return 0, err
+		}
+		return int64(len(b)), nil
+	}))
+}
+
+// ClientClose ends a client session after its queued writes are sent. Its
+// client_closed event goes to the port of the ClientOpen call.
+//
+//export ClientClose
+func ClientClose(sessionID C.longlong, reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	id := int64(sessionID)
+	call.SafeOp("client_close", func() (interface{}, error) {
+		s, err := lookupClient(id)
+		if err != nil {
+			return nil, err
+		}
+		s.end()
+		return id, nil
+	})
+}
+
+//export ListClientSessions
+func ListClientSessions(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_client_sessions", func() (interface{}, error) {
+		return listClients(), nil
+	})
+}
+
+// ---- errors ----
+
+// GetLastError returns the message behind a negative code returned by a
+// Create* call made with reqID, or NULL. Free it with FreeString. reqID 0
+// reads the latest failure among all calls made without a request id.
+//
+//export GetLastError
+func GetLastError(reqID C.longlong) *C.char {
+	msg, ok := core.LastError(int64(reqID))
 	if !ok {
-		sendToPort(p, simpleResp{Op: "stop", Success: false, Error: fmt.Sprintf("task %d not found", id)})
+		return nil
+	}
+	return C.CString(msg)
+}
+
+//export FreeString
+func FreeString(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// ---- task registry ----
+
+// StopTask cancels a task and waits up to core.StopTimeout for it. A task
+// that does not stop by then is abandoned and the reply says forced.
+//
+//export StopTask
+func StopTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
+	id := int64(taskID)
+	call := core.NewCall(int64(port), int64(reqID))
+	forced, err := core.StopTask(id, core.StopTimeout)
+	if err != nil {
+		call.Send(core.Resp{Op: "stop", Success: false, Error: err.Error()})
 		return
 	}
-	entry.cancel()
-	<-entry.done
-	sendToPort(p, simpleResp{Op: "stop", Success: true, Data: id})
+	call.Send(core.Resp{Op: "stop", Success: true, Data: map[string]interface{}{"id": id, "forced": forced}})
+}
+
+//export ListTasks
+func ListTasks(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_tasks", func() (interface{}, error) {
+		return core.ListTasks(), nil
+	})
+}
+
+//export GetTask
+func GetTask(taskID C.longlong, reqID C.longlong, port C.longlong) {
+	id := int64(taskID)
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("get_task", func() (interface{}, error) {
+		info, ok := core.GetTask(id)
+		if !ok {
+			return nil, fmt.Errorf("task %d not found", id)
+		}
+		return info, nil
+	})
 }
 
-func main() {}
\ No newline at end of file
+func main() {}
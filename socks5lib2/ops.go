@@ -0,0 +1,288 @@
+package main
+
+import "C"
+import (
+	core "core"
+	bridge "core/bridge"
+)
+
+// Invoke runs one export by name, as core.Invoke describes. For example
+//
+//	{"method": "StartSocks5Server", "id": 7, "params": {"srvID": 1}}
+//
+// starts server 1 and returns its task id; replies carry request id 7.
+//
+//export Invoke
+func Invoke(requestJSON *C.char, port C.longlong) C.longlong {
+	return C.longlong(core.Invoke([]byte(C.GoString(requestJSON)), int64(port)))
+}
+
+//export ListOperations
+func ListOperations(reqID C.longlong, port C.longlong) {
+	call := core.NewCall(int64(port), int64(reqID))
+	call.SafeOp("list_operations", func() (interface{}, error) {
+		return core.Operations(), nil
+	})
+}
+
+func init() {
+	core.Register(core.Operation{Method: "ConfigureQueue", Params: []core.Param{core.IntParam("targetPort"), core.IntParam("capacity"), core.StrParam("policy")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ConfigureQueue(C.longlong(a.Int64("targetPort")), C.int(a.Int("capacity")), (*C.char)(cs.Str(a.String("policy"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetQueueStats"}, func(call core.Call, a core.Args) int64 {
+		GetQueueStats(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogPort", Params: []core.Param{core.IntParam("logPort"), core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogPort(C.longlong(a.Int64("logPort")), (*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLogLevel", Params: []core.Param{core.StrParam("level")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetLogLevel((*C.char)(cs.Str(a.String("level"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Subscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Subscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Unsubscribe", Params: []core.Param{core.StrParam("topic"), core.IntParam("subPort")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		Unsubscribe((*C.char)(cs.Str(a.String("topic"))), C.longlong(a.Int64("subPort")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListSubscriptions"}, func(call core.Call, a core.Args) int64 {
+		ListSubscriptions(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "Shutdown", Params: []core.Param{core.IntParam("timeoutMs")}}, func(call core.Call, a core.Args) int64 {
+		Shutdown(C.int(a.Int("timeoutMs")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CreateDirectServerTCP", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateDirectServerTCP(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateDirectServerUDP", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateDirectServerUDP(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateProxyToSocks5ServerTCP", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password"), core.StrParam("proxyAddr"), core.StrParam("proxyUser"), core.StrParam("proxyPass")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateProxyToSocks5ServerTCP(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), (*C.char)(cs.Str(a.String("proxyAddr"))), (*C.char)(cs.Str(a.String("proxyUser"))), (*C.char)(cs.Str(a.String("proxyPass"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateProxyToSocks5ServerUDP", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password"), core.StrParam("proxyAddr"), core.StrParam("proxyUser"), core.StrParam("proxyPass")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateProxyToSocks5ServerUDP(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), (*C.char)(cs.Str(a.String("proxyAddr"))), (*C.char)(cs.Str(a.String("proxyUser"))), (*C.char)(cs.Str(a.String("proxyPass"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateProxyChainServer", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password"), core.ListParam("hops")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateProxyChainServer(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), (*C.char)(cs.Str(a.JSON("hops"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateProxyPoolServer", Params: []core.Param{core.IntParam("listenPort"), core.StrParam("username"), core.StrParam("password"), core.ObjectParam("pool")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateProxyPoolServer(C.int(a.Int("listenPort")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), (*C.char)(cs.Str(a.JSON("pool"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateServerFromConfig", Params: []core.Param{core.ObjectParam("config")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(CreateServerFromConfig((*C.char)(cs.Str(a.JSON("config"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "UpdateServerConfig", Params: []core.Param{core.IntParam("srvID"), core.ObjectParam("config")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		UpdateServerConfig(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.JSON("config"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "CreateWithAuthServer", Params: []core.Param{core.IntParam("listenPort")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		return int64(CreateWithAuthServer(C.int(a.Int("listenPort")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "CreateWithoutAuthServer", Params: []core.Param{core.IntParam("listenPort")}, Returns: "id or negative error code"}, func(call core.Call, a core.Args) int64 {
+		return int64(CreateWithoutAuthServer(C.int(a.Int("listenPort")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "StartSocks5Server", Params: []core.Param{core.IntParam("srvID")}, Returns: "task id"}, func(call core.Call, a core.Args) int64 {
+		return int64(StartSocks5Server(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "StopSocks5Server", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		StopSocks5Server(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListConnections", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		ListConnections(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "KillConnection", Params: []core.Param{core.IntParam("srvID"), core.IntParam("connID")}}, func(call core.Call, a core.Args) int64 {
+		KillConnection(C.longlong(a.Int64("srvID")), C.longlong(a.Int64("connID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetUpstreamHealth", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		GetUpstreamHealth(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetLegacySocks", Params: []core.Param{core.IntParam("srvID"), core.IntParam("enabled")}}, func(call core.Call, a core.Args) int64 {
+		SetLegacySocks(C.longlong(a.Int64("srvID")), C.int(a.Int("enabled")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetHTTPProxy", Params: []core.Param{core.IntParam("srvID"), core.IntParam("enabled")}}, func(call core.Call, a core.Args) int64 {
+		SetHTTPProxy(C.longlong(a.Int64("srvID")), C.int(a.Int("enabled")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "AddUser", Params: []core.Param{core.IntParam("srvID"), core.StrParam("username"), core.StrParam("password")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		AddUser(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "RemoveUser", Params: []core.Param{core.IntParam("srvID"), core.StrParam("username")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		RemoveUser(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.String("username"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetUserPassword", Params: []core.Param{core.IntParam("srvID"), core.StrParam("username"), core.StrParam("password")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetUserPassword(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListUsers", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		ListUsers(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "LoadUsersFile", Params: []core.Param{core.IntParam("srvID"), core.StrParam("path")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		LoadUsersFile(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.String("path"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetBandwidthLimits", Params: []core.Param{core.IntParam("srvID"), core.ObjectParam("limits")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetBandwidthLimits(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.JSON("limits"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetBandwidthLimits", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		GetBandwidthLimits(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetConnectionLimits", Params: []core.Param{core.IntParam("srvID"), core.IntParam("connID"), core.IntParam("uploadBps"), core.IntParam("downloadBps")}}, func(call core.Call, a core.Args) int64 {
+		SetConnectionLimits(C.longlong(a.Int64("srvID")), C.longlong(a.Int64("connID")), C.longlong(a.Int64("uploadBps")), C.longlong(a.Int64("downloadBps")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetACL", Params: []core.Param{core.IntParam("srvID"), core.ObjectParam("acl")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetACL(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.JSON("acl"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetACL", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		GetACL(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetDNSConfig", Params: []core.Param{core.IntParam("srvID"), core.ObjectParam("dns")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetDNSConfig(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.JSON("dns"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetDNSConfig", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		GetDNSConfig(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetEgress", Params: []core.Param{core.IntParam("srvID"), core.ObjectParam("egress")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetEgress(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.JSON("egress"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetEgress", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		GetEgress(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ResolveHost", Params: []core.Param{core.IntParam("srvID"), core.StrParam("host")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ResolveHost(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.String("host"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "SetGuard", Params: []core.Param{core.IntParam("srvID"), core.ObjectParam("guard")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		SetGuard(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.JSON("guard"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetGuard", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		GetGuard(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListBans", Params: []core.Param{core.IntParam("srvID")}}, func(call core.Call, a core.Args) int64 {
+		ListBans(C.longlong(a.Int64("srvID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ClearBans", Params: []core.Param{core.IntParam("srvID"), core.StrParam("ip").Opt()}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		ClearBans(C.longlong(a.Int64("srvID")), (*C.char)(cs.Str(a.String("ip"))), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ConnectDirectTCP", Params: []core.Param{core.StrParam("socksAddr"), core.StrParam("username"), core.StrParam("password"), core.StrParam("targetAddr")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(ConnectDirectTCP((*C.char)(cs.Str(a.String("socksAddr"))), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), (*C.char)(cs.Str(a.String("targetAddr"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "ConnectDirectUDP", Params: []core.Param{core.StrParam("socksAddr"), core.StrParam("username"), core.StrParam("password")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(ConnectDirectUDP((*C.char)(cs.Str(a.String("socksAddr"))), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "ClientOpen", Params: []core.Param{core.StrParam("socksAddr"), core.StrParam("username"), core.StrParam("password"), core.StrParam("network"), core.StrParam("targetAddr")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		return int64(ClientOpen((*C.char)(cs.Str(a.String("socksAddr"))), (*C.char)(cs.Str(a.String("username"))), (*C.char)(cs.Str(a.String("password"))), (*C.char)(cs.Str(a.String("network"))), (*C.char)(cs.Str(a.String("targetAddr"))), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "ClientWrite", Params: []core.Param{core.IntParam("sessionID"), core.BytesParam("data")}}, func(call core.Call, a core.Args) int64 {
+		var cs bridge.CArgs
+		defer cs.Free()
+		data := a.Bytes("data")
+		return int64(ClientWrite(C.longlong(a.Int64("sessionID")), (*C.char)(cs.Bytes(data)), C.int(len(data)), C.longlong(call.RequestID), C.longlong(call.Port)))
+	})
+	core.Register(core.Operation{Method: "ClientClose", Params: []core.Param{core.IntParam("sessionID")}}, func(call core.Call, a core.Args) int64 {
+		ClientClose(C.longlong(a.Int64("sessionID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListClientSessions"}, func(call core.Call, a core.Args) int64 {
+		ListClientSessions(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "StopTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		StopTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListTasks"}, func(call core.Call, a core.Args) int64 {
+		ListTasks(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "GetTask", Params: []core.Param{core.IntParam("taskID")}}, func(call core.Call, a core.Args) int64 {
+		GetTask(C.longlong(a.Int64("taskID")), C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+	core.Register(core.Operation{Method: "ListOperations"}, func(call core.Call, a core.Args) int64 {
+		ListOperations(C.longlong(call.RequestID), C.longlong(call.Port))
+		return 0
+	})
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	core "core"
+	socks5 "github.com/txthinking/socks5"
+)
+
+// PoolConfig is the JSON accepted by CreateProxyPoolServer.
+//
+// Strategy is "round_robin" (the default), "least_conn" or "latency",
+// which picks upstreams at random weighted by the inverse of their probe
+// latency. Every CheckIntervalMs each upstream is probed with a SOCKS5
+// handshake and, when CheckTarget is set, a CONNECT to it.
+type PoolConfig struct {
+	Upstreams       []Hop  `json:"upstreams"`
+	Strategy        string `json:"strategy,omitempty"`
+	CheckIntervalMs int    `json:"check_interval_ms,omitempty"`
+	CheckTimeoutMs  int    `json:"check_timeout_ms,omitempty"`
+	CheckTarget     string `json:"check_target,omitempty"`
+}
+
+// UpstreamHealth is the state of one pool member.
+type UpstreamHealth struct {
+	Addr      string    `json:"addr"`
+	Healthy   bool      `json:"healthy"`
+	Active    int64     `json:"active"`
+	LatencyMs float64   `json:"latency_ms"`
+	LastCheck time.Time `json:"last_check,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type poolMember struct {
+	hop    Hop
+	active int64 // atomic
+
+	mu        sync.Mutex
+	healthy   bool
+	latency   time.Duration // smoothed
+	lastCheck time.Time
+	lastErr   string
+}
+
+func (m *poolMember) health() UpstreamHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return UpstreamHealth{
+		Addr:      m.hop.Addr,
+		Healthy:   m.healthy,
+		Active:    atomic.LoadInt64(&m.active),
+		LatencyMs: float64(m.latency) / float64(time.Millisecond),
+		LastCheck: m.lastCheck,
+		Error:     m.lastErr,
+	}
+}
+
+// poolUpstream balances clients over several upstream proxies, each used
+// as a one-hop chain, and fails over to the next one when a dial fails.
+type poolUpstream struct {
+	cfg      PoolConfig
+	members  []*poolMember
+	next     uint64 // atomic round-robin cursor
+	onChange func(UpstreamHealth)
+	egress   func() *egress // for probes
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+const (
+	defaultCheckInterval = 10 * time.Second
+	defaultCheckTimeout  = 5 * time.Second
+)
+
+func parsePool(s string) (PoolConfig, error) {
+	var cfg PoolConfig
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid pool: %v", err)
+	}
+	if len(cfg.Upstreams) == 0 {
+		return cfg, errors.New("at least one upstream is required")
+	}
+	for i, h := range cfg.Upstreams {
+		if _, _, err := net.SplitHostPort(h.Addr); err != nil {
+			return cfg, fmt.Errorf("upstream %d: %v", i+1, err)
+		}
+	}
+	switch cfg.Strategy {
+	case "":
+		cfg.Strategy = "round_robin"
+	case "round_robin", "least_conn", "latency":
+	default:
+		return cfg, fmt.Errorf("unknown strategy %q", cfg.Strategy)
+	}
+	if cfg.CheckTarget != "" {
+		if _, _, err := net.SplitHostPort(cfg.CheckTarget); err != nil {
+			return cfg, fmt.Errorf("check_target: %v", err)
+		}
+	}
+	return cfg, nil
+}
+
+// newPoolUpstream returns a pool whose members count as healthy until
+// their first probe says otherwise.
+func newPoolUpstream(cfg PoolConfig) *poolUpstream {
+	p := &poolUpstream{cfg: cfg, stop: make(chan struct{})}
+	for _, h := range cfg.Upstreams {
+		p.members = append(p.members, &poolMember{hop: h, healthy: true})
+	}
+	return p
+}
+
+// start runs the health checks until Close, reporting changes to onChange.
+// Probes leave the host the way egress says at the time.
+func (p *poolUpstream) start(onChange func(UpstreamHealth), egress func() *egress) {
+	p.onChange, p.egress = onChange, egress
+	go p.checkLoop()
+}
+
+// Close stops the health checks.
+func (p *poolUpstream) Close() error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	return nil
+}
+
+func (p *poolUpstream) Health() []UpstreamHealth {
+	out := make([]UpstreamHealth, len(p.members))
+	for i, m := range p.members {
+		out[i] = m.health()
+	}
+	return out
+}
+
+// candidates orders the members to try: healthy ones by strategy, then
+// the unhealthy ones as a last resort.
+func (p *poolUpstream) candidates() []*poolMember {
+	var healthy, down []*poolMember
+	for _, m := range p.members {
+		if m.health().Healthy {
+			healthy = append(healthy, m)
+		} else {
+			down = append(down, m)
+		}
+	}
+	switch p.cfg.Strategy {
+	case "least_conn":
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return atomic.LoadInt64(&healthy[i].active) < atomic.LoadInt64(&healthy[j].active)
+		})
+	case "latency":
+		healthy = byLatency(healthy)
+	default:
+		if n := len(healthy); n > 0 {
+			k := int(atomic.AddUint64(&p.next, 1) % uint64(n))
+			healthy = append(healthy[k:], healthy[:k]...)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// byLatency draws members without replacement, each with a weight of
+// 1/latency, so faster upstreams are tried first more often.
+func byLatency(ms []*poolMember) []*poolMember {
+	weights := make([]float64, len(ms))
+	for i, m := range ms {
+		l := m.health().LatencyMs
+		if l < 1 {
+			l = 1
+		}
+		weights[i] = 1 / l
+	}
+	out := make([]*poolMember, 0, len(ms))
+	for len(ms) > 0 {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		r := rand.Float64() * total
+		i := 0
+		for ; i < len(ms)-1; i++ {
+			if r -= weights[i]; r < 0 {
+				break
+			}
+		}
+		out = append(out, ms[i])
+		ms = append(ms[:i:i], ms[i+1:]...)
+		weights = append(weights[:i:i], weights[i+1:]...)
+	}
+	return out
+}
+
+// failover tries fn on each candidate until one works. A refused request
+// is the target's fault, not the upstream's, so it is returned as is.
+func (p *poolUpstream) failover(fn func(m *poolMember) error) error {
+	var errs []error
+	for _, m := range p.candidates() {
+		err := fn(m)
+		if err == nil {
+			return nil
+		}
+		var re *replyError
+		if errors.As(err, &re) {
+			return err
+		}
+		p.setHealth(m, false, 0, err)
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("all upstreams failed: %w", errors.Join(errs...))
+}
+
+func (p *poolUpstream) dial(target string, e *egress) (net.Conn, error) {
+	var conn net.Conn
+	err := p.failover(func(m *poolMember) error {
+		c, _, err := dialChain([]Hop{m.hop}, socks5.CmdConnect, target, e)
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&m.active, 1)
+		conn = &poolConn{Conn: c, m: m}
+		return nil
+	})
+	return conn, err
+}
+
+func (p *poolUpstream) associate(e *egress) (datagramRelay, error) {
+	var relay datagramRelay
+	err := p.failover(func(m *poolMember) error {
+		r, err := (&chainUpstream{hops: []Hop{m.hop}}).associate(e)
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&m.active, 1)
+		relay = &poolRelay{datagramRelay: r, m: m}
+		return nil
+	})
+	return relay, err
+}
+
+func (p *poolUpstream) bind(peer string, e *egress) (binding, error) {
+	var b binding
+	err := p.failover(func(m *poolMember) error {
+		cb, err := (&chainUpstream{hops: []Hop{m.hop}}).bind(peer, e)
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&m.active, 1)
+		b = &poolBinding{binding: cb, m: m}
+		return nil
+	})
+	return b, err
+}
+
+// poolConn counts as active on its member until closed.
+type poolConn struct {
+	net.Conn
+	m    *poolMember
+	once sync.Once
+}
+
+func (c *poolConn) Close() error {
+	c.once.Do(func() { atomic.AddInt64(&c.m.active, -1) })
+	return c.Conn.Close()
+}
+
+func (c *poolConn) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return nil
+}
+
+type poolRelay struct {
+	datagramRelay
+	m    *poolMember
+	once sync.Once
+}
+
+func (r *poolRelay) Close() error {
+	r.once.Do(func() { atomic.AddInt64(&r.m.active, -1) })
+	return r.datagramRelay.Close()
+}
+
+type poolBinding struct {
+	binding
+	m    *poolMember
+	once sync.Once
+}
+
+func (b *poolBinding) Close() error {
+	b.once.Do(func() { atomic.AddInt64(&b.m.active, -1) })
+	return b.binding.Close()
+}
+
+func (p *poolUpstream) checkLoop() {
+	interval := time.Duration(p.cfg.CheckIntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultCheckInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		p.checkAll()
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (p *poolUpstream) checkAll() {
+	var wg sync.WaitGroup
+	for _, m := range p.members {
+		wg.Add(1)
+		go func(m *poolMember) {
+			defer wg.Done()
+			d, err := p.probe(m.hop)
+			p.setHealth(m, err == nil, d, err)
+		}(m)
+	}
+	wg.Wait()
+}
+
+// probe times a handshake with hop, plus a CONNECT to the check target
+// when one is configured.
+func (p *poolUpstream) probe(hop Hop) (time.Duration, error) {
+	timeout := time.Duration(p.cfg.CheckTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	start := time.Now()
+	conn, err := p.egress().dialer("tcp", timeout).Dial("tcp", hop.Addr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(start.Add(timeout))
+	if p.cfg.CheckTarget != "" {
+		_, err = hopRequest(conn, hop, socks5.CmdConnect, p.cfg.CheckTarget)
+	} else {
+		err = hopAuth(conn, hop)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// setHealth records a probe or dial result and reports changes of the
+// healthy flag.
+func (p *poolUpstream) setHealth(m *poolMember, ok bool, latency time.Duration, err error) {
+	m.mu.Lock()
+	changed := m.healthy != ok
+	m.healthy = ok
+	m.lastCheck = time.Now()
+	m.lastErr = ""
+	if err != nil {
+		m.lastErr = err.Error()
+	}
+	if ok {
+		if m.latency == 0 {
+			m.latency = latency
+		} else {
+			m.latency = (7*m.latency + 3*latency) / 10
+		}
+	}
+	m.mu.Unlock()
+	if changed && p.onChange != nil {
+		p.onChange(m.health())
+	}
+}
+
+// upstreamEvent reports a pool member going up or down.
+func (w *Socks5ServerWrapper) upstreamEvent(h UpstreamHealth) {
+	r := core.Resp{Op: "upstream_health", Success: h.Healthy, Error: h.Error, Data: map[string]interface{}{
+		"server":   w.ID,
+		"upstream": h,
+	}}
+	w.emit(core.TopicSocks5Upstreams, r)
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	core "core"
+	socks5 "github.com/txthinking/socks5"
+)
+
+// handshakeTimeout bounds how long a client may take from connecting, or
+// from the end of its previous HTTP request, until its session is tracked.
+// After that only the idle timeouts apply.
+const handshakeTimeout = 30 * time.Second
+
+// listen binds the server's TCP and UDP sockets for serve. It refuses a
+// server that is already listening or closed without touching it.
+func (w *Socks5ServerWrapper) listen() error {
+	w.mu.Lock()
+	if w.closed || w.bound {
+		w.mu.Unlock()
+		return fmt.Errorf("server %d already running", w.ID)
+	}
+	w.bound = true
+	w.mu.Unlock()
+
+	ln, uc, err := bind(w.Server.Addr)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.bound = false
+		return err
+	}
+	if w.closed {
+		ln.Close()
+		uc.Close()
+		return fmt.Errorf("server %d closed", w.ID)
+	}
+	w.ln, w.uc = ln, uc
+	return nil
+}
+
+func bind(addr string) (*net.TCPListener, *net.UDPConn, error) {
+	taddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	ln, err := net.ListenTCP("tcp", taddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	uaddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		ln.Close()
+		return nil, nil, err
+	}
+	uc, err := net.ListenUDP("udp", uaddr)
+	if err != nil {
+		ln.Close()
+		return nil, nil, err
+	}
+	return ln, uc, nil
+}
+
+// serve accepts clients on what listen bound until close is called. It
+// replaces socks5.Server.ListenAndServe so that every connection can be
+// tracked; Server only supplies the address and credentials.
+func (w *Socks5ServerWrapper) serve() error {
+	w.mu.Lock()
+	ln, uc := w.ln, w.uc
+	w.mu.Unlock()
+
+	go w.serveUDP(uc)
+	for {
+		c, err := ln.AcceptTCP()
+		if err != nil {
+			if w.isClosed() {
+				return nil
+			}
+			return err
+		}
+		go w.handle(c)
+	}
+}
+
+// currentUpstream returns where new connections go.
+func (w *Socks5ServerWrapper) currentUpstream() upstream {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.up
+}
+
+func (w *Socks5ServerWrapper) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+// close stops accepting and drops every live session. It never blocks and
+// is safe to call more than once.
+func (w *Socks5ServerWrapper) close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	ln, uc, up := w.ln, w.uc, w.up
+	sessions := make([]*session, 0, len(w.sessions))
+	for _, s := range w.sessions {
+		sessions = append(sessions, s)
+	}
+	w.mu.Unlock()
+	if ln != nil {
+		ln.Close()
+	}
+	if uc != nil {
+		uc.Close()
+	}
+	for _, s := range sessions {
+		s.kill()
+	}
+	if c, ok := up.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// handle tells protocols apart by the first byte: 5 is a SOCKS5 greeting,
+// 4 a SOCKS4 request, anything else is taken for an HTTP proxy request.
+// Connections the guard refuses are closed unanswered.
+func (w *Socks5ServerWrapper) handle(tc *net.TCPConn) {
+	defer tc.Close()
+	ip := hostOf(tc.RemoteAddr())
+	release, err := w.guard.admit(ip)
+	if err != nil {
+		return
+	}
+	defer release()
+	tc.SetDeadline(time.Now().Add(handshakeTimeout))
+	br := bufio.NewReader(tc)
+	b, err := br.Peek(1)
+	if err != nil {
+		return
+	}
+	c := &bufferedConn{TCPConn: tc, r: br}
+	switch b[0] {
+	case socks5.Ver:
+		if w.serves("socks5") {
+			w.handleSocks5(c)
+		}
+	case socks4Ver:
+		if w.serves("socks4") {
+			w.handleSocks4(c)
+		}
+	default:
+		if w.serves("http") {
+			w.serveHTTP(c)
+		}
+	}
+}
+
+func (w *Socks5ServerWrapper) handleSocks5(c net.Conn) {
+	user, err := w.negotiate(c)
+	if err != nil {
+		w.handshakeFailed(c, "socks5", err)
+		return
+	}
+	defer w.guard.releaseUser(user)
+	r, err := socks5.NewRequestFrom(c)
+	if err != nil {
+		w.handshakeFailed(c, "socks5", err)
+		return
+	}
+	s := w.track(c, "socks5", user, r.Address(), r.Cmd)
+	// a UDP ASSOCIATE names the client, not a destination
+	target := r.Address()
+	if r.Cmd == socks5.CmdUDP {
+		target = ""
+	}
+	if !w.serves(s.info.Command) {
+		writeReply(c, socks5.RepCommandNotSupported)
+		w.untrack(s, errCommandDisabled)
+		return
+	}
+	if !w.admit(s, target) {
+		writeReply(c, socks5.RepNotAllowed)
+		w.untrack(s, errNotAllowed)
+		return
+	}
+	switch r.Cmd {
+	case socks5.CmdConnect:
+		err = w.connect(s, c, r)
+	case socks5.CmdUDP:
+		err = w.associate(s, c, r)
+	case socks5.CmdBind:
+		err = w.bind(s, c, r)
+	default:
+		writeReply(c, socks5.RepCommandNotSupported)
+		err = fmt.Errorf("command %s not supported", commandName(r.Cmd))
+	}
+	w.untrack(s, err)
+}
+
+// handshakeFailed logs why a client was turned away before it had a
+// session. Any client can cause these, so they are debug records.
+func (w *Socks5ServerWrapper) handshakeFailed(c net.Conn, proto string, err error) {
+	core.Log(core.LevelDebug, "socks5", "handshake failed", map[string]interface{}{
+		"server":   w.ID,
+		"client":   c.RemoteAddr().String(),
+		"protocol": proto,
+		"error":    err.Error(),
+	})
+}
+
+// negotiate runs method selection and, when the server has users,
+// username/password authentication. It returns the authenticated user name,
+// which then holds one of the user's connection slots.
+func (w *Socks5ServerWrapper) negotiate(c net.Conn) (string, error) {
+	rq, err := socks5.NewNegotiationRequestFrom(c)
+	if err != nil {
+		return "", err
+	}
+	m := socks5.MethodNone
+	if !w.users.empty() {
+		m = socks5.MethodUsernamePassword
+	}
+	if !bytes.Contains(rq.Methods, []byte{m}) {
+		socks5.NewNegotiationReply(socks5.MethodUnsupportAll).WriteTo(c)
+		return "", errors.New("no acceptable authentication method")
+	}
+	if _, err := socks5.NewNegotiationReply(m).WriteTo(c); err != nil {
+		return "", err
+	}
+	if m != socks5.MethodUsernamePassword {
+		return "", nil
+	}
+	urq, err := socks5.NewUserPassNegotiationRequestFrom(c)
+	if err != nil {
+		return "", err
+	}
+	user := string(urq.Uname)
+	if !w.users.verify(user, string(urq.Passwd)) {
+		w.authEvent(c, user, false)
+		socks5.NewUserPassNegotiationReply(socks5.UserPassStatusFailure).WriteTo(c)
+		return "", socks5.ErrUserPassAuth
+	}
+	w.authEvent(c, user, true)
+	if !w.guard.holdUser(user) {
+		socks5.NewUserPassNegotiationReply(socks5.UserPassStatusFailure).WriteTo(c)
+		return "", errUserFull
+	}
+	if _, err := socks5.NewUserPassNegotiationReply(socks5.UserPassStatusSuccess).WriteTo(c); err != nil {
+		w.guard.releaseUser(user)
+		return "", err
+	}
+	return user, nil
+}
+
+// authEvent reports one authentication attempt and counts it towards bans.
+func (w *Socks5ServerWrapper) authEvent(c net.Conn, user string, ok bool) {
+	r := core.Resp{Op: "auth", Success: ok, Data: map[string]interface{}{
+		"server": w.ID,
+		"client": c.RemoteAddr().String(),
+		"user":   user,
+	}}
+	if !ok {
+		r.Error = socks5.ErrUserPassAuth.Error()
+	}
+	w.emit(core.TopicSocks5Connections, r)
+	w.noteAuth(c, ok)
+}
+
+func (w *Socks5ServerWrapper) connect(s *session, c net.Conn, r *socks5.Request) error {
+	rc, err := w.dial(s, r.Address())
+	if err != nil {
+		writeReply(c, socks5.RepHostUnreachable)
+		return err
+	}
+	s.attach(rc)
+	defer rc.Close()
+	if err := writeReply(c, socks5.RepSuccess); err != nil {
+		return err
+	}
+	relay(s, c, rc)
+	return nil
+}
+
+// relay copies between the client c and the upstream rc, counted and
+// shaped, until the upstream side is done.
+func relay(s *session, c, rc net.Conn) {
+	go func() {
+		io.Copy(countingWriter{shapedWriter{s.ctx, rc, s.limiters(true)}, s.addOut}, c)
+		if cw, ok := rc.(interface{ CloseWrite() error }); ok {
+			cw.CloseWrite()
+		}
+	}()
+	io.Copy(countingWriter{shapedWriter{s.ctx, c, s.limiters(false)}, s.addIn}, rc)
+}
+
+// associate serves a UDP ASSOCIATE for as long as its control connection
+// stays open.
+func (w *Socks5ServerWrapper) associate(s *session, c net.Conn, r *socks5.Request) error {
+	relay, err := w.currentUpstream().associate(w.egressFor(s))
+	if err != nil {
+		writeReply(c, socks5.RepServerFailure)
+		return err
+	}
+	s.attach(relay)
+	defer relay.Close()
+
+	w.mu.Lock()
+	uc, public := w.uc, w.publicIP
+	w.mu.Unlock()
+	if err := writeBoundReply(c, udpBindAddr(c, uc, public).String()); err != nil {
+		return err
+	}
+	a := &udpAssoc{
+		relay:    relay,
+		sess:     s,
+		clientIP: c.RemoteAddr().(*net.TCPAddr).IP,
+		up:       s.limiters(true),
+		down:     s.limiters(false),
+		out:      make(chan udpOut, udpOutQueue),
+		done:     make(chan struct{}),
+	}
+	// a zero port means the client did not say where it sends from
+	if !isZeroPort(r.DstPort) {
+		if ua, err := net.ResolveUDPAddr("udp", r.Address()); err == nil && !ua.IP.IsUnspecified() {
+			a.client = ua
+		}
+	}
+	w.udp.add(a)
+	defer w.udp.remove(a)
+	defer close(a.done)
+	go w.forward(a)
+
+	go func() {
+		a.pump(uc)
+		c.Close()
+	}()
+	io.Copy(io.Discard, c)
+	return nil
+}
+
+// serveUDP routes client datagrams to their association. Datagrams from
+// unassociated addresses are dropped, never sent on directly.
+func (w *Socks5ServerWrapper) serveUDP(uc *net.UDPConn) {
+	b := make([]byte, 65507)
+	for {
+		n, addr, err := uc.ReadFromUDP(b)
+		if err != nil {
+			return
+		}
+		// the datagram outlives b once queued
+		d, err := socks5.NewDatagramFromBytes(append([]byte(nil), b[:n]...))
+		if err != nil || d.Frag != 0 {
+			continue
+		}
+		if a := w.udp.lookup(addr); a != nil {
+			a.queue(udpOut{d, addr})
+		}
+	}
+}
+
+// forward checks, resolves and sends on the datagrams of a until it ends.
+// Doing so here rather than in serveUDP keeps a slow ACL or DNS lookup
+// from holding up the other associations.
+func (w *Socks5ServerWrapper) forward(a *udpAssoc) {
+	for {
+		select {
+		case o := <-a.out:
+			if !w.allowDatagram(a, o.from, o.d.Address()) {
+				continue
+			}
+			if !allowAll(a.up, len(o.d.Data)) || w.resolveDatagram(a.sess.ctx, o.d, w.egressFor(a.sess)) != nil {
+				continue
+			}
+			if n, err := a.relay.send(o.d); err == nil {
+				a.sess.addOut(n)
+			}
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// allowDatagram checks a datagram destination against the ACL. Decisions
+// are remembered per association so that each destination is evaluated,
+// and reported, once per rule set.
+func (w *Socks5ServerWrapper) allowDatagram(a *udpAssoc, from net.Addr, target string) bool {
+	cur := w.acl.get()
+	if cur == nil {
+		return true
+	}
+	a.mu.Lock()
+	if a.aclFor != cur || len(a.decisions) >= 1024 {
+		a.aclFor, a.decisions = cur, map[string]bool{}
+	}
+	ok, seen := a.decisions[target]
+	a.mu.Unlock()
+	if seen {
+		return ok
+	}
+	ok = w.allowed(a.sess.ctx, from, a.sess.info.User, "udp", target)
+	a.mu.Lock()
+	a.decisions[target] = ok
+	a.mu.Unlock()
+	return ok
+}
+
+// udpBindAddr is the address clients should send datagrams to: the UDP
+// port on the public IP if one is set, else on the IP the client reached
+// us at.
+func udpBindAddr(c net.Conn, uc *net.UDPConn, public net.IP) *net.UDPAddr {
+	ua := *uc.LocalAddr().(*net.UDPAddr)
+	if public != nil {
+		ua.IP = public
+	} else if ua.IP == nil || ua.IP.IsUnspecified() {
+		ua.IP = c.LocalAddr().(*net.TCPAddr).IP
+	}
+	return &ua
+}
+
+func isZeroPort(p []byte) bool {
+	return len(p) == 2 && p[0] == 0 && p[1] == 0
+}
+
+// writeReply sends a reply with an empty bound address.
+func writeReply(c net.Conn, rep byte) error {
+	_, err := socks5.NewReply(rep, socks5.ATYPIPv4, []byte{0, 0, 0, 0}, []byte{0, 0}).WriteTo(c)
+	return err
+}
+
+func writeBoundReply(c net.Conn, addr string) error {
+	a, h, p, err := socks5.ParseAddress(addr)
+	if err != nil {
+		return err
+	}
+	_, err = socks5.NewReply(socks5.RepSuccess, a, h, p).WriteTo(c)
+	return err
+}
+
+// bufferedConn reads through r, which has already peeked at the start of
+// the stream.
+type bufferedConn struct {
+	*net.TCPConn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) { return c.r.Read(b) }
+
+// WriteTo hides the TCPConn's, which would skip the buffered bytes.
+func (c *bufferedConn) WriteTo(w io.Writer) (int64, error) { return c.r.WriteTo(w) }
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	socks5 "github.com/txthinking/socks5"
+)
+
+func newTestServer(t *testing.T, addr string) *Socks5ServerWrapper {
+	t.Helper()
+	server, err := socks5.NewClassicServer(addr, "", "", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := newServerWrapper(server, directUpstream{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.close)
+	return w
+}
+
+func TestListenRefusesSecondStart(t *testing.T) {
+	w := newTestServer(t, "127.0.0.1:0")
+	if err := w.listen(); err != nil {
+		t.Fatal(err)
+	}
+	ln := w.ln
+	if err := w.listen(); err == nil {
+		t.Fatal("second listen succeeded")
+	}
+	if w.ln != ln || w.isClosed() {
+		t.Fatal("second listen touched the running server")
+	}
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("listener closed by second listen: %v", err)
+	}
+	c.Close()
+}
+
+func TestListenFailureLeavesServerReusable(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestServer(t, busy.Addr().String())
+	if err := w.listen(); err == nil {
+		t.Fatal("listen on a busy port succeeded")
+	}
+	if w.isClosed() {
+		t.Fatal("failed listen closed the server")
+	}
+	busy.Close()
+	if err := w.listen(); err != nil {
+		t.Fatalf("listen after the port was freed: %v", err)
+	}
+}
+
+func TestListenAfterClose(t *testing.T) {
+	w := newTestServer(t, "127.0.0.1:0")
+	w.close()
+	if err := w.listen(); err == nil {
+		t.Fatal("listen on a closed server succeeded")
+	}
+}
+
+func udpEcho(t *testing.T) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go func() {
+		b := make([]byte, 2048)
+		for {
+			n, from, err := pc.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(b[:n], from)
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func TestSlowDatagramLookupDoesNotStallOthers(t *testing.T) {
+	// a DNS server that never answers
+	dead, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dead.Close()
+	r, err := newResolver(DNSConfig{Mode: "udp", Server: dead.LocalAddr().String(), TimeoutMs: 3000, CacheSize: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TCP and UDP must share the port, which the client assumes
+	var w *Socks5ServerWrapper
+	for i := 0; ; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := ln.Addr().String()
+		ln.Close()
+		w = newTestServer(t, addr)
+		if err := w.listen(); err == nil {
+			break
+		} else if i == 10 {
+			t.Fatal(err)
+		}
+	}
+	w.dns.set(r)
+	go w.serve()
+	addr := w.ln.Addr().String()
+	echo := udpEcho(t)
+
+	c, err := socks5.NewClient(addr, "", "", 5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := c.Dial("udp", "slow.invalid:9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+	if _, err := slow.Write([]byte("stuck")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	fast, err := c.Dial("udp", echo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fast.Close()
+	if _, err := fast.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	fast.SetReadDeadline(time.Now().Add(time.Second))
+	b := make([]byte, 64)
+	n, err := fast.Read(b)
+	if err != nil {
+		t.Fatalf("echo through a second association: %v", err)
+	}
+	if string(b[:n]) != "ping" {
+		t.Fatalf("got %q", b[:n])
+	}
+}
+
+func TestTrackClearsHandshakeDeadline(t *testing.T) {
+	w := newTestServer(t, "127.0.0.1:0")
+	c, p := net.Pipe()
+	defer p.Close()
+	c.SetDeadline(time.Now().Add(20 * time.Millisecond))
+	s := w.track(c, "socks5", "", "192.0.2.1:80", socks5.CmdConnect)
+	defer w.untrack(s, nil)
+	time.Sleep(50 * time.Millisecond)
+	go p.Write([]byte{1})
+	if _, err := c.Read(make([]byte, 1)); err != nil {
+		t.Fatalf("tracked session still bound by the handshake deadline: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	core "core"
+	socks5 "github.com/txthinking/socks5"
+)
+
+// Session is a snapshot of one client connection. BytesIn counts what the
+// client received, BytesOut what it sent.
+type Session struct {
+	ID        int64     `json:"id"`
+	Server    int64     `json:"server"`
+	Client    string    `json:"client"`
+	Protocol  string    `json:"protocol"`
+	User      string    `json:"user,omitempty"`
+	Target    string    `json:"target"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+	BytesIn   int64     `json:"bytes_in"`
+	BytesOut  int64     `json:"bytes_out"`
+	Error     string    `json:"error,omitempty"`
+}
+
+var (
+	errKilled          = errors.New("connection killed")
+	errIdle            = errors.New("idle timeout")
+	errCommandDisabled = errors.New("command disabled on this server")
+)
+
+type session struct {
+	info Session // fixed once the session is tracked
+	in   int64   // atomic
+	out  int64   // atomic
+	last int64   // atomic, UnixNano of the last transfer
+	conn net.Conn
+
+	// ctx is canceled by kill so that shaped transfers stop waiting
+	ctx    context.Context
+	cancel context.CancelFunc
+	shared []*bucketPair // server and user buckets
+	own    *bucketPair
+	egress *egress // set by the ACL rule that allowed the request
+
+	mu      sync.Mutex
+	closers []io.Closer
+	killed  bool
+	idled   bool
+	pinned  bool // own limits were set for this session alone
+}
+
+func (s *session) addIn(n int) {
+	atomic.AddInt64(&s.in, int64(n))
+	atomic.StoreInt64(&s.last, time.Now().UnixNano())
+}
+
+func (s *session) addOut(n int) {
+	atomic.AddInt64(&s.out, int64(n))
+	atomic.StoreInt64(&s.last, time.Now().UnixNano())
+}
+
+// watchIdle kills s once nothing has moved either way for d.
+func (s *session) watchIdle(d time.Duration) {
+	atomic.StoreInt64(&s.last, time.Now().UnixNano())
+	var check func()
+	check = func() {
+		idle := time.Since(time.Unix(0, atomic.LoadInt64(&s.last)))
+		s.mu.Lock()
+		if s.killed {
+			s.mu.Unlock()
+			return
+		}
+		if idle < d {
+			s.mu.Unlock()
+			time.AfterFunc(d-idle, check)
+			return
+		}
+		s.idled = true
+		s.mu.Unlock()
+		s.kill()
+	}
+	time.AfterFunc(d, check)
+}
+
+func (s *session) snapshot() Session {
+	info := s.info
+	info.BytesIn = atomic.LoadInt64(&s.in)
+	info.BytesOut = atomic.LoadInt64(&s.out)
+	return info
+}
+
+// attach registers c to be closed when the session is killed; c is closed
+// right away if that already happened.
+func (s *session) attach(c io.Closer) {
+	s.mu.Lock()
+	killed := s.killed
+	if !killed {
+		s.closers = append(s.closers, c)
+	}
+	s.mu.Unlock()
+	if killed {
+		c.Close()
+	}
+}
+
+func (s *session) kill() {
+	s.mu.Lock()
+	s.killed = true
+	closers := s.closers
+	s.closers = nil
+	s.mu.Unlock()
+	s.cancel()
+	s.conn.Close()
+	for _, c := range closers {
+		c.Close()
+	}
+}
+
+func commandName(cmd byte) string {
+	switch cmd {
+	case 0x01:
+		return "connect"
+	case 0x02:
+		return "bind"
+	case 0x03:
+		return "udp"
+	}
+	return fmt.Sprintf("%#x", cmd)
+}
+
+// defaultOff lists what a server does not serve unless it is turned on:
+// the HTTP proxy, which would widen what an existing SOCKS5 port accepts,
+// and SOCKS4, which sends its user id in the clear.
+func defaultOff() map[string]bool {
+	return map[string]bool{"socks4": true, "http": true}
+}
+
+// serves reports whether a protocol ("socks5", "socks4", "http") or a
+// command ("connect", "udp", "bind") is turned on.
+func (w *Socks5ServerWrapper) serves(name string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.off[name]
+}
+
+// emit publishes an event of the server to topic and to the port that
+// started it.
+func (w *Socks5ServerWrapper) emit(topic string, r core.Resp) {
+	w.mu.Lock()
+	call := w.call
+	w.mu.Unlock()
+	call.Emit(topic, r)
+}
+
+// track registers a new session and emits connection_opened.
+func (w *Socks5ServerWrapper) track(c net.Conn, proto, user, target string, cmd byte) *session {
+	s := &session{
+		info: Session{
+			ID:        atomic.AddInt64(&w.nextSess, 1),
+			Server:    w.ID,
+			Client:    c.RemoteAddr().String(),
+			Protocol:  proto,
+			User:      user,
+			Target:    target,
+			Command:   commandName(cmd),
+			StartedAt: time.Now(),
+		},
+		conn: c,
+	}
+	// the handshake is over; from here on the idle timeouts apply
+	c.SetDeadline(time.Time{})
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	shared, own := w.shaper.buckets(user)
+	s.shared, s.own = shared, newBucketPair(own)
+	w.mu.Lock()
+	w.sessions[s.info.ID] = s
+	idle := w.tcpIdle
+	if cmd == socks5.CmdUDP {
+		idle = w.udpIdle
+	}
+	w.mu.Unlock()
+	if idle > 0 {
+		s.watchIdle(idle)
+	}
+	w.emit(core.TopicSocks5Connections, core.Resp{Op: "connection_opened", Success: true, Data: s.snapshot()})
+	return s
+}
+
+// untrack removes a finished session and emits connection_closed.
+func (w *Socks5ServerWrapper) untrack(s *session, err error) {
+	w.mu.Lock()
+	_, live := w.sessions[s.info.ID]
+	delete(w.sessions, s.info.ID)
+	w.mu.Unlock()
+	if live {
+		w.shaper.release(s.info.User)
+	}
+	s.mu.Lock()
+	if s.idled {
+		err = errIdle
+	} else if s.killed && err == nil {
+		err = errKilled
+	}
+	s.mu.Unlock()
+	s.kill()
+	info := s.snapshot()
+	r := core.Resp{Op: "connection_closed", Success: err == nil, Data: &info}
+	if err != nil {
+		info.Error = err.Error()
+		r.Error = err.Error()
+	}
+	w.emit(core.TopicSocks5Connections, r)
+}
+
+// Sessions returns the live sessions ordered by id.
+func (w *Socks5ServerWrapper) Sessions() []Session {
+	w.mu.Lock()
+	out := make([]Session, 0, len(w.sessions))
+	for _, s := range w.sessions {
+		out = append(out, s.snapshot())
+	}
+	w.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Kill closes a session's client and upstream connections.
+func (w *Socks5ServerWrapper) Kill(id int64) error {
+	w.mu.Lock()
+	s, ok := w.sessions[id]
+	w.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("connection %d not found on server %d", id, w.ID)
+	}
+	s.kill()
+	return nil
+}
+
+// countingWriter adds every byte written through it to n.
+type countingWriter struct {
+	w   io.Writer
+	add func(int)
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.add(n)
+	return n, err
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limits caps throughput in bytes per second; 0 means unlimited. Upload
+// is client to destination, download the other way.
+type Limits struct {
+	UploadBps   int64 `json:"upload_bps"`
+	DownloadBps int64 `json:"download_bps"`
+}
+
+// ShapingConfig is the JSON accepted by SetBandwidthLimits. A transfer
+// waits for the server, user and connection buckets alike, so the
+// tightest one wins. PerUser applies to every authenticated user not
+// listed in Users; anonymous clients only have server and connection
+// limits.
+type ShapingConfig struct {
+	Server        Limits            `json:"server"`
+	PerUser       Limits            `json:"per_user"`
+	Users         map[string]Limits `json:"users,omitempty"`
+	PerConnection Limits            `json:"per_connection"`
+}
+
+// shapeChunk is the most a TCP relay writes per wait. Buckets hold at
+// least minBurst so that a chunk, or any UDP datagram, always fits.
+const (
+	shapeChunk = 32 << 10
+	minBurst   = 64 << 10
+)
+
+func parseShaping(s string) (ShapingConfig, error) {
+	var cfg ShapingConfig
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid limits: %v", err)
+	}
+	check := func(what string, l Limits) error {
+		if l.UploadBps < 0 || l.DownloadBps < 0 {
+			return fmt.Errorf("%s: limits must not be negative", what)
+		}
+		return nil
+	}
+	if err := check("server", cfg.Server); err != nil {
+		return cfg, err
+	}
+	if err := check("per_user", cfg.PerUser); err != nil {
+		return cfg, err
+	}
+	if err := check("per_connection", cfg.PerConnection); err != nil {
+		return cfg, err
+	}
+	for u, l := range cfg.Users {
+		if err := check("user "+u, l); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+func setRate(lim *rate.Limiter, bps int64) {
+	if bps <= 0 {
+		lim.SetLimit(rate.Inf)
+		return
+	}
+	burst := bps
+	if burst < minBurst {
+		burst = minBurst
+	}
+	lim.SetBurst(int(burst))
+	lim.SetLimit(rate.Limit(bps))
+}
+
+// bucketPair is an upload and a download token bucket.
+type bucketPair struct {
+	up, down *rate.Limiter
+	refs     int // sessions holding a user's pair, under shaper.mu
+}
+
+func newBucketPair(l Limits) *bucketPair {
+	p := &bucketPair{up: rate.NewLimiter(rate.Inf, minBurst), down: rate.NewLimiter(rate.Inf, minBurst)}
+	p.set(l)
+	return p
+}
+
+func (p *bucketPair) set(l Limits) {
+	setRate(p.up, l.UploadBps)
+	setRate(p.down, l.DownloadBps)
+}
+
+// shaper holds a server's shaping config and its shared buckets. A
+// user's buckets live while the user has sessions, so a user coming back
+// after all of theirs ended starts with full buckets.
+type shaper struct {
+	mu     sync.Mutex
+	cfg    ShapingConfig
+	server *bucketPair
+	users  map[string]*bucketPair
+}
+
+func newShaper() *shaper {
+	return &shaper{server: newBucketPair(Limits{}), users: make(map[string]*bucketPair)}
+}
+
+func (s *shaper) userLimits(user string) Limits {
+	if l, ok := s.cfg.Users[user]; ok {
+		return l
+	}
+	return s.cfg.PerUser
+}
+
+// buckets returns the shared buckets a session of user goes through and
+// the per-connection limits it starts with. Every call is paired with a
+// release once the session ends.
+func (s *shaper) buckets(user string) ([]*bucketPair, Limits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []*bucketPair{s.server}
+	if user != "" {
+		p, ok := s.users[user]
+		if !ok {
+			p = newBucketPair(s.userLimits(user))
+			s.users[user] = p
+		}
+		p.refs++
+		out = append(out, p)
+	}
+	return out, s.cfg.PerConnection
+}
+
+// release drops a finished session's hold on the buckets of user.
+func (s *shaper) release(user string) {
+	if user == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.users[user]; ok {
+		if p.refs--; p.refs <= 0 {
+			delete(s.users, user)
+		}
+	}
+}
+
+func (s *shaper) config() ShapingConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// update applies cfg to the shared buckets in place, so running
+// transfers pick it up on their next write.
+func (s *shaper) update(cfg ShapingConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.server.set(cfg.Server)
+	for u, p := range s.users {
+		p.set(s.userLimits(u))
+	}
+}
+
+// shapedWriter waits on every bucket before each chunk it writes.
+type shapedWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	lims []*rate.Limiter
+}
+
+func (s shapedWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		c := len(p)
+		if c > shapeChunk {
+			c = shapeChunk
+		}
+		for _, l := range s.lims {
+			if err := l.WaitN(s.ctx, c); err != nil {
+				return n, err
+			}
+		}
+		m, err := s.w.Write(p[:c])
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[c:]
+	}
+	return n, nil
+}
+
+// allowAll takes n tokens from every bucket or, if any is short, from
+// none. UDP is policed this way: a datagram over the limit is dropped
+// instead of holding up the others.
+func allowAll(lims []*rate.Limiter, n int) bool {
+	now := time.Now()
+	rs := make([]*rate.Reservation, 0, len(lims))
+	for _, l := range lims {
+		r := l.ReserveN(now, n)
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			// canceling as of now, as Cancel would find the
+			// reservations due already and keep their tokens
+			r.CancelAt(now)
+			for _, done := range rs {
+				done.CancelAt(now)
+			}
+			return false
+		}
+		rs = append(rs, r)
+	}
+	return true
+}
+
+// limiters lists the session's buckets for one direction, connection
+// bucket last.
+func (s *session) limiters(upload bool) []*rate.Limiter {
+	pairs := make([]*bucketPair, 0, len(s.shared)+1)
+	pairs = append(append(pairs, s.shared...), s.own)
+	out := make([]*rate.Limiter, len(pairs))
+	for i, p := range pairs {
+		if upload {
+			out[i] = p.up
+		} else {
+			out[i] = p.down
+		}
+	}
+	return out
+}
+
+// setLimits changes one session's connection bucket; pinned limits are
+// kept when the server config changes.
+func (s *session) setLimits(l Limits, pinned bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pinned && !pinned {
+		return
+	}
+	s.pinned = s.pinned || pinned
+	s.own.set(l)
+}
+
+// SetShaping replaces the server's shaping config, live sessions included.
+func (w *Socks5ServerWrapper) SetShaping(cfg ShapingConfig) {
+	w.shaper.update(cfg)
+	w.mu.Lock()
+	sessions := make([]*session, 0, len(w.sessions))
+	for _, s := range w.sessions {
+		sessions = append(sessions, s)
+	}
+	w.mu.Unlock()
+	for _, s := range sessions {
+		s.setLimits(cfg.PerConnection, false)
+	}
+}
+
+// SetSessionLimits pins limits on one live session.
+func (w *Socks5ServerWrapper) SetSessionLimits(id int64, l Limits) error {
+	w.mu.Lock()
+	s, ok := w.sessions[id]
+	w.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("connection %d not found on server %d", id, w.ID)
+	}
+	s.setLimits(l, true)
+	return nil
+}
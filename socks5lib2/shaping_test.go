@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	socks5 "github.com/txthinking/socks5"
+	"golang.org/x/time/rate"
+)
+
+func TestShaperSharesUserBuckets(t *testing.T) {
+	s := newShaper()
+	s.update(ShapingConfig{
+		Server:        Limits{UploadBps: 1 << 20},
+		PerUser:       Limits{UploadBps: 100 << 10},
+		Users:         map[string]Limits{"bob": {DownloadBps: 200 << 10}},
+		PerConnection: Limits{UploadBps: 10 << 10},
+	})
+
+	anon, conn := s.buckets("")
+	if len(anon) != 1 || anon[0] != s.server {
+		t.Fatalf("anonymous session got %d shared buckets, want the server's only", len(anon))
+	}
+	if conn.UploadBps != 10<<10 {
+		t.Fatalf("per-connection limits %+v", conn)
+	}
+
+	a1, _ := s.buckets("alice")
+	a2, _ := s.buckets("alice")
+	b, _ := s.buckets("bob")
+	if len(a1) != 2 || a1[1] != a2[1] {
+		t.Fatal("sessions of one user do not share a bucket")
+	}
+	if a1[1] == b[1] {
+		t.Fatal("two users share a bucket")
+	}
+	if got := a1[1].up.Limit(); got != rate.Limit(100<<10) {
+		t.Fatalf("alice upload limit %v, want per_user", got)
+	}
+	if got := b[1].up.Limit(); got != rate.Inf {
+		t.Fatalf("bob upload limit %v, want unlimited from his own entry", got)
+	}
+	if got := b[1].down.Limit(); got != rate.Limit(200<<10) {
+		t.Fatalf("bob download limit %v", got)
+	}
+
+	// updates reach buckets already handed out
+	s.update(ShapingConfig{PerUser: Limits{UploadBps: 50 << 10}})
+	if got := a1[1].up.Limit(); got != rate.Limit(50<<10) {
+		t.Fatalf("alice upload limit %v after update", got)
+	}
+	if got := b[1].down.Limit(); got != rate.Inf {
+		t.Fatalf("bob download limit %v after his entry went", got)
+	}
+	if got := s.server.up.Limit(); got != rate.Inf {
+		t.Fatalf("server upload limit %v after update", got)
+	}
+}
+
+func TestShaperReleasesUserBuckets(t *testing.T) {
+	s := newShaper()
+	s.buckets("alice")
+	s.buckets("alice")
+	s.buckets("bob")
+	s.release("alice")
+	if _, ok := s.users["alice"]; !ok {
+		t.Fatal("alice's bucket went while a session still holds it")
+	}
+	s.release("alice")
+	s.release("bob")
+	s.release("carol")
+	s.release("")
+	if len(s.users) != 0 {
+		t.Fatalf("%d user buckets left after every session ended", len(s.users))
+	}
+}
+
+func TestUntrackReleasesUserBucketOnce(t *testing.T) {
+	w := newTestServer(t, "127.0.0.1:0")
+	c1, p1 := net.Pipe()
+	c2, p2 := net.Pipe()
+	defer p1.Close()
+	defer p2.Close()
+	s1 := w.track(c1, "socks5", "alice", "192.0.2.1:80", socks5.CmdConnect)
+	s2 := w.track(c2, "socks5", "alice", "192.0.2.1:80", socks5.CmdConnect)
+	w.untrack(s1, nil)
+	w.untrack(s1, nil)
+	if len(w.shaper.users) != 1 {
+		t.Fatal("ending one session twice dropped a bucket still in use")
+	}
+	w.untrack(s2, nil)
+	if len(w.shaper.users) != 0 {
+		t.Fatal("user bucket kept after the user's last session")
+	}
+}
+
+func TestAllowAllTakesFromAllOrNone(t *testing.T) {
+	now := time.Now()
+	wide := rate.NewLimiter(rate.Limit(1), 1000)
+	narrow := rate.NewLimiter(rate.Limit(1), 100)
+	lims := []*rate.Limiter{wide, narrow}
+	if allowAll(lims, 500) {
+		t.Fatal("datagram larger than one bucket was allowed")
+	}
+	if got := wide.TokensAt(now); got < 999 {
+		t.Fatalf("refused datagram still took tokens: %v left", got)
+	}
+	if !allowAll(lims, 100) {
+		t.Fatal("datagram that fits every bucket was refused")
+	}
+	if got := wide.TokensAt(now); got > 901 {
+		t.Fatalf("allowed datagram took no tokens: %v left", got)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	socks5 "github.com/txthinking/socks5"
+)
+
+// SOCKS4 reply codes.
+const (
+	socks4Ver          byte = 0x04
+	socks4Granted      byte = 90
+	socks4Rejected     byte = 91
+	socks4UserMismatch byte = 93
+)
+
+// socks4Request is a parsed SOCKS4 or SOCKS4a request. Host is the
+// hostname of a SOCKS4a request, empty otherwise.
+type socks4Request struct {
+	cmd    byte
+	port   uint16
+	ip     net.IP
+	userID string
+	host   string
+}
+
+func (r *socks4Request) target() string {
+	h := r.ip.String()
+	if r.host != "" {
+		h = r.host
+	}
+	return net.JoinHostPort(h, strconv.Itoa(int(r.port)))
+}
+
+// readSocks4Request reads a request, version byte included.
+func readSocks4Request(br *bufio.Reader) (*socks4Request, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, err
+	}
+	if hdr[0] != socks4Ver {
+		return nil, fmt.Errorf("socks4: bad version %d", hdr[0])
+	}
+	req := &socks4Request{
+		cmd:  hdr[1],
+		port: binary.BigEndian.Uint16(hdr[2:4]),
+		ip:   net.IPv4(hdr[4], hdr[5], hdr[6], hdr[7]).To4(),
+	}
+	var err error
+	if req.userID, err = readCString(br); err != nil {
+		return nil, err
+	}
+	// SOCKS4a: 0.0.0.x with x != 0 says a hostname follows
+	if hdr[4] == 0 && hdr[5] == 0 && hdr[6] == 0 && hdr[7] != 0 {
+		if req.host, err = readCString(br); err != nil {
+			return nil, err
+		}
+		if req.host == "" {
+			return nil, errors.New("socks4a: empty hostname")
+		}
+	}
+	return req, nil
+}
+
+// readCString reads a NUL-terminated field, no longer than br's buffer.
+func readCString(br *bufio.Reader) (string, error) {
+	b, err := br.ReadSlice(0)
+	if err != nil {
+		return "", fmt.Errorf("socks4: unterminated field: %w", err)
+	}
+	return string(b[:len(b)-1]), nil
+}
+
+func writeSocks4Reply(c net.Conn, code byte, addr string) error {
+	b := []byte{0, code, 0, 0, 0, 0, 0, 0}
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		p, _ := strconv.Atoi(port)
+		binary.BigEndian.PutUint16(b[2:4], uint16(p))
+		if ip := net.ParseIP(host).To4(); ip != nil {
+			copy(b[4:], ip)
+		}
+	}
+	_, err := c.Write(b)
+	return err
+}
+
+// socks4Auth maps the user id onto the credential store. With users
+// configured it must read "user:password", SOCKS4 having no other place
+// for a password; without users any id is accepted anonymously. An
+// accepted user holds one of its connection slots.
+func (w *Socks5ServerWrapper) socks4Auth(c net.Conn, userID string) (string, bool) {
+	if w.users.empty() {
+		return "", true
+	}
+	user, pass, _ := strings.Cut(userID, ":")
+	ok := w.users.verify(user, pass)
+	w.authEvent(c, user, ok)
+	return user, ok && w.guard.holdUser(user)
+}
+
+func (w *Socks5ServerWrapper) handleSocks4(c *bufferedConn) {
+	r, err := readSocks4Request(c.r)
+	if err != nil {
+		w.handshakeFailed(c, "socks4", err)
+		return
+	}
+	user, ok := w.socks4Auth(c, r.userID)
+	if !ok {
+		writeSocks4Reply(c, socks4UserMismatch, "")
+		return
+	}
+	defer w.guard.releaseUser(user)
+	target := r.target()
+	var cmd byte
+	switch r.cmd {
+	case 1:
+		cmd = socks5.CmdConnect
+	case 2:
+		cmd = socks5.CmdBind
+	default:
+		writeSocks4Reply(c, socks4Rejected, "")
+		return
+	}
+	s := w.track(c, "socks4", user, target, cmd)
+	if !w.serves(s.info.Command) {
+		writeSocks4Reply(c, socks4Rejected, "")
+		w.untrack(s, errCommandDisabled)
+		return
+	}
+	if !w.admit(s, target) {
+		writeSocks4Reply(c, socks4Rejected, "")
+		w.untrack(s, errNotAllowed)
+		return
+	}
+	if cmd == socks5.CmdConnect {
+		err = w.socks4Connect(s, c, target)
+	} else {
+		err = w.socks4Bind(s, c, r)
+	}
+	w.untrack(s, err)
+}
+
+func (w *Socks5ServerWrapper) socks4Connect(s *session, c net.Conn, target string) error {
+	rc, err := w.dial(s, target)
+	if err != nil {
+		writeSocks4Reply(c, socks4Rejected, "")
+		return err
+	}
+	s.attach(rc)
+	defer rc.Close()
+	if err := writeSocks4Reply(c, socks4Granted, ""); err != nil {
+		return err
+	}
+	relay(s, c, rc)
+	return nil
+}
+
+// socks4Bind answers twice: once with the address the peer should use,
+// once the peer has connected.
+func (w *Socks5ServerWrapper) socks4Bind(s *session, c net.Conn, r *socks4Request) error {
+	b, err := w.currentUpstream().bind(r.target(), w.egressFor(s))
+	if err != nil {
+		writeSocks4Reply(c, socks4Rejected, "")
+		return err
+	}
+	s.attach(b)
+	defer b.Close()
+	addr, err := bindReplyAddr(c, b)
+	if err != nil || addr.IP.To4() == nil {
+		writeSocks4Reply(c, socks4Rejected, "")
+		if err == nil {
+			err = errors.New("socks4: bound address is not IPv4")
+		}
+		return err
+	}
+	if err := writeSocks4Reply(c, socks4Granted, addr.String()); err != nil {
+		return err
+	}
+	pc, from, err := w.acceptBind(s, c, b, r.target())
+	if err != nil {
+		writeSocks4Reply(c, socks4Rejected, "")
+		return err
+	}
+	defer pc.Close()
+	if err := writeSocks4Reply(c, socks4Granted, from); err != nil {
+		return err
+	}
+	relay(s, c, pc)
+	return nil
+}
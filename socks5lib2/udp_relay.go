@@ -0,0 +1,201 @@
+package main
+
+import (
+	"net"
+	"sync"
+
+	socks5 "github.com/txthinking/socks5"
+	"golang.org/x/time/rate"
+)
+
+// datagramRelay carries one association's SOCKS5 datagrams between the
+// client and their destinations.
+type datagramRelay interface {
+	// send forwards a client datagram and returns the payload size.
+	send(d *socks5.Datagram) (int, error)
+	// recv reads into b and returns the next packet for the client, with
+	// its SOCKS5 header, plus the payload size.
+	recv(b []byte) ([]byte, int, error)
+	Close() error
+}
+
+// directRelay sends datagrams straight to their destinations from one
+// local socket per association.
+type directRelay struct {
+	pc *net.UDPConn
+}
+
+func newDirectRelay(e *egress) (*directRelay, error) {
+	pc, err := e.listenUDP()
+	if err != nil {
+		return nil, err
+	}
+	return &directRelay{pc: pc}, nil
+}
+
+func (r *directRelay) send(d *socks5.Datagram) (int, error) {
+	addr, err := net.ResolveUDPAddr("udp", d.Address())
+	if err != nil {
+		return 0, err
+	}
+	return r.pc.WriteToUDP(d.Data, addr)
+}
+
+func (r *directRelay) recv(b []byte) ([]byte, int, error) {
+	n, from, err := r.pc.ReadFromUDP(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	a, h, p, err := socks5.ParseAddress(from.String())
+	if err != nil {
+		return nil, 0, err
+	}
+	return socks5.NewDatagram(a, h, p, b[:n]).Bytes(), n, nil
+}
+
+func (r *directRelay) Close() error { return r.pc.Close() }
+
+// chainRelay passes datagrams unchanged to the relay of the last hop; the
+// SOCKS5 header already carries the destination. The association lives as
+// long as ctrl, the control connection through the chain.
+type chainRelay struct {
+	ctrl  net.Conn
+	relay *net.UDPConn
+	once  sync.Once
+}
+
+func (r *chainRelay) send(d *socks5.Datagram) (int, error) {
+	if _, err := r.relay.Write(d.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(d.Data), nil
+}
+
+func (r *chainRelay) recv(b []byte) ([]byte, int, error) {
+	n, err := r.relay.Read(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	d, err := socks5.NewDatagramFromBytes(b[:n])
+	if err != nil {
+		return nil, 0, err
+	}
+	return b[:n], len(d.Data), nil
+}
+
+func (r *chainRelay) Close() error {
+	r.once.Do(func() {
+		r.relay.Close()
+		r.ctrl.Close()
+	})
+	return nil
+}
+
+// udpAssoc ties a client's UDP ASSOCIATE to its relay and session.
+type udpAssoc struct {
+	clientIP net.IP
+	relay    datagramRelay
+	sess     *session
+	up, down []*rate.Limiter // datagrams over the limit are dropped
+	out      chan udpOut     // client datagrams waiting for forward
+	done     chan struct{}   // closed when the association ends
+
+	mu        sync.Mutex
+	client    *net.UDPAddr // nil until the first datagram when the client did not announce it
+	aclFor    *acl
+	decisions map[string]bool
+}
+
+// udpOut is a client datagram on its way to the relay.
+type udpOut struct {
+	d    *socks5.Datagram
+	from *net.UDPAddr
+}
+
+// datagrams queued beyond this for one association are dropped
+const udpOutQueue = 256
+
+// queue hands o to forward, dropping it when the association is behind.
+func (a *udpAssoc) queue(o udpOut) {
+	select {
+	case a.out <- o:
+	default:
+	}
+}
+
+func (a *udpAssoc) clientAddr() *net.UDPAddr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.client
+}
+
+// pump copies datagrams from the relay back to the client through uc
+// until the relay is closed.
+func (a *udpAssoc) pump(uc *net.UDPConn) {
+	b := make([]byte, 65507)
+	for {
+		pkt, n, err := a.relay.recv(b)
+		if err != nil {
+			return
+		}
+		client := a.clientAddr()
+		if client == nil || !allowAll(a.down, n) {
+			continue
+		}
+		if _, err := uc.WriteToUDP(pkt, client); err != nil {
+			return
+		}
+		a.sess.addIn(n)
+	}
+}
+
+// udpRelay finds the association a client datagram belongs to.
+type udpRelay struct {
+	mu     sync.Mutex
+	byAddr map[string]*udpAssoc
+	all    map[*udpAssoc]struct{}
+}
+
+func (u *udpRelay) add(a *udpAssoc) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.all == nil {
+		u.all = map[*udpAssoc]struct{}{}
+		u.byAddr = map[string]*udpAssoc{}
+	}
+	u.all[a] = struct{}{}
+	if a.client != nil {
+		u.byAddr[a.client.String()] = a
+	}
+}
+
+func (u *udpRelay) remove(a *udpAssoc) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.all, a)
+	if c := a.clientAddr(); c != nil && u.byAddr[c.String()] == a {
+		delete(u.byAddr, c.String())
+	}
+}
+
+// lookup returns the association for addr. A datagram from an unknown
+// port is bound to an unbound association opened from the same IP, which
+// covers clients that announce 0.0.0.0:0 as RFC 1928 allows.
+func (u *udpRelay) lookup(addr *net.UDPAddr) *udpAssoc {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if a, ok := u.byAddr[addr.String()]; ok {
+		return a
+	}
+	for a := range u.all {
+		a.mu.Lock()
+		if a.client == nil && a.clientIP.Equal(addr.IP) {
+			a.client = addr
+			a.mu.Unlock()
+			u.byAddr[addr.String()] = a
+			return a
+		}
+		a.mu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	socks5 "github.com/txthinking/socks5"
+)
+
+// upstream is where a server sends its clients' traffic. e says how the
+// connections to targets or upstream proxies leave the host.
+type upstream interface {
+	dial(target string, e *egress) (net.Conn, error)
+	associate(e *egress) (datagramRelay, error)
+	// bind prepares to accept one connection from peer, the address the
+	// client expects it from.
+	bind(peer string, e *egress) (binding, error)
+}
+
+// binding is a BIND waiting for its one inbound connection.
+type binding interface {
+	// Addr is where the peer should connect; an unspecified IP stands for
+	// the address the client reached us at.
+	Addr() (*net.TCPAddr, error)
+	// Accept waits up to bindTimeout for the peer and returns the
+	// connection and the peer's address.
+	Accept() (net.Conn, string, error)
+	Close() error
+}
+
+const bindTimeout = 2 * time.Minute
+
+// directUpstream connects to targets itself.
+type directUpstream struct{}
+
+func (directUpstream) dial(target string, e *egress) (net.Conn, error) {
+	return e.dial("tcp", target)
+}
+
+func (directUpstream) associate(e *egress) (datagramRelay, error) {
+	return newDirectRelay(e)
+}
+
+// bind listens on the egress address too, which is where the peer is
+// expected to connect.
+func (directUpstream) bind(peer string, e *egress) (binding, error) {
+	ln, err := e.listenTCP()
+	if err != nil {
+		return nil, err
+	}
+	return &listenBinding{ln: ln}, nil
+}
+
+// listenBinding accepts the peer on a local port.
+type listenBinding struct {
+	ln *net.TCPListener
+}
+
+func (b *listenBinding) Addr() (*net.TCPAddr, error) {
+	return b.ln.Addr().(*net.TCPAddr), nil
+}
+
+func (b *listenBinding) Accept() (net.Conn, string, error) {
+	defer b.ln.Close()
+	b.ln.SetDeadline(time.Now().Add(bindTimeout))
+	c, err := b.ln.AcceptTCP()
+	if err != nil {
+		return nil, "", err
+	}
+	return c, c.RemoteAddr().String(), nil
+}
+
+func (b *listenBinding) Close() error { return b.ln.Close() }
+
+// chainUpstream sends everything through a chain of SOCKS5 proxies; a
+// single upstream proxy is a one-hop chain.
+type chainUpstream struct {
+	hops []Hop
+}
+
+func (u *chainUpstream) dial(target string, e *egress) (net.Conn, error) {
+	conn, _, err := dialChain(u.hops, socks5.CmdConnect, target, e)
+	return conn, err
+}
+
+// associate opens a UDP ASSOCIATE on the last hop. The control connection
+// runs through the whole chain, but datagrams go straight to the last
+// hop's relay since SOCKS5 has no way to nest UDP relays.
+func (u *chainUpstream) associate(e *egress) (datagramRelay, error) {
+	last := u.hops[len(u.hops)-1]
+	ctrl, rp, err := dialChain(u.hops, socks5.CmdUDP, "0.0.0.0:0", e)
+	if err != nil {
+		return nil, fmt.Errorf("upstream udp associate: %w", err)
+	}
+	relayAddr, err := upstreamRelayAddr(last.Addr, rp.Address())
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	rc, err := e.dial("udp", relayAddr.String())
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	r := &chainRelay{ctrl: ctrl, relay: rc.(*net.UDPConn)}
+	// the upstream ends the association by closing its control connection
+	go func() {
+		io.Copy(io.Discard, ctrl)
+		r.Close()
+	}()
+	return r, nil
+}
+
+// bind sends BIND to the last hop, which accepts the peer for us.
+func (u *chainUpstream) bind(peer string, e *egress) (binding, error) {
+	conn, rp, err := dialChain(u.hops, socks5.CmdBind, peer, e)
+	if err != nil {
+		return nil, err
+	}
+	return &chainBinding{conn: conn, hop: u.hops[len(u.hops)-1].Addr, bound: rp.Address()}, nil
+}
+
+// chainBinding waits for the second BIND reply on the chain connection,
+// which then carries the peer's data.
+type chainBinding struct {
+	conn  net.Conn
+	hop   string
+	bound string
+}
+
+func (b *chainBinding) Addr() (*net.TCPAddr, error) {
+	a, err := net.ResolveTCPAddr("tcp", b.bound)
+	if err != nil {
+		return nil, err
+	}
+	if a.IP == nil || a.IP.IsUnspecified() {
+		// like a UDP relay, 0.0.0.0 means the hop's own address
+		host, _, err := net.SplitHostPort(b.hop)
+		if err != nil {
+			return nil, err
+		}
+		return net.ResolveTCPAddr("tcp", net.JoinHostPort(host, fmt.Sprint(a.Port)))
+	}
+	return a, nil
+}
+
+func (b *chainBinding) Accept() (net.Conn, string, error) {
+	b.conn.SetReadDeadline(time.Now().Add(bindTimeout))
+	rp, err := socks5.NewReplyFrom(b.conn)
+	if err != nil {
+		return nil, "", err
+	}
+	if rp.Rep != socks5.RepSuccess {
+		return nil, "", &replyError{dst: "bind", rep: rp.Rep}
+	}
+	b.conn.SetReadDeadline(time.Time{})
+	return b.conn, rp.Address(), nil
+}
+
+func (b *chainBinding) Close() error { return b.conn.Close() }
+
+// upstreamRelayAddr resolves the relay address an upstream announced. Many
+// servers answer 0.0.0.0, meaning "the address you connected to".
+func upstreamRelayAddr(proxyAddr, relay string) (*net.UDPAddr, error) {
+	ra, err := net.ResolveUDPAddr("udp", relay)
+	if err != nil {
+		return nil, err
+	}
+	if ra.IP == nil || ra.IP.IsUnspecified() {
+		host, _, err := net.SplitHostPort(proxyAddr)
+		if err != nil {
+			return nil, err
+		}
+		return net.ResolveUDPAddr("udp", net.JoinHostPort(host, fmt.Sprint(ra.Port)))
+	}
+	return ra, nil
+}
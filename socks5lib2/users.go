@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	core "core"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userStore holds a server's accounts as bcrypt hashes. An empty store
+// means the server accepts clients without authentication.
+type userStore struct {
+	mu    sync.RWMutex
+	users map[string][]byte
+}
+
+func newUserStore() *userStore {
+	return &userStore{users: make(map[string][]byte)}
+}
+
+func hashPassword(user, pass string) ([]byte, error) {
+	if user == "" || pass == "" {
+		return nil, core.WithCode(core.CodeInvalidArg, errors.New("username and password must not be empty"))
+	}
+	// SOCKS5 carries both in one length byte each
+	if len(user) > 255 || len(pass) > 255 {
+		return nil, core.WithCode(core.CodeInvalidArg, errors.New("username and password are limited to 255 bytes"))
+	}
+	return bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+}
+
+func (s *userStore) add(user, pass string) error {
+	h, err := hashPassword(user, pass)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[user]; ok {
+		return fmt.Errorf("user %q already exists", user)
+	}
+	s.users[user] = h
+	return nil
+}
+
+func (s *userStore) remove(user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[user]; !ok {
+		return core.WithCode(core.CodeNotFound, fmt.Errorf("user %q not found", user))
+	}
+	delete(s.users, user)
+	return nil
+}
+
+func (s *userStore) setPassword(user, pass string) error {
+	h, err := hashPassword(user, pass)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[user]; !ok {
+		return core.WithCode(core.CodeNotFound, fmt.Errorf("user %q not found", user))
+	}
+	s.users[user] = h
+	return nil
+}
+
+func (s *userStore) list() []string {
+	s.mu.RLock()
+	out := make([]string, 0, len(s.users))
+	for u := range s.users {
+		out = append(out, u)
+	}
+	s.mu.RUnlock()
+	sort.Strings(out)
+	return out
+}
+
+func (s *userStore) empty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users) == 0
+}
+
+// dummyHash is compared against for unknown users, so that a login takes
+// as long whether or not the user exists.
+var dummyHash = []byte("$2a$10$v77m3.y/E4b3B6YAj1//UOZ9JxCr7TkjbBVP4tdIHxiXiYjhXIt.W")
+
+func (s *userStore) verify(user, pass string) bool {
+	s.mu.RLock()
+	h, ok := s.users[user]
+	s.mu.RUnlock()
+	if !ok {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(pass))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(h, []byte(pass)) == nil
+}
+
+// replace swaps in every account of other at once.
+func (s *userStore) replace(other *userStore) {
+	other.mu.RLock()
+	users := other.users
+	other.mu.RUnlock()
+	s.mu.Lock()
+	s.users = users
+	s.mu.Unlock()
+}
+
+// loadUsersFile reads accounts from path. A JSON object maps user names to
+// passwords; any other content is read as htpasswd lines "user:hash",
+// which must use bcrypt (htpasswd -B). A JSON value that already is a
+// bcrypt hash is kept as is.
+func loadUsersFile(path string) (*userStore, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := newUserStore()
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '{' {
+		var m map[string]string
+		if err := json.Unmarshal(t, &m); err != nil {
+			return nil, core.WithCode(core.CodeInvalidArg, fmt.Errorf("invalid users file: %v", err))
+		}
+		for u, p := range m {
+			if isBcrypt(p) {
+				s.users[u] = []byte(p)
+				continue
+			}
+			h, err := hashPassword(u, p)
+			if err != nil {
+				return nil, fmt.Errorf("user %q: %w", u, err)
+			}
+			s.users[u] = h
+		}
+		return s, nil
+	}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		u, h, ok := strings.Cut(line, ":")
+		if !ok || u == "" || !isBcrypt(h) {
+			return nil, core.WithCode(core.CodeInvalidArg, fmt.Errorf("invalid users file: line %d is not user:bcrypt-hash", n))
+		}
+		s.users[u] = []byte(h)
+	}
+	return s, sc.Err()
+}
+
+func isBcrypt(h string) bool {
+	_, err := bcrypt.Cost([]byte(h))
+	return err == nil
+}